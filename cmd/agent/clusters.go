@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// clusterTarget is one cluster a multi-cluster agent monitors.
+type clusterTarget struct {
+	ClusterID  string
+	Kubeconfig string // empty uses $KUBECONFIG or ~/.kube/config
+	Context    string // empty uses the kubeconfig's current context
+}
+
+func (t clusterTarget) String() string {
+	source := t.Context
+	if source == "" {
+		source = "current context"
+	}
+	if t.Kubeconfig != "" {
+		source = filepath.Base(t.Kubeconfig) + ", " + source
+	}
+	return fmt.Sprintf("%s (%s)", t.ClusterID, source)
+}
+
+// parseClusterTargets reads --clusters, a comma-separated list of
+// context[=clusterID] entries resolved against kubeconfig, and
+// --kubeconfig-dir, where every file is a kubeconfig whose current context is
+// reported under the file's base name. Both may be combined; cluster IDs must
+// be unique. No targets means single-cluster mode.
+func parseClusterTargets(clusters, kubeconfig, kubeconfigDir string) ([]clusterTarget, error) {
+	var targets []clusterTarget
+	for _, entry := range splitList(clusters) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		context, clusterID, _ := strings.Cut(entry, "=")
+		context, clusterID = strings.TrimSpace(context), strings.TrimSpace(clusterID)
+		if context == "" {
+			return nil, fmt.Errorf("cluster entry %q has no context", entry)
+		}
+		if clusterID == "" {
+			clusterID = context
+		}
+		targets = append(targets, clusterTarget{ClusterID: clusterID, Kubeconfig: kubeconfig, Context: context})
+	}
+
+	if kubeconfigDir != "" {
+		entries, err := os.ReadDir(kubeconfigDir)
+		if err != nil {
+			return nil, fmt.Errorf("read kubeconfig dir: %w", err)
+		}
+		var found []clusterTarget
+		for _, entry := range entries {
+			// Skips the ..data links of mounted Secrets and ConfigMaps too.
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(kubeconfigDir, entry.Name())
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			clusterID := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			found = append(found, clusterTarget{ClusterID: clusterID, Kubeconfig: path})
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no kubeconfig files in %s", kubeconfigDir)
+		}
+		targets = append(targets, found...)
+	}
+
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		if seen[t.ClusterID] {
+			return nil, fmt.Errorf("cluster ID %q is used twice", t.ClusterID)
+		}
+		seen[t.ClusterID] = true
+	}
+	sort.SliceStable(targets, func(i, j int) bool { return targets[i].ClusterID < targets[j].ClusterID })
+	return targets, nil
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"kuberoot/internal/k8s"
+)
+
+// configCheckInterval is how often the config source is re-read. Kubelet
+// only refreshes mounted ConfigMaps every minute or so, so a tighter loop
+// would not apply changes any sooner.
+const configCheckInterval = 10 * time.Second
+
+// remoteConfigInterval is how often the backend config is fetched. Report
+// responses carry the config version the backend wants, so a change is
+// usually picked up at the next report instead.
+const remoteConfigInterval = time.Minute
+
+// defaultConfigMapKey is the ConfigMap key read by --config-map when the
+// flag does not name one.
+const defaultConfigMapKey = "agent.yaml"
+
+// fileConfig is the YAML config file, and the schema of the config served by
+// the backend. Every field is optional: anything left out keeps its flag or
+// env value, and an empty list clears one. Backend config is layered over
+// the local file, but may not set backend.url or enrichment.logTail.namespaces,
+// and its redaction lists add to the local ones. Settings that only take
+// effect at startup (report mode, outbox, leader election, cluster ID, API
+// key) are flags only, and unknown keys reject the file.
+//
+//	pollInterval: 60s
+//	snapshotInterval: 10m
+//	backend:
+//	  url: http://kuberoot-backend.kuberoot.svc.cluster.local:8080
+//	  compress: true
+//	  maxRequestBytes: 921600
+//	  timeout: 10s
+//	filters:
+//	  includeNamespaces: [team-*]
+//	  excludeNamespaces: [kube-system]
+//	  labelSelector: tier!=batch
+//	  excludeOwnerKinds: [Job]
+//	enrichment:
+//	  events: true
+//	  workloadContext: true
+//	  logTail:
+//	    namespaces: [team-*]
+//	    lines: 50
+//	    bytes: 4096
+//	redaction:
+//	  patterns: ['session=([a-f0-9]+)']
+//	  dropFields: [envVariables]
+type fileConfig struct {
+	PollInterval     *duration         `json:"pollInterval,omitempty"`
+	SnapshotInterval *duration         `json:"snapshotInterval,omitempty"`
+	Backend          *backendConfig    `json:"backend,omitempty"`
+	Filters          *filterConfig     `json:"filters,omitempty"`
+	Enrichment       *enrichmentConfig `json:"enrichment,omitempty"`
+	Redaction        *redactionConfig  `json:"redaction,omitempty"`
+}
+
+type backendConfig struct {
+	URL             *string   `json:"url,omitempty"`
+	Compress        *bool     `json:"compress,omitempty"`
+	MaxRequestBytes *int      `json:"maxRequestBytes,omitempty"`
+	Timeout         *duration `json:"timeout,omitempty"`
+}
+
+type filterConfig struct {
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	LabelSelector     *string  `json:"labelSelector,omitempty"`
+	ExcludeOwnerKinds []string `json:"excludeOwnerKinds,omitempty"`
+}
+
+type enrichmentConfig struct {
+	Events          *bool          `json:"events,omitempty"`
+	WorkloadContext *bool          `json:"workloadContext,omitempty"`
+	LogTail         *logTailConfig `json:"logTail,omitempty"`
+}
+
+type logTailConfig struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+	Lines      *int64   `json:"lines,omitempty"`
+	Bytes      *int64   `json:"bytes,omitempty"`
+}
+
+type redactionConfig struct {
+	Patterns   []string `json:"patterns,omitempty"`
+	DropFields []string `json:"dropFields,omitempty"`
+}
+
+// duration reads Go duration strings such as "30s" or "5m".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// apply overlays f onto base and returns the resulting config.
+func (f fileConfig) apply(base AgentConfig) AgentConfig {
+	c := base
+	if f.PollInterval != nil {
+		c.PollInterval = time.Duration(*f.PollInterval)
+	}
+	if f.SnapshotInterval != nil {
+		c.SnapshotInterval = time.Duration(*f.SnapshotInterval)
+	}
+	if b := f.Backend; b != nil {
+		if b.URL != nil {
+			c.BackendURL = *b.URL
+		}
+		if b.Compress != nil {
+			c.Compress = *b.Compress
+		}
+		if b.MaxRequestBytes != nil {
+			c.MaxRequestBytes = *b.MaxRequestBytes
+		}
+		if b.Timeout != nil {
+			c.RequestTimeout = time.Duration(*b.Timeout)
+		}
+	}
+	if fl := f.Filters; fl != nil {
+		if fl.IncludeNamespaces != nil {
+			c.IncludeNamespaces = fl.IncludeNamespaces
+		}
+		if fl.ExcludeNamespaces != nil {
+			c.ExcludeNamespaces = fl.ExcludeNamespaces
+		}
+		if fl.LabelSelector != nil {
+			c.PodSelector = *fl.LabelSelector
+		}
+		if fl.ExcludeOwnerKinds != nil {
+			c.ExcludeOwnerKinds = fl.ExcludeOwnerKinds
+		}
+	}
+	if e := f.Enrichment; e != nil {
+		if e.Events != nil {
+			c.Events = *e.Events
+		}
+		if e.WorkloadContext != nil {
+			c.WorkloadContext = *e.WorkloadContext
+		}
+		if lt := e.LogTail; lt != nil {
+			if lt.Namespaces != nil {
+				c.LogTailNamespaces = lt.Namespaces
+			}
+			if lt.Lines != nil {
+				c.LogTailLines = *lt.Lines
+			}
+			if lt.Bytes != nil {
+				c.LogTailBytes = *lt.Bytes
+			}
+		}
+	}
+	if r := f.Redaction; r != nil {
+		if r.Patterns != nil {
+			c.RedactPatterns = r.Patterns
+		}
+		if r.DropFields != nil {
+			c.RedactDropFields = r.DropFields
+		}
+	}
+	return c
+}
+
+// compile validates c and builds the filter, log tail and redaction settings
+// from their raw fields.
+func (c *AgentConfig) compile() error {
+	if c.PollInterval <= 0 {
+		return errors.New("poll interval must be positive")
+	}
+	if c.SnapshotInterval <= 0 {
+		return errors.New("snapshot interval must be positive")
+	}
+	if c.RequestTimeout <= 0 {
+		return errors.New("backend timeout must be positive")
+	}
+	if c.MaxRequestBytes < 0 {
+		return errors.New("max request bytes must not be negative")
+	}
+	if c.LogTailLines < 0 || c.LogTailBytes < 0 {
+		return errors.New("log tail lines and bytes must not be negative")
+	}
+	u, err := url.Parse(c.BackendURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("backend URL %q must be an absolute http(s) URL", c.BackendURL)
+	}
+
+	filter, err := k8s.NewFailureFilter(c.IncludeNamespaces, c.ExcludeNamespaces, c.PodSelector, c.ExcludeOwnerKinds)
+	if err != nil {
+		return fmt.Errorf("pod filter: %w", err)
+	}
+	redaction, err := k8s.NewRedaction(c.RedactPatterns, c.RedactDropFields)
+	if err != nil {
+		return fmt.Errorf("redaction: %w", err)
+	}
+
+	c.Filter = filter
+	c.Redaction = redaction
+	c.LogTail = nil
+	if namespaces := cleanNamespaces(c.LogTailNamespaces); len(namespaces) > 0 && c.LogTailLines > 0 {
+		c.LogTail = &k8s.LogTailConfig{
+			Lines:      c.LogTailLines,
+			MaxBytes:   c.LogTailBytes,
+			Namespaces: namespaces,
+		}
+	}
+	return nil
+}
+
+func cleanNamespaces(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// configLoader reads a config source and layers it over base. It remembers
+// the last content it applied so unchanged content is not re-applied.
+type configLoader struct {
+	base   AgentConfig
+	source string
+	read   func(ctx context.Context) ([]byte, error)
+	parse  func(data []byte, base AgentConfig) (AgentConfig, error) // nil uses parseConfig
+	data   []byte                                                   // last content applied
+	last   [sha256.Size]byte
+	// lastErr is the last error logged, so a source that stays broken is
+	// only reported once.
+	lastErr string
+}
+
+// newFileConfigLoader reads a file, typically a mounted ConfigMap. Kubelet
+// swaps the mount's symlink on update, so re-reading by path picks up the
+// new content.
+func newFileConfigLoader(base AgentConfig, path string) *configLoader {
+	return &configLoader{
+		base:   base,
+		source: path,
+		read: func(context.Context) ([]byte, error) {
+			return os.ReadFile(path)
+		},
+	}
+}
+
+// newConfigMapLoader reads one key of a ConfigMap through the API, which
+// sees edits without waiting for kubelet to sync a volume. ref is
+// namespace/name or namespace/name/key.
+func newConfigMapLoader(base AgentConfig, cs kubernetes.Interface, ref string) (*configLoader, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) == 2 {
+		parts = append(parts, defaultConfigMapKey)
+	}
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("config map %q must be namespace/name or namespace/name/key", ref)
+	}
+	namespace, name, key := parts[0], parts[1], parts[2]
+
+	return &configLoader{
+		base:   base,
+		source: "configmap " + namespace + "/" + name + "[" + key + "]",
+		read: func(ctx context.Context) ([]byte, error) {
+			cm, err := cs.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("get config map: %w", err)
+			}
+			data, ok := cm.Data[key]
+			if !ok {
+				return nil, fmt.Errorf("config map has no key %q", key)
+			}
+			return []byte(data), nil
+		},
+	}, nil
+}
+
+// Load reads the source and returns the merged config. changed is false, with
+// a nil error, when the content is the same as the last successful load.
+func (l *configLoader) Load(ctx context.Context) (config AgentConfig, changed bool, err error) {
+	data, err := l.read(ctx)
+	if err != nil {
+		return AgentConfig{}, false, fmt.Errorf("read %s: %w", l.source, err)
+	}
+	sum := sha256.Sum256(data)
+	if sum == l.last {
+		return AgentConfig{}, false, nil
+	}
+
+	config, err = l.parseWith(data, l.base)
+	if err != nil {
+		return AgentConfig{}, false, fmt.Errorf("%s: %w", l.source, err)
+	}
+	l.data = data
+	l.last = sum
+	return config, true, nil
+}
+
+// rebase layers the last content read over a new base, used when the config
+// beneath this source changes.
+func (l *configLoader) rebase(base AgentConfig) (AgentConfig, error) {
+	if l.data == nil {
+		l.base = base
+		return base, nil
+	}
+	config, err := l.parseWith(l.data, base)
+	if err != nil {
+		return AgentConfig{}, fmt.Errorf("%s: %w", l.source, err)
+	}
+	l.base = base
+	return config, nil
+}
+
+func (l *configLoader) parseWith(data []byte, base AgentConfig) (AgentConfig, error) {
+	if l.parse != nil {
+		return l.parse(data, base)
+	}
+	return parseConfig(data, base)
+}
+
+func parseConfig(data []byte, base AgentConfig) (AgentConfig, error) {
+	file, err := decodeConfig(data)
+	if err != nil {
+		return AgentConfig{}, err
+	}
+	return file.build(base)
+}
+
+func decodeConfig(data []byte) (fileConfig, error) {
+	var file fileConfig
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return fileConfig{}, fmt.Errorf("parse: %w", err)
+	}
+	return file, nil
+}
+
+// build applies f over base and validates the result.
+func (f fileConfig) build(base AgentConfig) (AgentConfig, error) {
+	config := f.apply(base)
+	if err := config.compile(); err != nil {
+		return AgentConfig{}, err
+	}
+	return config, nil
+}
+
+// watchConfig re-reads the local config source and the backend config until
+// ctx is cancelled and swaps in each valid change. Either loader may be nil.
+// An invalid config is logged and ignored, leaving the last good config in
+// place until it is fixed.
+func (a *agent) watchConfig(ctx context.Context, local, remote *configLoader) {
+	ticker := time.NewTicker(configCheckInterval)
+	defer ticker.Stop()
+
+	lastFetch := time.Now()
+	for {
+		fetch := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fetch = time.Since(lastFetch) >= remoteConfigInterval
+		case <-a.configStale:
+			fetch = true
+		}
+
+		if local != nil {
+			if config, ok, _ := a.checkConfig(ctx, local); ok {
+				source := local.source
+				if remote != nil {
+					// Backend settings still win over the new local ones.
+					merged, err := remote.rebase(config)
+					if err != nil {
+						a.rejectConfig(remote, err)
+						continue
+					}
+					config = merged
+				}
+				a.applyConfig(config, source)
+			}
+		}
+
+		if remote != nil && fetch {
+			lastFetch = time.Now()
+			config, ok, err := a.checkConfig(ctx, remote)
+			if errors.Is(err, errRemoteConfigUnsupported) {
+				a.logger.Printf("ℹ Backend does not serve agent config, using local config only")
+				remote = nil
+				continue
+			}
+			if ok {
+				a.applyConfig(config, fmt.Sprintf("%s (version %d)", remote.source, config.ConfigVersion))
+			}
+		}
+	}
+}
+
+// checkConfig loads l and reports whether it produced a new config. Errors
+// are logged before they are returned.
+func (a *agent) checkConfig(ctx context.Context, l *configLoader) (AgentConfig, bool, error) {
+	config, changed, err := l.Load(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.rejectConfig(l, err)
+		}
+		return AgentConfig{}, false, err
+	}
+	l.lastErr = ""
+	return config, changed, nil
+}
+
+func (a *agent) rejectConfig(l *configLoader, err error) {
+	if err.Error() == l.lastErr {
+		return
+	}
+	l.lastErr = err.Error()
+	if errors.Is(err, errRemoteConfigUnsupported) {
+		return
+	}
+	a.logger.Printf("❌ Rejected config, keeping last good config: %v", err)
+	a.metrics.ObserveConfigReload(err)
+}
+
+func (a *agent) applyConfig(config AgentConfig, source string) {
+	a.setConfig(config)
+	a.metrics.ObserveConfigReload(nil)
+	a.logger.Printf("✓ Reloaded config from %s", source)
+	a.logger.Printf("  Poll Interval: %v", config.PollInterval)
+	a.logger.Printf("  Filters: %s", filterLabel(config.Filter))
+}
+
+// setConfig makes config current and wakes the detection loop so it takes
+// effect right away.
+func (a *agent) setConfig(config AgentConfig) {
+	a.config.Store(&config)
+	if a.delta != nil {
+		a.delta.SetSnapshotInterval(config.SnapshotInterval)
+	}
+	select {
+	case a.reloaded <- struct{}{}:
+	default:
+	}
+}
+
+// cfg returns the current config. Callers that read several fields should
+// keep the returned value rather than call cfg again, so a reload cannot
+// land between reads.
+func (a *agent) cfg() AgentConfig {
+	return *a.config.Load()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"kuberoot/internal/k8s"
+)
+
+const (
+	reportModeFull      = "full"
+	reportModeSnapshot  = "snapshot"
+	reportModeDelta     = "delta"
+	reportModeHeartbeat = "heartbeat"
+)
+
+// deltaTracker remembers what the backend was last told so each report only
+// carries new, changed and cleared failures. A full snapshot is sent on start,
+// periodically, and whenever the backend reports it has lost track.
+type deltaTracker struct {
+	mu               sync.Mutex
+	snapshotInterval time.Duration
+	known            map[string]string // failure key -> fingerprint last reported
+	sequence         int64
+	lastSnapshot     time.Time
+	resync           bool
+}
+
+func newDeltaTracker(snapshotInterval time.Duration) *deltaTracker {
+	return &deltaTracker{snapshotInterval: snapshotInterval}
+}
+
+// SetSnapshotInterval changes how often later reports are full snapshots.
+func (t *deltaTracker) SetSnapshotInterval(interval time.Duration) {
+	t.mu.Lock()
+	t.snapshotInterval = interval
+	t.mu.Unlock()
+}
+
+// Build turns the current failure set into the next payload in the sequence.
+func (t *deltaTracker) Build(clusterID string, failures []k8s.PodFailure, now time.Time) AgentPayload {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[string]string, len(failures))
+	for _, f := range failures {
+		current[k8s.FailureKey(f)] = k8s.FailureFingerprint(f)
+	}
+
+	payload := AgentPayload{
+		ClusterID:    clusterID,
+		Timestamp:    now,
+		BaseSequence: t.sequence,
+	}
+	t.sequence++
+	payload.Sequence = t.sequence
+
+	if t.known == nil || t.resync || now.Sub(t.lastSnapshot) >= t.snapshotInterval {
+		payload.Mode = reportModeSnapshot
+		payload.Failures = failures
+		t.known = current
+		t.lastSnapshot = now
+		t.resync = false
+		return payload
+	}
+
+	for _, f := range failures {
+		key := k8s.FailureKey(f)
+		if t.known[key] != current[key] {
+			payload.Failures = append(payload.Failures, f)
+		}
+	}
+	for key := range t.known {
+		if _, ok := current[key]; !ok {
+			payload.Cleared = append(payload.Cleared, key)
+		}
+	}
+	sort.Strings(payload.Cleared)
+
+	payload.Mode = reportModeDelta
+	if len(payload.Failures) == 0 && len(payload.Cleared) == 0 {
+		payload.Mode = reportModeHeartbeat
+	}
+	t.known = current
+	return payload
+}
+
+// RequestResync makes the next payload a full snapshot.
+func (t *deltaTracker) RequestResync() {
+	t.mu.Lock()
+	t.resync = true
+	t.mu.Unlock()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"kuberoot/internal/k8s"
+)
+
+func TestDeltaTrackerBuild(t *testing.T) {
+	crash := k8s.PodFailure{Namespace: "shop", Name: "api-1", Container: "api", Types: []string{string(k8s.FailureCrashLoopBackOff)}, RestartCount: 3}
+	oom := k8s.PodFailure{Namespace: "shop", Name: "worker-1", Container: "worker", Types: []string{string(k8s.FailureOOMKilled)}}
+	pull := k8s.PodFailure{Namespace: "web", Name: "site-1", Container: "nginx", Types: []string{string(k8s.FailureImagePullBackOff)}}
+
+	crashRestarted := crash
+	crashRestarted.RestartCount = 5 // same restart bucket
+	crashChanged := crash
+	crashChanged.Message = "exec format error"
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []struct {
+		name        string
+		after       time.Duration
+		resync      bool
+		failures    []k8s.PodFailure
+		wantMode    string
+		wantSent    []string
+		wantCleared []string
+	}{
+		{name: "first report is a snapshot", failures: []k8s.PodFailure{crash, oom}, wantMode: reportModeSnapshot, wantSent: []string{"shop/api-1/api", "shop/worker-1/worker"}},
+		{name: "nothing changed", after: time.Minute, failures: []k8s.PodFailure{crashRestarted, oom}, wantMode: reportModeHeartbeat},
+		{name: "changed, new and cleared", after: 2 * time.Minute, failures: []k8s.PodFailure{crashChanged, pull}, wantMode: reportModeDelta, wantSent: []string{"shop/api-1/api", "web/site-1/nginx"}, wantCleared: []string{"shop/worker-1/worker"}},
+		{name: "all cleared", after: 3 * time.Minute, wantMode: reportModeDelta, wantCleared: []string{"shop/api-1/api", "web/site-1/nginx"}},
+		{name: "resync requested", after: 4 * time.Minute, resync: true, failures: []k8s.PodFailure{oom}, wantMode: reportModeSnapshot, wantSent: []string{"shop/worker-1/worker"}},
+		{name: "snapshot interval elapsed", after: 15 * time.Minute, failures: []k8s.PodFailure{oom}, wantMode: reportModeSnapshot, wantSent: []string{"shop/worker-1/worker"}},
+	}
+
+	tracker := newDeltaTracker(10 * time.Minute)
+	for i, step := range steps {
+		if step.resync {
+			tracker.RequestResync()
+		}
+		payload := tracker.Build("prod", step.failures, start.Add(step.after))
+
+		if payload.Mode != step.wantMode {
+			t.Errorf("%s: mode = %q, want %q", step.name, payload.Mode, step.wantMode)
+		}
+		if want := int64(i + 1); payload.Sequence != want || payload.BaseSequence != want-1 {
+			t.Errorf("%s: sequence %d on %d, want %d on %d", step.name, payload.Sequence, payload.BaseSequence, want, want-1)
+		}
+		var sent []string
+		for _, f := range payload.Failures {
+			sent = append(sent, k8s.FailureKey(f))
+		}
+		if !reflect.DeepEqual(sent, step.wantSent) {
+			t.Errorf("%s: sent %v, want %v", step.name, sent, step.wantSent)
+		}
+		if !reflect.DeepEqual(payload.Cleared, step.wantCleared) {
+			t.Errorf("%s: cleared %v, want %v", step.name, payload.Cleared, step.wantCleared)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// runWithLeaderElection campaigns for the agent Lease and only detects and
+// reports while holding it. Losing the Lease stops detection and delivery and
+// puts the replica back in the running as a standby, so the process never
+// exits just because another replica took over. It returns once ctx is
+// cancelled.
+func (a *agent) runWithLeaderElection(ctx context.Context) {
+	config := a.cfg() // election settings are not reloadable
+	// Held for a whole term, so a new term cannot start delivering until
+	// the last one has stopped and cleared its outbox.
+	var term sync.Mutex
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      config.LeaseName,
+			Namespace: config.LeaderElectionNamespace,
+		},
+		Client: a.cs.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: config.Identity,
+		},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            config.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				term.Lock()
+				defer term.Unlock()
+
+				a.logger.Printf("✓ Acquired leadership as %s", config.Identity)
+				a.metrics.SetLeading(true)
+				if a.delta != nil {
+					// The previous leader moved the backend's sequence on.
+					a.delta.RequestResync()
+				}
+				delivered := make(chan struct{})
+				go func() {
+					defer close(delivered)
+					a.outbox.Run(leaderCtx, a.sendReport)
+				}()
+				a.run(leaderCtx)
+				<-delivered
+
+				// Reports still queued were captured under this lease. The
+				// next leader starts from a snapshot, so sending them later
+				// could only roll the backend's state back.
+				if discarded := a.outbox.Discard(); discarded > 0 {
+					a.logger.Printf("ℹ Discarded %d queued report(s) from the lost lease", discarded)
+				}
+			},
+			OnStoppedLeading: func() {
+				a.metrics.SetLeading(false)
+				a.logger.Printf("ℹ Stopped leading, returning to standby")
+			},
+			OnNewLeader: func(identity string) {
+				if identity != config.Identity {
+					a.logger.Printf("ℹ Standing by; current leader is %s", identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		a.logger.Fatalf("Invalid leader election config: %v", err)
+	}
+
+	a.metrics.SetLeading(false)
+	for ctx.Err() == nil {
+		elector.Run(ctx)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(config.RetryPeriod):
+		}
+	}
+}
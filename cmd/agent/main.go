@@ -1,41 +1,184 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"kuberoot/internal/k8s"
+	"kuberoot/internal/redact"
 )
 
 type AgentPayload struct {
-	ClusterID string       `json:"clusterId"`
-	Timestamp time.Time    `json:"timestamp"`
+	ClusterID string           `json:"clusterId"`
+	Timestamp time.Time        `json:"timestamp"`
 	Failures  []k8s.PodFailure `json:"failures"`
+
+	// Delta protocol fields; empty Mode means a legacy full report.
+	Mode         string   `json:"mode,omitempty"`
+	Sequence     int64    `json:"sequence,omitempty"`
+	BaseSequence int64    `json:"baseSequence,omitempty"`
+	Cleared      []string `json:"cleared,omitempty"`
+
+	// ReportID is stable across retries; the chunk fields are only set when a
+	// report is split to fit the backend's body limit.
+	ReportID   string `json:"reportId,omitempty"`
+	ChunkIndex int    `json:"chunkIndex,omitempty"`
+	ChunkCount int    `json:"chunkCount,omitempty"`
+
+	// Filters is the pod filter set the agent is running with, if any.
+	Filters *k8s.FailureFilter `json:"filters,omitempty"`
+
+	// Leader is the identity of the replica that produced the report when
+	// leader election is enabled.
+	Leader string `json:"leader,omitempty"`
+
+	// ConfigVersion is the backend config version in effect, 0 when the
+	// agent runs on local config only.
+	ConfigVersion int64 `json:"configVersion,omitempty"`
+
+	// Redactions counts what was scrubbed from the failures detected in this
+	// cycle, including unchanged ones a delta report leaves out.
+	Redactions *redact.Summary `json:"redactions,omitempty"`
+}
+
+// AgentReportResponse is the subset of the backend reply the agent acts on.
+type AgentReportResponse struct {
+	Status         string `json:"status"`
+	ResyncRequired bool   `json:"resyncRequired,omitempty"`
+	ConfigVersion  int64  `json:"configVersion,omitempty"`
 }
 
 type AgentConfig struct {
-	BackendURL  string
-	APIKey      string
-	ClusterID   string
-	PollInterval time.Duration
+	BackendURL    string
+	APIKey        string
+	SigningSecret string // empty sends unsigned reports
+	ClusterID     string
+	PollInterval  time.Duration
+	Watch         bool
+
+	RemoteConfig  bool  // fetch config from the backend
+	ConfigVersion int64 // backend config version applied, 0 for none
+
+	OutboxDir        string
+	OutboxMaxEntries int
+
+	ReportMode       string
+	SnapshotInterval time.Duration
+
+	Compress        bool
+	MaxRequestBytes int
+	RequestTimeout  time.Duration
+
+	IncludeNamespaces []string
+	ExcludeNamespaces []string
+	PodSelector       string
+	ExcludeOwnerKinds []string
+
+	Events            bool // attach recent pod events
+	WorkloadContext   bool // resolve deployments, services and config refs
+	LogTailNamespaces []string
+	LogTailLines      int64
+	LogTailBytes      int64
+	RedactPatterns    []string
+	RedactDropFields  []string
+
+	// Built from the fields above by compile.
+	Filter    *k8s.FailureFilter // nil watches every pod
+	LogTail   *k8s.LogTailConfig // nil captures no logs
+	Redaction *k8s.Redaction
+
+	KubeQPS   float32
+	KubeBurst int
+
+	MetricsAddr   string
+	ShutdownGrace time.Duration
+
+	LeaderElect             bool
+	LeaderElectionNamespace string
+	LeaseName               string
+	LeaseDuration           time.Duration
+	RenewDeadline           time.Duration
+	RetryPeriod             time.Duration
+	Identity                string
 }
 
+func (c AgentConfig) collectOptions() k8s.Options {
+	return k8s.Options{
+		Filter:              c.Filter,
+		LogTail:             c.LogTail,
+		SkipEvents:          !c.Events,
+		SkipWorkloadContext: !c.WorkloadContext,
+	}
+}
+
+// reportDebounce coalesces bursts of pod updates (a rollout touches many pods
+// at once) into a single report.
+const reportDebounce = 2 * time.Second
+
+// Backoff between attempts to start a cluster's informers while its API
+// server is unreachable.
+const (
+	watchMinBackoff = 5 * time.Second
+	watchMaxBackoff = 5 * time.Minute
+)
+
 func main() {
 	// Parse flags
 	backendURL := flag.String("backend", os.Getenv("KUBEROOT_BACKEND_URL"), "Backend URL (env: KUBEROOT_BACKEND_URL)")
 	apiKey := flag.String("api-key", os.Getenv("KUBEROOT_API_KEY"), "API Key (env: KUBEROOT_API_KEY)")
+	signingSecret := flag.String("signing-secret", os.Getenv("KUBEROOT_SIGNING_SECRET"), "Secret for HMAC-signing reports, issued with the API key (env: KUBEROOT_SIGNING_SECRET)")
 	clusterID := flag.String("cluster-id", os.Getenv("KUBEROOT_CLUSTER_ID"), "Cluster ID")
-	pollInterval := flag.Duration("poll-interval", 30*time.Second, "Poll interval for failures")
+	pollInterval := flag.Duration("poll-interval", 30*time.Second, "Poll interval for failures (resync interval in watch mode)")
+	watch := flag.Bool("watch", os.Getenv("KUBEROOT_WATCH") != "false", "Detect failures from informer caches on pod updates (env: KUBEROOT_WATCH)")
+	outboxDir := flag.String("outbox-dir", envOrDefault("KUBEROOT_OUTBOX_DIR", filepath.Join(os.TempDir(), "kuberoot-outbox")), "Directory used to spool undelivered reports (env: KUBEROOT_OUTBOX_DIR)")
+	outboxMax := flag.Int("outbox-max-entries", 500, "Maximum reports kept in the outbox; oldest are dropped first")
+	reportMode := flag.String("report-mode", envOrDefault("KUBEROOT_REPORT_MODE", reportModeFull), "Report protocol: full or delta (env: KUBEROOT_REPORT_MODE)")
+	snapshotInterval := flag.Duration("snapshot-interval", 10*time.Minute, "In delta mode, how often to send a full snapshot")
+	compress := flag.Bool("compress", os.Getenv("KUBEROOT_COMPRESS") != "false", "Gzip report bodies (env: KUBEROOT_COMPRESS)")
+	maxRequestBytes := flag.Int("max-request-bytes", 900*1024, "Largest request body sent to the backend; bigger reports are split into chunks")
+	includeNamespaces := flag.String("include-namespaces", os.Getenv("KUBEROOT_INCLUDE_NAMESPACES"), "Comma-separated namespace globs to watch; empty watches all (env: KUBEROOT_INCLUDE_NAMESPACES)")
+	excludeNamespaces := flag.String("exclude-namespaces", os.Getenv("KUBEROOT_EXCLUDE_NAMESPACES"), "Comma-separated namespace globs to ignore (env: KUBEROOT_EXCLUDE_NAMESPACES)")
+	podSelector := flag.String("pod-selector", os.Getenv("KUBEROOT_POD_SELECTOR"), "Only watch pods matching this label selector (env: KUBEROOT_POD_SELECTOR)")
+	metricsAddr := flag.String("metrics-addr", envOrDefault("KUBEROOT_METRICS_ADDR", ":9090"), "Address for /healthz, /readyz and /metrics; empty disables (env: KUBEROOT_METRICS_ADDR)")
+	logTailNamespaces := flag.String("log-tail-namespaces", os.Getenv("KUBEROOT_LOG_TAIL_NAMESPACES"), "Comma-separated namespace globs whose failing containers get log tails attached; needs pods/log RBAC (env: KUBEROOT_LOG_TAIL_NAMESPACES)")
+	logTailLines := flag.Int64("log-tail-lines", 50, "Log lines captured per container stream")
+	logTailBytes := flag.Int64("log-tail-bytes", 4096, "Byte budget per captured log stream")
+	redactPatterns := splitLines(os.Getenv("KUBEROOT_REDACT_PATTERNS"))
+	flag.Func("redact-pattern", "Extra regexp scrubbed from reports alongside the built-in credential detectors; repeatable (env: KUBEROOT_REDACT_PATTERNS, one per line)", func(v string) error {
+		redactPatterns = append(redactPatterns, v)
+		return nil
+	})
+	dropFields := flag.String("drop-fields", os.Getenv("KUBEROOT_DROP_FIELDS"), "Comma-separated failure fields never sent to the backend: "+strings.Join(k8s.RedactableFields(), ", ")+" (env: KUBEROOT_DROP_FIELDS)")
+	shutdownGrace := flag.Duration("shutdown-grace", 10*time.Second, "On SIGTERM, how long to keep trying to deliver queued reports; with --leader-elect they are dropped with the Lease instead")
+	leaderElect := flag.Bool("leader-elect", os.Getenv("KUBEROOT_LEADER_ELECT") == "true", "Only the replica holding the Lease detects and reports (env: KUBEROOT_LEADER_ELECT)")
+	leaderNamespace := flag.String("leader-election-namespace", envOrDefault("POD_NAMESPACE", "kuberoot"), "Namespace of the leader election Lease (env: POD_NAMESPACE)")
+	leaseName := flag.String("leader-election-lease", "kuberoot-agent", "Name of the leader election Lease")
+	leaseDuration := flag.Duration("leader-election-lease-duration", 15*time.Second, "How long standbys wait before taking over from an unresponsive leader")
+	renewDeadline := flag.Duration("leader-election-renew-deadline", 10*time.Second, "How long the leader keeps retrying to renew before giving up leadership")
+	retryPeriod := flag.Duration("leader-election-retry-period", 2*time.Second, "How often candidates try to acquire or renew the Lease")
+	excludeOwnerKinds := flag.String("exclude-owner-kinds", os.Getenv("KUBEROOT_EXCLUDE_OWNER_KINDS"), "Comma-separated controller kinds to ignore, e.g. Job,DaemonSet (env: KUBEROOT_EXCLUDE_OWNER_KINDS)")
+	kubeQPS := flag.Float64("kube-qps", 20, "Sustained Kubernetes API requests per second the agent may make")
+	kubeBurst := flag.Int("kube-burst", 40, "Kubernetes API request burst allowed above --kube-qps")
+	requestTimeout := flag.Duration("request-timeout", 10*time.Second, "Timeout for each report request to the backend")
+	configFile := flag.String("config", os.Getenv("KUBEROOT_CONFIG"), "YAML config file layered over flags and reloaded on change (env: KUBEROOT_CONFIG)")
+	clusters := flag.String("clusters", os.Getenv("KUBEROOT_CLUSTERS"), "Comma-separated kubeconfig contexts to monitor, as context or context=clusterID; overrides --cluster-id (env: KUBEROOT_CLUSTERS)")
+	kubeconfig := flag.String("kubeconfig", "", "Kubeconfig holding the --clusters contexts (default $KUBECONFIG or ~/.kube/config)")
+	kubeconfigDir := flag.String("kubeconfig-dir", os.Getenv("KUBEROOT_KUBECONFIG_DIR"), "Directory of kubeconfig files to monitor, one cluster per file named after its cluster ID (env: KUBEROOT_KUBECONFIG_DIR)")
+	remoteConfig := flag.Bool("remote-config", os.Getenv("KUBEROOT_REMOTE_CONFIG") == "true", "Register with the backend and apply the config it serves for this cluster over local config (env: KUBEROOT_REMOTE_CONFIG)")
+	configMap := flag.String("config-map", os.Getenv("KUBEROOT_CONFIG_MAP"), "Read the YAML config from namespace/name[/key] through the API instead of a file; needs configmaps get RBAC (env: KUBEROOT_CONFIG_MAP)")
 	flag.Parse()
 
 	// Validate config
@@ -48,69 +191,406 @@ func main() {
 	if *clusterID == "" {
 		*clusterID = "local"
 	}
+	if *reportMode != reportModeFull && *reportMode != reportModeDelta {
+		log.Fatalf("--report-mode must be %q or %q", reportModeFull, reportModeDelta)
+	}
+	if *configFile != "" && *configMap != "" {
+		log.Fatal("--config and --config-map are mutually exclusive")
+	}
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
 
 	config := AgentConfig{
-		BackendURL:   *backendURL,
-		APIKey:       *apiKey,
-		ClusterID:    *clusterID,
-		PollInterval: *pollInterval,
+		BackendURL:    *backendURL,
+		APIKey:        *apiKey,
+		SigningSecret: *signingSecret,
+		ClusterID:     *clusterID,
+		PollInterval:  *pollInterval,
+		Watch:         *watch,
+		RemoteConfig:  *remoteConfig,
+
+		OutboxDir:        *outboxDir,
+		OutboxMaxEntries: *outboxMax,
+
+		ReportMode:       *reportMode,
+		SnapshotInterval: *snapshotInterval,
+
+		Compress:        *compress,
+		MaxRequestBytes: *maxRequestBytes,
+		RequestTimeout:  *requestTimeout,
+
+		IncludeNamespaces: splitList(*includeNamespaces),
+		ExcludeNamespaces: splitList(*excludeNamespaces),
+		PodSelector:       *podSelector,
+		ExcludeOwnerKinds: splitList(*excludeOwnerKinds),
+
+		Events:            true,
+		WorkloadContext:   true,
+		LogTailNamespaces: splitList(*logTailNamespaces),
+		LogTailLines:      *logTailLines,
+		LogTailBytes:      *logTailBytes,
+		RedactPatterns:    redactPatterns,
+		RedactDropFields:  splitList(*dropFields),
+
+		KubeQPS:   float32(*kubeQPS),
+		KubeBurst: *kubeBurst,
+
+		MetricsAddr:   *metricsAddr,
+		ShutdownGrace: *shutdownGrace,
+
+		LeaderElect:             *leaderElect,
+		LeaderElectionNamespace: *leaderNamespace,
+		LeaseName:               *leaseName,
+		LeaseDuration:           *leaseDuration,
+		RenewDeadline:           *renewDeadline,
+		RetryPeriod:             *retryPeriod,
+		Identity:                identity,
 	}
 
-	log.Printf("Kuberoot Agent Starting")
-	log.Printf("  Backend: %s", config.BackendURL)
-	log.Printf("  Cluster: %s", config.ClusterID)
-	log.Printf("  Poll Interval: %v", config.PollInterval)
+	if err := config.compile(); err != nil {
+		log.Fatalf("Invalid agent config: %v", err)
+	}
 
-	// Try in-cluster config first
-	var cs *kubernetes.Clientset
-	var err error
+	targets, err := parseClusterTargets(*clusters, *kubeconfig, *kubeconfigDir)
+	if err != nil {
+		log.Fatalf("Invalid cluster list: %v", err)
+	}
+	if len(targets) > 0 && config.LeaderElect {
+		log.Fatal("--leader-elect cannot be combined with --clusters or --kubeconfig-dir")
+	}
+	if len(targets) > 0 && *configMap != "" {
+		log.Fatal("--config-map cannot be combined with --clusters or --kubeconfig-dir; use --config")
+	}
 
-	kubeConfig, inClusterErr := rest.InClusterConfig()
-	if inClusterErr == nil {
-		cs, err = kubernetes.NewForConfig(kubeConfig)
+	log.Printf("Kuberoot Agent Starting")
+	var agents []*agent
+	if len(targets) == 0 {
+		// Try in-cluster config first
+		kubeConfig, err := rest.InClusterConfig()
+		if err == nil {
+			log.Printf("✓ Using in-cluster Kubernetes config")
+		} else {
+			log.Printf("ℹ Not running in-cluster, using kubeconfig...")
+			kubeConfig, err = k8s.LoadConfig()
+			if err != nil {
+				log.Fatalf("Failed to load Kubernetes config: %v", err)
+			}
+		}
+
+		a, err := newAgent(config, kubeConfig, log.Default())
 		if err != nil {
-			log.Fatalf("Failed to create clientset from in-cluster config: %v", err)
+			log.Fatalf("Failed to start agent: %v", err)
 		}
-		log.Printf("✓ Using in-cluster Kubernetes config")
+		log.Printf("✓ Connected to Kubernetes cluster")
+		agents = append(agents, a)
 	} else {
-		log.Printf("ℹ Not running in-cluster, using kubeconfig...")
-		cs, err = k8s.NewClientset()
-		if err != nil {
-			log.Fatalf("Failed to create clientset: %v", err)
+		// Each cluster gets its own client, outbox and detection loop, so a
+		// broken kubeconfig only drops that cluster.
+		for _, target := range targets {
+			clusterConfig := config
+			clusterConfig.ClusterID = target.ClusterID
+			clusterConfig.OutboxDir = filepath.Join(config.OutboxDir, target.ClusterID)
+			logger := log.New(log.Writer(), "["+target.ClusterID+"] ", log.Flags()|log.Lmsgprefix)
+
+			kubeConfig, _, err := k8s.LoadContextConfig(target.Kubeconfig, target.Context)
+			if err != nil {
+				log.Printf("❌ Skipping cluster %s: %v", target, err)
+				continue
+			}
+			a, err := newAgent(clusterConfig, kubeConfig, logger)
+			if err != nil {
+				log.Printf("❌ Skipping cluster %s: %v", target, err)
+				continue
+			}
+			log.Printf("✓ Monitoring cluster %s", target)
+			agents = append(agents, a)
+		}
+		if len(agents) == 0 {
+			log.Fatal("No cluster could be loaded")
 		}
 	}
 
-	log.Printf("✓ Connected to Kubernetes cluster")
+	local := make([]*configLoader, len(agents))
+	remote := make([]*configLoader, len(agents))
+	for i, a := range agents {
+		switch {
+		case *configFile != "":
+			local[i] = newFileConfigLoader(a.cfg(), *configFile)
+		case *configMap != "":
+			local[i], err = newConfigMapLoader(a.cfg(), a.cs, *configMap)
+			if err != nil {
+				log.Fatalf("Invalid --config-map: %v", err)
+			}
+		}
+		if local[i] != nil {
+			loaded, _, err := local[i].Load(context.Background())
+			if err != nil {
+				log.Fatalf("Failed to load agent config: %v", err)
+			}
+			a.config.Store(&loaded)
+			if a.delta != nil {
+				a.delta.SetSnapshotInterval(loaded.SnapshotInterval)
+			}
+			if i == 0 {
+				log.Printf("✓ Loaded config from %s", local[i].source)
+			}
+		}
+		if config.RemoteConfig {
+			remote[i] = a.loadRemoteConfig(newRemoteConfigLoader(a.cfg(), a))
+		}
+	}
+
+	config = agents[0].cfg()
+	log.Printf("  Backend: %s", config.BackendURL)
+	if len(targets) == 0 {
+		log.Printf("  Cluster: %s", config.ClusterID)
+	} else {
+		log.Printf("  Clusters: %d", len(agents))
+	}
+	log.Printf("  Poll Interval: %v", config.PollInterval)
+	log.Printf("  Watch Mode: %v", config.Watch)
+	log.Printf("  Outbox: %s (max %d)", config.OutboxDir, config.OutboxMaxEntries)
+	log.Printf("  Report Mode: %s", config.ReportMode)
+	log.Printf("  Compression: %v (max request %d bytes)", config.Compress, config.MaxRequestBytes)
+	log.Printf("  Signed Reports: %v", config.SigningSecret != "")
+	log.Printf("  Filters: %s", filterLabel(config.Filter))
+	if config.LeaderElect {
+		log.Printf("  Leader Election: %s/%s as %s (lease %v)", config.LeaderElectionNamespace, config.LeaseName, config.Identity, config.LeaseDuration)
+	}
+	if config.LogTail != nil {
+		log.Printf("  Log Tails: %d lines / %d bytes in %s", config.LogTail.Lines, config.LogTail.MaxBytes, strings.Join(config.LogTail.Namespaces, ","))
+	}
+	if dropped := config.Redaction.DroppedFields(); len(dropped) > 0 {
+		log.Printf("  Dropped Fields: %s", strings.Join(dropped, ","))
+	}
+
+	// Start detection loops
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	if config.MetricsAddr != "" {
+		go serveHTTP(ctx, config.MetricsAddr, agents, log.Default())
+	}
 
-	// Start detection loop
-	runAgentLoop(cs, config)
+	var wg sync.WaitGroup
+	for i, a := range agents {
+		wg.Add(1)
+		go func(a *agent, local, remote *configLoader) {
+			defer wg.Done()
+			a.serve(ctx, local, remote)
+		}(a, local[i], remote[i])
+	}
+	wg.Wait()
 }
 
-func runAgentLoop(cs *kubernetes.Clientset, config AgentConfig) {
-	ticker := time.NewTicker(config.PollInterval)
+// agent ties the detection loop of one cluster to the durable outbox that
+// delivers its reports.
+type agent struct {
+	config      atomic.Pointer[AgentConfig] // swapped on config reload; read via cfg
+	reloaded    chan struct{}               // signalled after each reload
+	configStale chan struct{}               // signalled when the backend wants another config version
+	cs          *kubernetes.Clientset
+	outbox      *outbox
+	delta       *deltaTracker // nil in full report mode
+	metrics     *metrics
+	logger      *log.Logger
+}
+
+// newAgent connects to the cluster behind kubeConfig and opens the outbox
+// for config.ClusterID.
+func newAgent(config AgentConfig, kubeConfig *rest.Config, logger *log.Logger) (*agent, error) {
+	agentMetrics := newMetrics()
+	kubeConfig.Wrap(agentMetrics.WrapTransport)
+	// Client-side limit, so an incident with many failing pods cannot turn
+	// enrichment into a burst against the API server.
+	kubeConfig.QPS = config.KubeQPS
+	kubeConfig.Burst = config.KubeBurst
+
+	cs, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create clientset: %w", err)
+	}
+	box, err := newOutbox(config.OutboxDir, config.OutboxMaxEntries, logger)
+	if err != nil {
+		return nil, fmt.Errorf("open outbox: %w", err)
+	}
+
+	a := &agent{
+		reloaded:    make(chan struct{}, 1),
+		configStale: make(chan struct{}, 1),
+		cs:          cs,
+		outbox:      box,
+		metrics:     agentMetrics,
+		logger:      logger,
+	}
+	a.config.Store(&config)
+	if config.ReportMode == reportModeDelta {
+		a.delta = newDeltaTracker(config.SnapshotInterval)
+	}
+	return a, nil
+}
+
+// serve runs detection and delivery until ctx is cancelled, then gives the
+// outbox one last chance to deliver. Under leader election delivery is tied
+// to the lease instead, see runWithLeaderElection.
+func (a *agent) serve(ctx context.Context, local, remote *configLoader) {
+	if local != nil || remote != nil {
+		go a.watchConfig(ctx, local, remote)
+	}
+
+	if a.cfg().LeaderElect {
+		a.runWithLeaderElection(ctx)
+		return
+	}
+
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		a.outbox.Run(ctx, a.sendReport)
+	}()
+	a.run(ctx)
+
+	// Reports already queued are on disk; try once more to deliver them
+	// before the kubelet's grace period runs out.
+	grace := a.cfg().ShutdownGrace
+	a.logger.Printf("ℹ Shutting down, flushing outbox for up to %v", grace)
+	a.outbox.Drain(outboxDone, grace, a.sendReport)
+}
+
+// run detects and reports until ctx is cancelled.
+func (a *agent) run(ctx context.Context) {
+	if a.cfg().Watch {
+		a.runWatchLoop(ctx)
+		return
+	}
+	a.runAgentLoop(ctx)
+}
+
+func (a *agent) runAgentLoop(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg().PollInterval)
 	defer ticker.Stop()
 
+	detect := func(ctx context.Context) ([]k8s.PodFailure, error) {
+		return k8s.GetFailedPods(ctx, a.cs, a.cfg().collectOptions())
+	}
+
 	// Run once immediately
-	detectAndReport(cs, config)
+	a.detectAndReport(ctx, detect)
 
-	// Then run on interval
-	for range ticker.C {
-		detectAndReport(cs, config)
+	// Then run on interval, and straight away after a reload
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-a.reloaded:
+			ticker.Reset(a.cfg().PollInterval)
+		}
+		a.detectAndReport(ctx, detect)
 	}
 }
 
-func detectAndReport(cs *kubernetes.Clientset, config AgentConfig) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// runWatchLoop reports from informer caches as soon as a pod's failure state
+// changes. The ticker is only a resync safety net so the backend still hears
+// from the agent when nothing changes.
+func (a *agent) runWatchLoop(ctx context.Context) {
+	backoff := watchMinBackoff
+	for ctx.Err() == nil {
+		err := a.watchUntilRescoped(ctx)
+		if err == nil || ctx.Err() != nil {
+			backoff = watchMinBackoff
+			continue
+		}
+		// Retried rather than fatal: with several clusters one unreachable
+		// API server must not take the others down.
+		a.logger.Printf("❌ Failed to run watcher, retrying in %v: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, watchMaxBackoff)
+	}
+}
+
+// watchUntilRescoped runs one set of informers until ctx is cancelled or a
+// reload moves the filter to a different namespace scope, which the
+// informers cannot follow. Other reloads are applied to the running watcher.
+// It returns an error when the informers could not be started.
+func (a *agent) watchUntilRescoped(parent context.Context) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	config := a.cfg()
+	watcher, err := k8s.NewWatcher(a.cs, config.PollInterval, config.collectOptions())
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	if err := watcher.Start(ctx); err != nil {
+		return fmt.Errorf("start watcher: %w", err)
+	}
+	a.logger.Printf("✓ Informer caches synced")
+
+	ticker := time.NewTicker(config.PollInterval)
+	defer ticker.Stop()
+
+	a.detectAndReport(ctx, watcher.FailedPods)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-a.reloaded:
+			config = a.cfg()
+			if config.Filter.ListNamespace() != watcher.Namespace() {
+				a.logger.Printf("ℹ Namespace scope changed, restarting informers")
+				return nil
+			}
+			watcher.SetOptions(config.collectOptions())
+			ticker.Reset(config.PollInterval)
+		case <-watcher.Changes():
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(reportDebounce):
+			}
+			select {
+			case <-watcher.Changes():
+			default:
+			}
+			ticker.Reset(a.cfg().PollInterval)
+		}
+		a.detectAndReport(ctx, watcher.FailedPods)
+	}
+}
+
+func (a *agent) detectAndReport(parent context.Context, detect func(context.Context) ([]k8s.PodFailure, error)) {
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
 	defer cancel()
 
 	// Detect failures
-	failures, err := k8s.GetFailedPods(ctx, cs)
+	start := time.Now()
+	failures, err := detect(ctx)
+	a.metrics.ObserveDetection(time.Since(start), failures, err)
 	if err != nil {
-		log.Printf("❌ Failed to detect failures: %v", err)
+		if parent.Err() != nil {
+			a.logger.Printf("ℹ Detection interrupted by shutdown")
+			return
+		}
+		a.logger.Printf("❌ Failed to detect failures: %v", err)
 		return
 	}
 
-	log.Printf("📊 Detected %d failures", len(failures))
+	a.logger.Printf("📊 Detected %d failures", len(failures))
+
+	config := a.cfg()
+
+	// Scrub before anything is queued, so secrets never reach the outbox on
+	// disk either.
+	redactions := config.Redaction.Apply(failures)
+	a.metrics.ObserveRedactions(redactions)
 
 	// Build payload
 	payload := AgentPayload{
@@ -118,48 +598,81 @@ func detectAndReport(cs *kubernetes.Clientset, config AgentConfig) {
 		Timestamp: time.Now().UTC(),
 		Failures:  failures,
 	}
+	if a.delta != nil {
+		payload = a.delta.Build(config.ClusterID, failures, payload.Timestamp)
+	}
+	payload.Filters = config.Filter
+	payload.ConfigVersion = config.ConfigVersion
+	if !redactions.Empty() {
+		payload.Redactions = &redactions
+	}
+	if config.LeaderElect {
+		payload.Leader = config.Identity
+	}
 
-	// Send to backend
-	if err := sendReport(config, payload); err != nil {
-		log.Printf("❌ Failed to send report: %v", err)
+	// Spool to the outbox; the sender goroutine delivers it with retries.
+	if err := a.outbox.Enqueue(payload); err != nil {
+		a.logger.Printf("❌ Failed to queue report: %v", err)
 		return
 	}
 
-	log.Printf("✓ Report sent to backend (%d failures)", len(failures))
+	queued, oldest := a.outbox.Stats()
+	a.logger.Printf("📦 Report queued (%s, %d failures, %d in outbox, oldest %v)", reportModeLabel(payload), len(payload.Failures), queued, oldest.Round(time.Second))
 }
 
-func sendReport(config AgentConfig, payload AgentPayload) error {
-	// Marshal payload
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
+func reportModeLabel(payload AgentPayload) string {
+	if payload.Mode == "" {
+		return reportModeFull
 	}
-
-	// Create request
-	url := fmt.Sprintf("%s/api/v1/agent/report", config.BackendURL)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+	if len(payload.Cleared) > 0 {
+		return fmt.Sprintf("%s, %d cleared", payload.Mode, len(payload.Cleared))
 	}
+	return payload.Mode
+}
 
-	// Add headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", config.APIKey)
-
-	// Send request
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+func filterLabel(filter *k8s.FailureFilter) string {
+	if filter == nil {
+		return "none (all pods)"
 	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("send request: %w", err)
+	var parts []string
+	if len(filter.IncludeNamespaces) > 0 {
+		parts = append(parts, "include="+strings.Join(filter.IncludeNamespaces, ","))
+	}
+	if len(filter.ExcludeNamespaces) > 0 {
+		parts = append(parts, "exclude="+strings.Join(filter.ExcludeNamespaces, ","))
+	}
+	if filter.LabelSelector != "" {
+		parts = append(parts, "selector="+filter.LabelSelector)
 	}
-	defer resp.Body.Close()
+	if len(filter.ExcludeOwnerKinds) > 0 {
+		parts = append(parts, "exclude-owners="+strings.Join(filter.ExcludeOwnerKinds, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitList parses a comma-separated flag value.
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
 
-	// Check response
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("backend returned %d", resp.StatusCode)
+// splitLines parses a newline-separated env value, for lists whose items may
+// themselves contain commas.
+func splitLines(value string) []string {
+	var out []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
 	}
+	return out
+}
 
-	return nil
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
 }
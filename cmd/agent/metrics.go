@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kuberoot/internal/k8s"
+	"kuberoot/internal/redact"
+)
+
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// metrics holds the agent's self-observability state and renders it in the
+// Prometheus text exposition format. It is small enough that a client
+// library would be more code than it saves.
+type metrics struct {
+	mu sync.Mutex
+
+	detectionDuration histogram
+	detectionErrors   uint64
+	failuresByType    map[string]int
+	reportLatency     histogram
+	reportsSent       uint64
+	sendErrors        map[string]uint64 // HTTP status, or "network"/"encode"
+	apiRequests       map[[2]string]uint64
+	configReloads     map[string]uint64 // "success" or "error"
+	redactions        map[string]uint64 // by detector
+
+	lastDetection time.Time
+	lastReport    time.Time
+
+	electing bool // leader election enabled
+	leading  bool
+}
+
+type histogram struct {
+	counts []uint64 // per bucket in latencyBuckets, non-cumulative
+	sum    float64
+	total  uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		detectionDuration: histogram{counts: make([]uint64, len(latencyBuckets))},
+		reportLatency:     histogram{counts: make([]uint64, len(latencyBuckets))},
+		failuresByType:    make(map[string]int),
+		sendErrors:        make(map[string]uint64),
+		apiRequests:       make(map[[2]string]uint64),
+		configReloads:     make(map[string]uint64),
+		redactions:        make(map[string]uint64),
+	}
+}
+
+func (h *histogram) observe(seconds float64) {
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.total++
+}
+
+// ObserveDetection records one detection pass. failures is nil when it failed.
+func (m *metrics) ObserveDetection(duration time.Duration, failures []k8s.PodFailure, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.detectionDuration.observe(duration.Seconds())
+	if err != nil {
+		m.detectionErrors++
+		return
+	}
+	m.lastDetection = time.Now()
+	m.failuresByType = make(map[string]int)
+	for _, f := range failures {
+		for _, t := range f.Types {
+			m.failuresByType[t]++
+		}
+	}
+}
+
+// ObserveRequest records one report request. status is the HTTP status code,
+// or 0 when no response was received.
+func (m *metrics) ObserveRequest(duration time.Duration, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reportLatency.observe(duration.Seconds())
+	switch {
+	case status == 0:
+		m.sendErrors["network"]++
+	case status != http.StatusOK:
+		m.sendErrors[strconv.Itoa(status)]++
+	}
+}
+
+// ObserveReportSent records a report whose every chunk was accepted.
+func (m *metrics) ObserveReportSent() {
+	m.mu.Lock()
+	m.reportsSent++
+	m.lastReport = time.Now()
+	m.mu.Unlock()
+}
+
+// ObserveEncodeError records a report that could not be serialized.
+func (m *metrics) ObserveEncodeError() {
+	m.mu.Lock()
+	m.sendErrors["encode"]++
+	m.mu.Unlock()
+}
+
+// ObserveConfigReload records an applied or rejected config change.
+func (m *metrics) ObserveConfigReload(err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.mu.Lock()
+	m.configReloads[result]++
+	m.mu.Unlock()
+}
+
+// ObserveRedactions records the values scrubbed from one detection pass.
+func (m *metrics) ObserveRedactions(summary redact.Summary) {
+	m.mu.Lock()
+	for name, n := range summary.ByDetector {
+		m.redactions[name] += uint64(n)
+	}
+	m.mu.Unlock()
+}
+
+// SetLeading records whether this replica holds the leader Lease.
+func (m *metrics) SetLeading(leading bool) {
+	m.mu.Lock()
+	m.electing = true
+	m.leading = leading
+	m.mu.Unlock()
+}
+
+// Ready reports whether detection and delivery have both succeeded within
+// window. A standby replica is always ready to take over.
+func (m *metrics) Ready(window time.Duration) (bool, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.electing && !m.leading {
+		return true, "standby"
+	}
+
+	now := time.Now()
+	switch {
+	case m.lastDetection.IsZero():
+		return false, "no successful detection yet"
+	case now.Sub(m.lastDetection) > window:
+		return false, fmt.Sprintf("last successful detection %v ago", now.Sub(m.lastDetection).Round(time.Second))
+	case m.lastReport.IsZero():
+		return false, "no report delivered yet"
+	case now.Sub(m.lastReport) > window:
+		return false, fmt.Sprintf("last delivered report %v ago", now.Sub(m.lastReport).Round(time.Second))
+	}
+	return true, "ok"
+}
+
+// WrapTransport counts Kubernetes API requests by method and status code.
+func (m *metrics) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := rt.RoundTrip(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		m.mu.Lock()
+		m.apiRequests[[2]string{req.Method, code}]++
+		m.mu.Unlock()
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// snapshot copies m so it can be rendered without holding the lock.
+func (m *metrics) snapshot() *metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := &metrics{
+		detectionDuration: m.detectionDuration.clone(),
+		detectionErrors:   m.detectionErrors,
+		failuresByType:    make(map[string]int, len(m.failuresByType)),
+		reportLatency:     m.reportLatency.clone(),
+		reportsSent:       m.reportsSent,
+		sendErrors:        make(map[string]uint64, len(m.sendErrors)),
+		apiRequests:       make(map[[2]string]uint64, len(m.apiRequests)),
+		configReloads:     make(map[string]uint64, len(m.configReloads)),
+		redactions:        make(map[string]uint64, len(m.redactions)),
+		lastDetection:     m.lastDetection,
+		lastReport:        m.lastReport,
+		electing:          m.electing,
+		leading:           m.leading,
+	}
+	for k, v := range m.failuresByType {
+		c.failuresByType[k] = v
+	}
+	for k, v := range m.sendErrors {
+		c.sendErrors[k] = v
+	}
+	for k, v := range m.apiRequests {
+		c.apiRequests[k] = v
+	}
+	for k, v := range m.configReloads {
+		c.configReloads[k] = v
+	}
+	for k, v := range m.redactions {
+		c.redactions[k] = v
+	}
+	return c
+}
+
+func (h histogram) clone() histogram {
+	h.counts = append([]uint64(nil), h.counts...)
+	return h
+}
+
+// writeMetrics renders the metrics of every agent. Each family gets one
+// HELP/TYPE header with a sample per cluster, labelled by cluster ID.
+func writeMetrics(w io.Writer, agents []*agent) {
+	type source struct {
+		cluster string
+		m       *metrics
+		queued  int
+		oldest  time.Duration
+	}
+	sources := make([]source, 0, len(agents))
+	for _, a := range agents {
+		queued, oldest := a.outbox.Stats()
+		sources = append(sources, source{cluster: a.cfg().ClusterID, m: a.metrics.snapshot(), queued: queued, oldest: oldest})
+	}
+
+	writeHeader(w, "kuberoot_agent_detection_duration_seconds", "histogram", "Time spent detecting and enriching failures.")
+	for _, src := range sources {
+		writeHistogram(w, "kuberoot_agent_detection_duration_seconds", src.cluster, src.m.detectionDuration)
+	}
+	writeHeader(w, "kuberoot_agent_detection_errors_total", "counter", "Detection passes that failed.")
+	for _, src := range sources {
+		fmt.Fprintf(w, "kuberoot_agent_detection_errors_total%s %d\n", labels(src.cluster), src.m.detectionErrors)
+	}
+
+	writeHeader(w, "kuberoot_agent_failures", "gauge", "Failures found by the last successful detection, by type.")
+	for _, src := range sources {
+		for _, t := range sortedKeys(src.m.failuresByType) {
+			fmt.Fprintf(w, "kuberoot_agent_failures%s %d\n", labels(src.cluster, "type", t), src.m.failuresByType[t])
+		}
+	}
+
+	writeHeader(w, "kuberoot_agent_report_request_duration_seconds", "histogram", "Latency of report requests to the backend.")
+	for _, src := range sources {
+		writeHistogram(w, "kuberoot_agent_report_request_duration_seconds", src.cluster, src.m.reportLatency)
+	}
+	writeHeader(w, "kuberoot_agent_reports_sent_total", "counter", "Reports delivered to the backend.")
+	for _, src := range sources {
+		fmt.Fprintf(w, "kuberoot_agent_reports_sent_total%s %d\n", labels(src.cluster), src.m.reportsSent)
+	}
+
+	writeHeader(w, "kuberoot_agent_report_errors_total", "counter", "Failed report requests, by HTTP status or failure class.")
+	for _, src := range sources {
+		for _, status := range sortedKeys(src.m.sendErrors) {
+			fmt.Fprintf(w, "kuberoot_agent_report_errors_total%s %d\n", labels(src.cluster, "status", status), src.m.sendErrors[status])
+		}
+	}
+
+	writeHeader(w, "kuberoot_agent_kubernetes_requests_total", "counter", "Kubernetes API requests, by method and status code.")
+	for _, src := range sources {
+		keys := make([][2]string, 0, len(src.m.apiRequests))
+		for k := range src.m.apiRequests {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i][0] != keys[j][0] {
+				return keys[i][0] < keys[j][0]
+			}
+			return keys[i][1] < keys[j][1]
+		})
+		for _, k := range keys {
+			fmt.Fprintf(w, "kuberoot_agent_kubernetes_requests_total%s %d\n", labels(src.cluster, "method", k[0], "code", k[1]), src.m.apiRequests[k])
+		}
+	}
+
+	writeHeader(w, "kuberoot_agent_outbox_reports", "gauge", "Reports waiting in the outbox.")
+	for _, src := range sources {
+		fmt.Fprintf(w, "kuberoot_agent_outbox_reports%s %d\n", labels(src.cluster), src.queued)
+	}
+	writeHeader(w, "kuberoot_agent_outbox_oldest_age_seconds", "gauge", "Age of the oldest report waiting in the outbox.")
+	for _, src := range sources {
+		fmt.Fprintf(w, "kuberoot_agent_outbox_oldest_age_seconds%s %g\n", labels(src.cluster), src.oldest.Seconds())
+	}
+
+	writeHeader(w, "kuberoot_agent_config_reloads_total", "counter", "Config file changes applied or rejected, by result.")
+	for _, src := range sources {
+		for _, result := range sortedKeys(src.m.configReloads) {
+			fmt.Fprintf(w, "kuberoot_agent_config_reloads_total%s %d\n", labels(src.cluster, "result", result), src.m.configReloads[result])
+		}
+	}
+
+	writeHeader(w, "kuberoot_agent_redactions_total", "counter", "Values scrubbed from reports, by detector.")
+	for _, src := range sources {
+		for _, detector := range sortedKeys(src.m.redactions) {
+			fmt.Fprintf(w, "kuberoot_agent_redactions_total%s %d\n", labels(src.cluster, "detector", detector), src.m.redactions[detector])
+		}
+	}
+
+	headerDone := false
+	for _, src := range sources {
+		if !src.m.electing {
+			continue
+		}
+		if !headerDone {
+			writeHeader(w, "kuberoot_agent_leader", "gauge", "1 while this replica holds the leader Lease.")
+			headerDone = true
+		}
+		leading := 0
+		if src.m.leading {
+			leading = 1
+		}
+		fmt.Fprintf(w, "kuberoot_agent_leader%s %d\n", labels(src.cluster), leading)
+	}
+
+	writeHeader(w, "kuberoot_agent_last_detection_timestamp_seconds", "gauge", "Unix time of the last successful detection.")
+	for _, src := range sources {
+		fmt.Fprintf(w, "kuberoot_agent_last_detection_timestamp_seconds%s %d\n", labels(src.cluster), unixOrZero(src.m.lastDetection))
+	}
+	writeHeader(w, "kuberoot_agent_last_report_timestamp_seconds", "gauge", "Unix time of the last delivered report.")
+	for _, src := range sources {
+		fmt.Fprintf(w, "kuberoot_agent_last_report_timestamp_seconds%s %d\n", labels(src.cluster), unixOrZero(src.m.lastReport))
+	}
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeHistogram(w io.Writer, name, cluster string, h histogram) {
+	var cumulative uint64
+	for i, bound := range latencyBuckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(cluster, "le", strconv.FormatFloat(bound, 'g', -1, 64)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(cluster, "le", "+Inf"), h.total)
+	fmt.Fprintf(w, "%s_sum%s %g\n", name, labels(cluster), h.sum)
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels(cluster), h.total)
+}
+
+// labels renders a label set starting with cluster; pairs are name, value.
+func labels(cluster string, pairs ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "{cluster=%q", cluster)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fmt.Fprintf(&b, ",%s=%q", pairs[i], pairs[i+1])
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// serveHTTP exposes /healthz, /readyz and /metrics for every agent on addr
+// until ctx is cancelled. With several clusters the process is ready while
+// any of them is, so one unreachable cluster does not take the rest out of
+// service; /readyz lists each cluster's state.
+func serveHTTP(ctx context.Context, addr string, agents []*agent, logger *log.Logger) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		anyReady := false
+		var body strings.Builder
+		for _, a := range agents {
+			// Allow a few missed cycles, but never less than the outbox's early
+			// retries need to get a report through a brief backend blip.
+			config := a.cfg()
+			readyWindow := 3 * config.PollInterval
+			if readyWindow < 2*time.Minute {
+				readyWindow = 2 * time.Minute
+			}
+			ready, reason := a.metrics.Ready(readyWindow)
+			anyReady = anyReady || ready
+			if len(agents) == 1 {
+				body.WriteString(reason + "\n")
+			} else {
+				fmt.Fprintf(&body, "%s: %s\n", config.ClusterID, reason)
+			}
+		}
+		if !anyReady {
+			http.Error(w, strings.TrimSuffix(body.String(), "\n"), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, body.String())
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var buf strings.Builder
+		writeMetrics(&buf, agents)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = io.WriteString(w, buf.String())
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Printf("✓ Serving health and metrics on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		// Detection and delivery do not depend on it, so keep reporting.
+		logger.Printf("❌ Metrics server stopped, reporting continues without it: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	outboxMinBackoff = 1 * time.Second
+	outboxMaxBackoff = 5 * time.Minute
+)
+
+// outbox spools report payloads to disk (an emptyDir or PVC in-cluster) so
+// they survive backend outages and agent restarts. Reports are delivered
+// oldest first and keep their original Timestamp when replayed.
+type outbox struct {
+	dir        string
+	maxEntries int
+	logger     *log.Logger
+
+	mu   sync.Mutex
+	seq  uint64
+	wake chan struct{}
+}
+
+func newOutbox(dir string, maxEntries int, logger *log.Logger) (*outbox, error) {
+	if maxEntries <= 0 {
+		maxEntries = 500
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create outbox dir: %w", err)
+	}
+	return &outbox{
+		dir:        dir,
+		maxEntries: maxEntries,
+		logger:     logger,
+		wake:       make(chan struct{}, 1),
+	}, nil
+}
+
+// Enqueue persists payload and wakes the sender. When the outbox is full the
+// oldest reports are dropped, since newer reports supersede them.
+func (o *outbox) Enqueue(payload AgentPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	o.mu.Lock()
+	o.seq++
+	name := fmt.Sprintf("%020d-%06d.json", time.Now().UnixNano(), o.seq%1000000)
+	tmp := filepath.Join(o.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, body, 0o600); err != nil {
+		o.mu.Unlock()
+		return fmt.Errorf("write outbox entry: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(o.dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		o.mu.Unlock()
+		return fmt.Errorf("commit outbox entry: %w", err)
+	}
+	dropped := o.trimLocked()
+	o.mu.Unlock()
+
+	if dropped > 0 {
+		o.logger.Printf("⚠️ Outbox full, dropped %d oldest report(s)", dropped)
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Stats reports how many reports are waiting and how long the oldest has waited.
+func (o *outbox) Stats() (int, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	names, err := o.entriesLocked()
+	if err != nil || len(names) == 0 {
+		return 0, 0
+	}
+	queuedAt, ok := entryTime(names[0])
+	if !ok {
+		return len(names), 0
+	}
+	return len(names), time.Since(queuedAt)
+}
+
+// Run delivers queued reports until ctx is cancelled, backing off
+// exponentially with jitter while the backend is unreachable. Cancelling ctx
+// also aborts a delivery in flight.
+func (o *outbox) Run(ctx context.Context, send func(context.Context, AgentPayload) error) {
+	backoff := outboxMinBackoff
+	for {
+		err := o.flush(ctx, send)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			backoff = outboxMinBackoff
+			select {
+			case <-ctx.Done():
+				return
+			case <-o.wake:
+			}
+			continue
+		}
+
+		queued, oldest := o.Stats()
+		delay := backoff/2 + rand.N(backoff/2+1)
+		o.logger.Printf("❌ Failed to send report: %v (retry in %v, %d queued, oldest %v)", err, delay.Round(time.Millisecond), queued, oldest.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		backoff *= 2
+		if backoff > outboxMaxBackoff {
+			backoff = outboxMaxBackoff
+		}
+	}
+}
+
+// Drain makes a last delivery attempt once Run (signalled by runDone) has
+// returned, giving up after timeout. Whatever is still queued stays on disk
+// and is sent by the next agent that starts with the same outbox.
+func (o *outbox) Drain(runDone <-chan struct{}, timeout time.Duration, send func(context.Context, AgentPayload) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	select {
+	case <-runDone:
+		if err := o.flush(ctx, send); err != nil && ctx.Err() == nil {
+			o.logger.Printf("⚠️ Final outbox flush failed: %v", err)
+		}
+	case <-ctx.Done():
+	}
+
+	if queued, _ := o.Stats(); queued > 0 {
+		o.logger.Printf("ℹ %d report(s) left in outbox for the next start", queued)
+		return
+	}
+	o.logger.Printf("✓ Outbox drained")
+}
+
+// Discard removes every queued report and returns how many there were.
+func (o *outbox) Discard() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	names, err := o.entriesLocked()
+	if err != nil {
+		return 0
+	}
+	discarded := 0
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(o.dir, name)); err == nil {
+			discarded++
+		}
+	}
+	return discarded
+}
+
+// flush sends queued reports oldest first and stops at the first retryable
+// failure, or once ctx is cancelled, so delivery order is preserved.
+func (o *outbox) flush(ctx context.Context, send func(context.Context, AgentPayload) error) error {
+	o.mu.Lock()
+	names, err := o.entriesLocked()
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		path := filepath.Join(o.dir, name)
+		body, readErr := os.ReadFile(path)
+		if errors.Is(readErr, os.ErrNotExist) {
+			continue // trimmed while we were sending
+		}
+		if readErr != nil {
+			return fmt.Errorf("read outbox entry: %w", readErr)
+		}
+
+		var payload AgentPayload
+		if unmarshalErr := json.Unmarshal(body, &payload); unmarshalErr != nil {
+			o.logger.Printf("⚠️ Dropping corrupt outbox entry %s: %v", name, unmarshalErr)
+			o.remove(path)
+			continue
+		}
+
+		if sendErr := send(ctx, payload); sendErr != nil {
+			if errors.Is(sendErr, errReportRejected) {
+				o.logger.Printf("⚠️ Dropping report from %s: %v", payload.Timestamp.Format(time.RFC3339), sendErr)
+				o.remove(path)
+				continue
+			}
+			return sendErr
+		}
+
+		o.remove(path)
+		o.logger.Printf("✓ Report sent to backend (%s, %d failures, captured %s)", reportModeLabel(payload), len(payload.Failures), payload.Timestamp.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (o *outbox) remove(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		o.logger.Printf("⚠️ Failed to remove outbox entry %s: %v", filepath.Base(path), err)
+	}
+}
+
+func (o *outbox) trimLocked() int {
+	names, err := o.entriesLocked()
+	if err != nil || len(names) <= o.maxEntries {
+		return 0
+	}
+	dropped := 0
+	for _, name := range names[:len(names)-o.maxEntries] {
+		if err := os.Remove(filepath.Join(o.dir, name)); err == nil {
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// entriesLocked lists committed entries oldest first; names sort by enqueue time.
+func (o *outbox) entriesLocked() ([]string, error) {
+	dirEntries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list outbox: %w", err)
+	}
+	names := make([]string, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func entryTime(name string) (time.Time, bool) {
+	prefix, _, ok := strings.Cut(name, "-")
+	if !ok {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// version is stamped at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// agentFeatures lists the protocol features this build supports. It is sent
+// on registration so the backend can tell what each cluster's agent can do.
+var agentFeatures = []string{"chunked-reports", "delta-reports", "gzip", "leader-election", "log-tails", "remote-config", "signed-reports", "watch"}
+
+// Backend routes used alongside reportPath.
+const (
+	registerPath = "/api/v1/agent/register"
+	configPath   = "/api/v1/agent/config"
+)
+
+// errRemoteConfigUnsupported means the backend predates agent registration,
+// so the agent keeps running on local config only.
+var errRemoteConfigUnsupported = errors.New("backend does not serve agent config")
+
+type registerRequest struct {
+	ClusterID     string   `json:"clusterId"`
+	AgentVersion  string   `json:"agentVersion"`
+	Features      []string `json:"features"`
+	ConfigVersion int64    `json:"configVersion,omitempty"`
+}
+
+type registerResponse struct {
+	Features      []string `json:"features"`
+	ConfigVersion int64    `json:"configVersion"`
+}
+
+// remoteConfig is the backend's effective config for this cluster. Config
+// has the same schema as the --config file.
+type remoteConfig struct {
+	ConfigVersion int64           `json:"configVersion"`
+	Config        json.RawMessage `json:"config"`
+}
+
+// newRemoteConfigLoader reads the cluster's config from the backend and
+// layers it over base, which is the flag and local file config. The agent
+// registers before its first read.
+func newRemoteConfigLoader(base AgentConfig, a *agent) *configLoader {
+	registered := false
+	return &configLoader{
+		base:   base,
+		source: "backend",
+		read: func(ctx context.Context) ([]byte, error) {
+			if !registered {
+				if err := a.register(ctx); err != nil {
+					return nil, err
+				}
+				registered = true
+			}
+			query := url.Values{"clusterId": {a.cfg().ClusterID}}
+			return a.backendRequest(ctx, http.MethodGet, configPath, query, nil)
+		},
+		parse: parseRemoteConfig,
+	}
+}
+
+func parseRemoteConfig(data []byte, base AgentConfig) (AgentConfig, error) {
+	var remote remoteConfig
+	if err := json.Unmarshal(data, &remote); err != nil {
+		return AgentConfig{}, fmt.Errorf("decode response: %w", err)
+	}
+	file, err := decodeConfig(remote.Config)
+	if err == nil {
+		err = file.restrictRemote(base)
+	}
+	var config AgentConfig
+	if err == nil {
+		config, err = file.build(base)
+	}
+	if err != nil {
+		return AgentConfig{}, fmt.Errorf("config version %d: %w", remote.ConfigVersion, err)
+	}
+	config.ConfigVersion = remote.ConfigVersion
+	return config, nil
+}
+
+// restrictRemote keeps backend config from loosening what leaves the
+// cluster. The backend URL and the log tail namespaces are local-only, since
+// either could send the API key or pod logs somewhere the cluster owner did
+// not choose. Redaction lists are added to the local ones, so backend config
+// can only scrub more.
+func (f *fileConfig) restrictRemote(base AgentConfig) error {
+	if f.Backend != nil && f.Backend.URL != nil {
+		return errors.New("backend.url can only be set in local config")
+	}
+	if f.Enrichment != nil && f.Enrichment.LogTail != nil && f.Enrichment.LogTail.Namespaces != nil {
+		return errors.New("enrichment.logTail.namespaces can only be set in local config")
+	}
+	if r := f.Redaction; r != nil {
+		if r.Patterns != nil {
+			r.Patterns = mergeLists(base.RedactPatterns, r.Patterns)
+		}
+		if r.DropFields != nil {
+			r.DropFields = mergeLists(base.RedactDropFields, r.DropFields)
+		}
+	}
+	return nil
+}
+
+// mergeLists returns local followed by the entries of extra it lacks.
+func mergeLists(local, extra []string) []string {
+	out := append([]string{}, local...)
+	for _, v := range extra {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// loadRemoteConfig registers and applies the backend config once at
+// startup. An unreachable backend is not fatal: the agent starts on local
+// config and watchConfig keeps trying. It returns nil when the backend does
+// not serve config at all.
+func (a *agent) loadRemoteConfig(remote *configLoader) *configLoader {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*a.cfg().RequestTimeout)
+	defer cancel()
+
+	config, _, err := remote.Load(ctx)
+	switch {
+	case errors.Is(err, errRemoteConfigUnsupported):
+		a.logger.Printf("ℹ Backend does not serve agent config, using local config only")
+		return nil
+	case err != nil:
+		a.logger.Printf("⚠️ Could not load backend config, starting with local config: %v", err)
+		remote.lastErr = err.Error()
+	case config.ConfigVersion != 0:
+		a.config.Store(&config)
+		if a.delta != nil {
+			a.delta.SetSnapshotInterval(config.SnapshotInterval)
+		}
+		a.logger.Printf("✓ Loaded config from backend (version %d)", config.ConfigVersion)
+	}
+	return remote
+}
+
+// register announces the agent's version, features and running config
+// version to the backend.
+func (a *agent) register(ctx context.Context) error {
+	config := a.cfg()
+	body, err := json.Marshal(registerRequest{
+		ClusterID:     config.ClusterID,
+		AgentVersion:  version,
+		Features:      agentFeatures,
+		ConfigVersion: config.ConfigVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("encode registration: %w", err)
+	}
+
+	data, err := a.backendRequest(ctx, http.MethodPost, registerPath, nil, body)
+	if err != nil {
+		return fmt.Errorf("register: %w", err)
+	}
+	var reply registerResponse
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return fmt.Errorf("register: decode response: %w", err)
+	}
+	a.logger.Printf("✓ Registered with backend (agent %s, backend config version %d)", version, reply.ConfigVersion)
+	return nil
+}
+
+// backendRequest sends a small signed request to the backend and returns the
+// response body.
+func (a *agent) backendRequest(ctx context.Context, method, path string, query url.Values, body []byte) ([]byte, error) {
+	config := a.cfg()
+	target := config.BackendURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.RequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("X-API-Key", config.APIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if config.SigningSecret != "" {
+		if err := signRequest(req, config.SigningSecret, path, body); err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	case http.StatusNotFound:
+		return nil, errRemoteConfigUnsupported
+	default:
+		return nil, fmt.Errorf("backend returned %d", resp.StatusCode)
+	}
+}
+
+// requestConfigFetch asks watchConfig to read the backend config now rather
+// than at its next scheduled check.
+func (a *agent) requestConfigFetch() {
+	select {
+	case a.configStale <- struct{}{}:
+	default:
+	}
+}
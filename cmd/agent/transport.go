@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kuberoot/internal/auth"
+)
+
+// reportPath is the backend route for reports. Signatures cover it as the
+// backend routes it, even when BackendURL carries a proxy prefix.
+const reportPath = "/api/v1/agent/report"
+
+// errReportRejected marks responses that will never succeed on retry, so the
+// outbox drops the report instead of blocking everything queued behind it.
+var errReportRejected = errors.New("report rejected by backend")
+
+// sendReport delivers payload, split into sequenced chunks when a single
+// request body would exceed MaxRequestBytes. Chunks share a report ID derived
+// from the payload, so a retried report reuses it and the backend can
+// de-duplicate.
+func (a *agent) sendReport(ctx context.Context, payload AgentPayload) error {
+	config := a.cfg()
+	bodies, err := encodeReport(config, payload)
+	if err != nil {
+		a.metrics.ObserveEncodeError()
+		return fmt.Errorf("%w: encode payload: %v", errReportRejected, err)
+	}
+
+	for i, body := range bodies {
+		if err := a.postReport(ctx, config, body); err != nil {
+			if len(bodies) > 1 {
+				return fmt.Errorf("chunk %d/%d: %w", i+1, len(bodies), err)
+			}
+			return err
+		}
+	}
+	a.metrics.ObserveReportSent()
+	if len(bodies) > 1 {
+		a.logger.Printf("ℹ Report %s sent in %d chunks", reportID(payload), len(bodies))
+	}
+	return nil
+}
+
+// encodeReport returns the request bodies for payload, each within
+// MaxRequestBytes unless a single failure is larger than that on its own.
+// Failures and cleared keys are both spread across the chunks.
+func encodeReport(config AgentConfig, payload AgentPayload) ([][]byte, error) {
+	payload.ReportID = reportID(payload)
+
+	body, err := encodeBody(config, payload)
+	if err != nil {
+		return nil, err
+	}
+	limit := config.MaxRequestBytes
+	items := len(payload.Failures)
+	if len(payload.Cleared) > items {
+		items = len(payload.Cleared)
+	}
+	if limit <= 0 || len(body) <= limit || items <= 1 {
+		return [][]byte{body}, nil
+	}
+
+	parts := len(body)/limit + 1
+	for {
+		if parts > items {
+			parts = items
+		}
+		bodies, fits, err := encodeChunks(config, payload, parts)
+		if err != nil {
+			return nil, err
+		}
+		if fits || parts == items {
+			return bodies, nil
+		}
+		parts *= 2
+	}
+}
+
+func encodeChunks(config AgentConfig, payload AgentPayload, parts int) ([][]byte, bool, error) {
+	bodies := make([][]byte, 0, parts)
+	fits := true
+	for i := 0; i < parts; i++ {
+		chunk := payload
+		lo, hi := chunkBounds(len(payload.Failures), i, parts)
+		chunk.Failures = payload.Failures[lo:hi]
+		lo, hi = chunkBounds(len(payload.Cleared), i, parts)
+		chunk.Cleared = payload.Cleared[lo:hi]
+		chunk.ChunkIndex = i
+		chunk.ChunkCount = parts
+
+		body, err := encodeBody(config, chunk)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(body) > config.MaxRequestBytes {
+			fits = false
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, fits, nil
+}
+
+// chunkBounds is the range of the i-th of parts near-equal slices of n items.
+func chunkBounds(n, i, parts int) (int, int) {
+	return i * n / parts, (i + 1) * n / parts
+}
+
+func encodeBody(config AgentConfig, payload AgentPayload) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Compress {
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (a *agent) postReport(ctx context.Context, config AgentConfig, body []byte) error {
+	// Create request
+	url := config.BackendURL + reportPath
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", config.APIKey)
+	if config.Compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if config.SigningSecret != "" {
+		// Signed per attempt, so an outbox retry is a new request rather
+		// than a replay.
+		if err := signRequest(req, config.SigningSecret, reportPath, body); err != nil {
+			return fmt.Errorf("sign request: %w", err)
+		}
+	}
+
+	// Send request
+	client := &http.Client{
+		Timeout: config.RequestTimeout,
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		a.metrics.ObserveRequest(time.Since(start), 0)
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	a.metrics.ObserveRequest(time.Since(start), resp.StatusCode)
+
+	// Check response
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var reply AgentReportResponse
+		if err := json.NewDecoder(resp.Body).Decode(&reply); err == nil {
+			if reply.ResyncRequired && a.delta != nil {
+				a.logger.Printf("ℹ Backend requested a full snapshot")
+				a.delta.RequestResync()
+			}
+			if reply.ConfigVersion != 0 && reply.ConfigVersion != config.ConfigVersion {
+				a.requestConfigFetch()
+			}
+		}
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: backend returned %d", errReportRejected, resp.StatusCode)
+	default:
+		return fmt.Errorf("backend returned %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// signRequest adds the timestamp, nonce and HMAC headers the backend checks
+// before accepting a request. path is the backend route, without any proxy
+// prefix in BackendURL.
+func signRequest(req *http.Request, secret, path string, body []byte) error {
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return err
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set(auth.TimestampHeader, timestamp)
+	req.Header.Set(auth.NonceHeader, nonce)
+	req.Header.Set(auth.SignatureHeader, auth.SignReport(secret, timestamp, nonce, req.Method, path, body))
+	return nil
+}
+
+// reportID identifies a payload independently of how it is chunked or how
+// many times it is retried.
+func reportID(payload AgentPayload) string {
+	sum := sha256.Sum256([]byte(payload.ClusterID + "|" + payload.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(payload.Sequence, 10)))
+	return hex.EncodeToString(sum[:8])
+}
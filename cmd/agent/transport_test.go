@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"kuberoot/internal/k8s"
+)
+
+func TestChunkBounds(t *testing.T) {
+	tests := []struct {
+		n, parts int
+		want     [][2]int
+	}{
+		{n: 0, parts: 3, want: [][2]int{{0, 0}, {0, 0}, {0, 0}}},
+		{n: 1, parts: 3, want: [][2]int{{0, 0}, {0, 0}, {0, 1}}},
+		{n: 6, parts: 3, want: [][2]int{{0, 2}, {2, 4}, {4, 6}}},
+		{n: 7, parts: 3, want: [][2]int{{0, 2}, {2, 4}, {4, 7}}},
+		{n: 5, parts: 1, want: [][2]int{{0, 5}}},
+	}
+	for _, tt := range tests {
+		var got [][2]int
+		for i := 0; i < tt.parts; i++ {
+			lo, hi := chunkBounds(tt.n, i, tt.parts)
+			got = append(got, [2]int{lo, hi})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chunkBounds(%d, i, %d) = %v, want %v", tt.n, tt.parts, got, tt.want)
+		}
+	}
+}
+
+// TestEncodeReportRoundTrip splits reports the way the backend reassembles
+// them: chunks in index order, failures and cleared keys concatenated.
+func TestEncodeReportRoundTrip(t *testing.T) {
+	failures := make([]k8s.PodFailure, 40)
+	for i := range failures {
+		failures[i] = k8s.PodFailure{
+			Namespace: "shop",
+			Name:      fmt.Sprintf("api-%02d", i),
+			Container: "api",
+			Types:     []string{string(k8s.FailureCrashLoopBackOff)},
+			Message:   strings.Repeat(fmt.Sprintf("%x", sha256.Sum256([]byte{byte(i)})), 3), // resists gzip
+		}
+	}
+	cleared := make([]string, 90)
+	for i := range cleared {
+		cleared[i] = fmt.Sprintf("shop/old-%02d/api", i)
+	}
+	payload := AgentPayload{
+		ClusterID:    "prod",
+		Timestamp:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Mode:         reportModeDelta,
+		Sequence:     8,
+		BaseSequence: 7,
+		Failures:     failures,
+		Cleared:      cleared,
+	}
+
+	tests := []struct {
+		name       string
+		config     AgentConfig
+		payload    AgentPayload
+		wantChunks bool
+	}{
+		{name: "under the limit", config: AgentConfig{MaxRequestBytes: 1 << 20}, payload: payload},
+		{name: "no limit", payload: payload},
+		{name: "split", config: AgentConfig{MaxRequestBytes: 2048}, payload: payload, wantChunks: true},
+		{name: "split compressed", config: AgentConfig{MaxRequestBytes: 1024, Compress: true}, payload: payload, wantChunks: true},
+		{name: "only cleared keys", config: AgentConfig{MaxRequestBytes: 512}, payload: AgentPayload{ClusterID: "prod", Mode: reportModeDelta, Sequence: 9, BaseSequence: 8, Cleared: cleared}, wantChunks: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies, err := encodeReport(tt.config, tt.payload)
+			if err != nil {
+				t.Fatalf("encodeReport: %v", err)
+			}
+			if got := len(bodies) > 1; got != tt.wantChunks {
+				t.Fatalf("got %d bodies, want chunks=%v", len(bodies), tt.wantChunks)
+			}
+
+			var assembled AgentPayload
+			for i, body := range bodies {
+				if tt.config.MaxRequestBytes > 0 && len(body) > tt.config.MaxRequestBytes {
+					t.Errorf("body %d is %d bytes, over the %d limit", i, len(body), tt.config.MaxRequestBytes)
+				}
+				part := decodeBody(t, tt.config, body)
+				if len(bodies) > 1 && (part.ChunkIndex != i || part.ChunkCount != len(bodies)) {
+					t.Errorf("body %d is chunk %d of %d", i, part.ChunkIndex, part.ChunkCount)
+				}
+				if part.ReportID != reportID(tt.payload) || part.Sequence != tt.payload.Sequence {
+					t.Errorf("body %d has report %s sequence %d", i, part.ReportID, part.Sequence)
+				}
+				if i == 0 {
+					assembled = part
+					continue
+				}
+				assembled.Failures = append(assembled.Failures, part.Failures...)
+				assembled.Cleared = append(assembled.Cleared, part.Cleared...)
+			}
+			if !reflect.DeepEqual(assembled.Failures, tt.payload.Failures) {
+				t.Errorf("reassembled %d failures, want %d in order", len(assembled.Failures), len(tt.payload.Failures))
+			}
+			if !reflect.DeepEqual(assembled.Cleared, tt.payload.Cleared) {
+				t.Errorf("reassembled %d cleared keys, want %d in order", len(assembled.Cleared), len(tt.payload.Cleared))
+			}
+		})
+	}
+}
+
+func decodeBody(t *testing.T, config AgentConfig, body []byte) AgentPayload {
+	t.Helper()
+	if config.Compress {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("gunzip: %v", err)
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			t.Fatalf("gunzip: %v", err)
+		}
+	}
+	var payload AgentPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return payload
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"kuberoot/internal/store"
@@ -56,12 +57,18 @@ func main() {
 
 	keyHash := hashAPIKey(apiKey)
 
+	signingSecret, err := generateRandomKey()
+	if err != nil {
+		log.Fatalf("failed to generate signing secret: %v", err)
+	}
+	signingSecret = "krs_" + strings.TrimPrefix(signingSecret, "kr_")
+
 	insertCtx, insertCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer insertCancel()
 
 	// Direct SQL execution since this is a utility
-	query := `INSERT INTO api_keys (organization_id, key_hash, name, active, created_at)
-	          VALUES ($1, $2, $3, true, NOW())`
+	query := `INSERT INTO api_keys (organization_id, key_hash, name, active, signing_secret, created_at)
+	          VALUES ($1, $2, $3, true, $4, NOW())`
 
 	// Access the db connection directly via reflection or add a method
 	// For now, we'll output instructions
@@ -69,16 +76,17 @@ func main() {
 	fmt.Println("Generated API Key:")
 	fmt.Println("========================================")
 	fmt.Printf("Key: %s\n", apiKey)
+	fmt.Printf("Signing Secret: %s\n", signingSecret)
 	fmt.Printf("Organization: %s\n", *orgID)
 	fmt.Printf("Name: %s\n", *name)
 	fmt.Println()
 	fmt.Println("To activate, run this SQL:")
 	fmt.Println("========================================")
-	fmt.Printf("INSERT INTO api_keys (organization_id, key_hash, name, active, created_at)\n")
-	fmt.Printf("VALUES ('%s', '%s', '%s', true, NOW());\n", *orgID, keyHash, *name)
+	fmt.Printf("INSERT INTO api_keys (organization_id, key_hash, name, active, signing_secret, created_at)\n")
+	fmt.Printf("VALUES ('%s', '%s', '%s', true, '%s', NOW());\n", *orgID, keyHash, *name, signingSecret)
 	fmt.Println("========================================")
 	fmt.Println()
-	fmt.Println("IMPORTANT: Save this key now. It cannot be retrieved later.")
+	fmt.Println("IMPORTANT: Save this key and signing secret now. They cannot be retrieved later.")
 	fmt.Println()
 
 	_ = insertCtx
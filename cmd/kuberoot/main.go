@@ -0,0 +1,33 @@
+// Command kuberoot diagnoses failing pods straight from a kubeconfig context,
+// without the agent, the backend or a database.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: kuberoot <command> [flags]
+
+Commands:
+  scan    Diagnose failing pods in the current kubeconfig context
+
+Run "kuberoot <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitError)
+	}
+
+	switch os.Args[1] {
+	case "scan":
+		os.Exit(runScan(os.Args[2:]))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(exitError)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"kuberoot/internal/analyzer"
+	"kuberoot/internal/k8s"
+)
+
+// Exit codes, so CI can tell "issues found" apart from "could not scan".
+const (
+	exitOK     = 0
+	exitIssues = 1
+	exitError  = 2
+)
+
+// localOrgID stands in for the organization a backend would attach.
+const localOrgID = "local"
+
+var severityRank = map[string]int{
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// scanResult is one pass over the cluster, as printed by -o json and -o yaml.
+type scanResult struct {
+	Context   string               `json:"context"`
+	ScannedAt time.Time            `json:"scannedAt"`
+	Diagnoses []analyzer.Diagnosis `json:"diagnoses"`
+}
+
+func runScan(args []string) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kuberoot scan [flags]\n\nExit status is 0 when the scan passes, %d when --fail-on matched an issue and %d on errors.\n\nFlags:\n", exitIssues, exitError)
+		fs.PrintDefaults()
+	}
+	kubeconfig := fs.String("kubeconfig", "", "Path to the kubeconfig file (default $KUBECONFIG or ~/.kube/config)")
+	kubeContext := fs.String("context", "", "Kubeconfig context to scan (default the current context)")
+	var namespaces string
+	fs.StringVar(&namespaces, "namespace", "", "Comma-separated namespace globs to scan; empty scans all")
+	fs.StringVar(&namespaces, "n", "", "Shorthand for --namespace")
+	selector := fs.String("selector", "", "Only scan pods matching this label selector")
+	var output string
+	fs.StringVar(&output, "output", "table", "Output format: table, json or yaml")
+	fs.StringVar(&output, "o", "table", "Shorthand for --output")
+	watch := fs.Bool("watch", false, "Rescan every --interval until interrupted")
+	interval := fs.Duration("interval", 15*time.Second, "Time between scans with --watch")
+	failOn := fs.String("fail-on", "", "Exit non-zero when an issue at or above this severity is found: low, medium, high or critical")
+	logs := fs.Bool("logs", false, "Read container log tails to sharpen diagnoses; needs pods/log access")
+	timeout := fs.Duration("timeout", time.Minute, "Timeout for each scan")
+	kubeQPS := fs.Float64("kube-qps", 20, "Sustained Kubernetes API requests per second")
+	kubeBurst := fs.Int("kube-burst", 40, "Kubernetes API request burst allowed above --kube-qps")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if output != "table" && output != "json" && output != "yaml" {
+		return scanError("--output must be table, json or yaml")
+	}
+	if *failOn != "" && severityRank[*failOn] == 0 {
+		return scanError("--fail-on must be low, medium, high or critical")
+	}
+	if *watch && *interval <= 0 {
+		return scanError("--interval must be positive")
+	}
+
+	var include []string
+	if strings.TrimSpace(namespaces) != "" {
+		include = strings.Split(namespaces, ",")
+	}
+	filter, err := k8s.NewFailureFilter(include, nil, *selector, nil)
+	if err != nil {
+		return scanError("invalid filter: %v", err)
+	}
+	opts := k8s.Options{Filter: filter}
+	if *logs {
+		opts.LogTail = &k8s.LogTailConfig{Lines: 50, MaxBytes: 4096, Namespaces: []string{"*"}}
+	}
+	// Output is often pasted into tickets and CI logs, so it is scrubbed
+	// like an agent report.
+	redaction, err := k8s.NewRedaction(nil, nil)
+	if err != nil {
+		return scanError("build redaction: %v", err)
+	}
+
+	restConfig, contextName, err := k8s.LoadContextConfig(*kubeconfig, *kubeContext)
+	if err != nil {
+		return scanError("%v", err)
+	}
+	restConfig.QPS = float32(*kubeQPS)
+	restConfig.Burst = *kubeBurst
+	cs, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return scanError("create clientset: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	scan := func() (scanResult, error) {
+		scanCtx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		failures, err := k8s.GetFailedPods(scanCtx, cs, opts)
+		if err != nil {
+			return scanResult{}, err
+		}
+		redaction.Apply(failures)
+		diagnoses := analyzer.DiagnoseFailures(localOrgID, contextName, failures)
+		sortDiagnoses(diagnoses)
+		return scanResult{Context: contextName, ScannedAt: time.Now().UTC(), Diagnoses: diagnoses}, nil
+	}
+
+	if !*watch {
+		result, err := scan()
+		if err != nil {
+			return scanError("scan failed: %v", err)
+		}
+		if err := printResult(os.Stdout, output, result); err != nil {
+			return scanError("print result: %v", err)
+		}
+		return exitStatus(result, *failOn)
+	}
+
+	// In watch mode the exit status reflects the last completed scan, so a
+	// CI job can watch until the cluster settles and then be interrupted.
+	status := exitOK
+	for first := true; ; first = false {
+		if !first {
+			select {
+			case <-ctx.Done():
+				return status
+			case <-time.After(*interval):
+			}
+		}
+
+		result, err := scan()
+		if err != nil {
+			if ctx.Err() != nil {
+				return status
+			}
+			fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+			status = exitError
+			continue
+		}
+		if output == "yaml" && !first {
+			fmt.Println("---")
+		}
+		if output == "table" {
+			fmt.Printf("# %s at %s\n", result.Context, result.ScannedAt.Local().Format(time.TimeOnly))
+		}
+		if err := printResult(os.Stdout, output, result); err != nil {
+			return scanError("print result: %v", err)
+		}
+		if output == "table" {
+			fmt.Println()
+		}
+		status = exitStatus(result, *failOn)
+	}
+}
+
+func scanError(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "kuberoot scan: "+format+"\n", args...)
+	return exitError
+}
+
+// exitStatus is exitIssues when any diagnosis is at or above failOn.
+func exitStatus(result scanResult, failOn string) int {
+	if failOn == "" {
+		return exitOK
+	}
+	for _, d := range result.Diagnoses {
+		if severityRank[d.Severity] >= severityRank[failOn] {
+			return exitIssues
+		}
+	}
+	return exitOK
+}
+
+// sortDiagnoses puts the most severe issues first, then groups by pod.
+func sortDiagnoses(diagnoses []analyzer.Diagnosis) {
+	sort.SliceStable(diagnoses, func(i, j int) bool {
+		a, b := diagnoses[i], diagnoses[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] > severityRank[b.Severity]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.PodName != b.PodName {
+			return a.PodName < b.PodName
+		}
+		return a.Container < b.Container
+	})
+}
+
+func printResult(w io.Writer, format string, result scanResult) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case "yaml":
+		out, err := yaml.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return printTable(w, result)
+	}
+}
+
+func printTable(w io.Writer, result scanResult) error {
+	if len(result.Diagnoses) == 0 {
+		_, err := fmt.Fprintf(w, "No failing pods found in context %s.\n", result.Context)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tNAMESPACE\tPOD\tCONTAINER\tFAILURE\tCONFIDENCE\tLIKELY CAUSE")
+	counts := make(map[string]int)
+	for _, d := range result.Diagnoses {
+		counts[d.Severity]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Severity, d.Namespace, d.PodName, d.Container, d.FailureType, d.Confidence, d.LikelyCause)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var parts []string
+	for _, severity := range []string{"critical", "high", "medium", "low"} {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d issues (%s)\n", len(result.Diagnoses), strings.Join(parts, ", "))
+	return err
+}
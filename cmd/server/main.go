@@ -1,10 +1,14 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"kuberoot/internal/api"
@@ -19,13 +23,42 @@ func main() {
 		log.Fatalf("FATAL: DATABASE_URL environment variable is required in SaaS mode")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// SIGTERM (rollouts) and SIGINT stop accepting connections and drain
+	// in-flight requests for up to SHUTDOWN_GRACE_PERIOD.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	gracePeriod := 20 * time.Second
+	if raw := os.Getenv("SHUTDOWN_GRACE_PERIOD"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("FATAL: invalid SHUTDOWN_GRACE_PERIOD %q", raw)
+		}
+		gracePeriod = parsed
+	}
+
+	signatureSkew := 5 * time.Minute
+	if raw := os.Getenv("REPORT_SIGNATURE_SKEW"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("FATAL: invalid REPORT_SIGNATURE_SKEW %q", raw)
+		}
+		signatureSkew = parsed
+	}
+	requireSignedReports := os.Getenv("REQUIRE_SIGNED_REPORTS") == "true"
+
+	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	postgresStore, pgErr := store.NewPostgresStore(ctx, databaseURL)
+	postgresStore, pgErr := store.NewPostgresStore(initCtx, databaseURL)
 	if pgErr != nil {
 		log.Fatalf("FATAL: failed to initialize postgres store: %v", pgErr)
 	}
+	defer func() {
+		if err := postgresStore.Close(); err != nil {
+			log.Printf("[WARN] close postgres store: %v", err)
+		}
+	}()
 
 	clusterID := os.Getenv("KUBEROOT_CLUSTER_ID")
 	if clusterID == "" {
@@ -41,6 +74,9 @@ func main() {
 	mux.HandleFunc("/diagnose/current", handler.DiagnoseCurrent)
 	mux.HandleFunc("/api/current-failures", handler.DiagnoseCurrent)
 	mux.HandleFunc("/api/v1/agent/report", handler.AgentReport)
+	mux.HandleFunc("/api/v1/agent/register", handler.AgentRegister)
+	mux.HandleFunc("/api/v1/agent/config", handler.AgentConfig)
+	mux.HandleFunc("/api/v1/clusters", handler.ListClusters)
 	mux.HandleFunc("/internal/generate-key", handler.GenerateAPIKey)
 	// NOTE: /diagnose removed - not available in SaaS mode (only agent-pushed data)
 
@@ -56,13 +92,23 @@ func main() {
 	// 3. CORS (allow cross-origin requests)
 	httpHandler = corsMiddleware()(httpHandler)
 
-	// 4. Body size limit (1MB max)
+	// 4. Gzip request bodies (16MB max once decompressed)
+	httpHandler = gzipDecompressMiddleware(16 * 1024 * 1024)(httpHandler)
+
+	// 5. Agent report signatures (over the body as sent, so before gzip)
+	httpHandler = auth.SignatureMiddleware(postgresStore, auth.SignatureOptions{
+		Paths:      []string{"/api/v1/agent/report", "/api/v1/agent/register"},
+		Skew:       signatureSkew,
+		RequireAll: requireSignedReports,
+	})(httpHandler)
+
+	// 6. Body size limit (1MB max on the wire)
 	httpHandler = bodySizeLimitMiddleware(1024 * 1024)(httpHandler)
 
-	// 5. Request timeout (10 seconds max)
+	// 7. Request timeout (10 seconds max)
 	httpHandler = timeoutMiddleware(10 * time.Second)(httpHandler)
 
-	// 6. API Key validation (required for all endpoints except /health)
+	// 8. API Key validation (required for all endpoints except /health)
 	httpHandler = auth.APIKeyMiddleware(postgresStore)(httpHandler)
 
 	// PORT from environment (Railway/Heroku sets this)
@@ -80,10 +126,31 @@ func main() {
 		IdleTimeout:  30 * time.Second,
 	}
 
-	log.Printf("🚀 Kuberoot backend starting on %s (SaaS mode, database-backed)", addr)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("server error: %v", err)
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("🚀 Kuberoot backend starting on %s (SaaS mode, database-backed)", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Fatalf("server error: %v", err)
+		}
+	case <-ctx.Done():
+	}
+
+	log.Printf("[SHUTDOWN] signal received, draining requests for up to %v", gracePeriod)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("[SHUTDOWN] drain incomplete: %v", err)
+		return
 	}
+	log.Printf("[SHUTDOWN] all requests drained")
 }
 
 // panicRecoveryMiddleware recovers from panics and returns 500
@@ -124,6 +191,36 @@ func bodySizeLimitMiddleware(maxBytes int64) func(http.Handler) http.Handler {
 	}
 }
 
+// gzipDecompressMiddleware transparently inflates gzip-encoded request bodies,
+// capping the decompressed size so a small body can't expand without bound
+func gzipDecompressMiddleware(maxDecompressed int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+			case "", "identity":
+				next.ServeHTTP(w, r)
+				return
+			case "gzip":
+			default:
+				http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+				return
+			}
+
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "invalid gzip body", http.StatusBadRequest)
+				return
+			}
+			defer zr.Close()
+
+			r.Body = http.MaxBytesReader(w, zr, maxDecompressed)
+			r.Header.Del("Content-Encoding")
+			r.ContentLength = -1
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // corsMiddleware sets CORS headers
 // In production, use CORS_ORIGIN env var to restrict to your frontend domain
 func corsMiddleware() func(http.Handler) http.Handler {
@@ -134,8 +231,8 @@ func corsMiddleware() func(http.Handler) http.Handler {
 				origin = "*" // Allow all in local dev, restrict in production
 			}
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Encoding, X-API-Key")
 			w.Header().Set("Access-Control-Max-Age", "86400")
 
 			if r.Method == http.MethodOptions {
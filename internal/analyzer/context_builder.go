@@ -7,35 +7,51 @@ import (
 )
 
 func buildPodSignal(failureType string, failure k8s.PodFailure) PodSignal {
-	exitCode := failure.ExitCode
+	exitCode, exitReason := failure.ExitCode, failure.TerminatedReason
 	if exitCode == 0 {
-		exitCode = failure.LastExitCode
+		exitCode, exitReason = failure.LastExitCode, failure.LastTerminationReason
 	}
 	return PodSignal{
 		FailureType:  failureType,
 		Namespace:    failure.Namespace,
 		PodName:      failure.Name,
 		Container:    failure.Container,
+		Role:         failure.ContainerRole,
 		Image:        failure.Image,
 		RestartCount: failure.RestartCount,
 		ExitCode:     exitCode,
+		ExitReason:   exitReason,
 		Message:      failure.Message,
 		Events:       failure.Events,
+
+		NodeName:       failure.NodeName,
+		NodeConditions: failure.NodeConditions,
 	}
 }
 
 func buildWorkloadContext(failure k8s.PodFailure) WorkloadContext {
 	ctx := WorkloadContext{
-		Namespace:          failure.Namespace,
-		Deployment:         failure.Deployment,
-		DeploymentRevision: failure.DeploymentRevision,
-		ReplicaStatus:      failure.ReplicaStatus,
-		Image:              failure.Image,
-		ContainerCommand:   failure.ContainerCommand,
-		ConfigMaps:         append([]string{}, failure.ConfigMaps...),
-		Secrets:            append([]string{}, failure.Secrets...),
-		Services:           append([]string{}, failure.Services...),
-		EnvVariables:       append([]string{}, failure.EnvVariables...),
+		Namespace:         failure.Namespace,
+		WorkloadKind:      failure.WorkloadKind,
+		WorkloadName:      failure.WorkloadName,
+		WorkloadRevision:  failure.WorkloadRevision,
+		WorkloadReadiness: failure.WorkloadReadiness,
+		NodeName:          failure.NodeName,
+		Image:             failure.Image,
+		ContainerCommand:  failure.ContainerCommand,
+		ConfigMaps:        append([]string{}, failure.ConfigMaps...),
+		Secrets:           append([]string{}, failure.Secrets...),
+		Services:          append([]string{}, failure.Services...),
+		EnvVariables:      append([]string{}, failure.EnvVariables...),
+	}
+	for _, vol := range failure.Volumes {
+		ctx.Claims = append(ctx.Claims, vol.Claim)
+		if vol.PersistentVolume != "" {
+			ctx.PersistentVolumes = append(ctx.PersistentVolumes, vol.PersistentVolume)
+		}
+		if vol.StorageClass != "" {
+			ctx.StorageClasses = append(ctx.StorageClasses, vol.StorageClass)
+		}
 	}
 	ctx.DependencyGraph = buildDependencyGraph(ctx)
 	return ctx
@@ -44,8 +60,11 @@ func buildWorkloadContext(failure k8s.PodFailure) WorkloadContext {
 func buildDependencyGraph(ctx WorkloadContext) []string {
 	nodes := make([]string, 0, 16)
 
-	if strings.TrimSpace(ctx.Deployment) != "" {
-		nodes = append(nodes, "Deployment "+ctx.Deployment)
+	if strings.TrimSpace(ctx.WorkloadName) != "" {
+		nodes = append(nodes, ctx.WorkloadKind+" "+ctx.WorkloadName)
+	}
+	if strings.TrimSpace(ctx.NodeName) != "" {
+		nodes = append(nodes, "Node "+ctx.NodeName)
 	}
 	for _, cm := range ctx.ConfigMaps {
 		nodes = append(nodes, "ConfigMap "+cm)
@@ -56,6 +75,15 @@ func buildDependencyGraph(ctx WorkloadContext) []string {
 	for _, svc := range ctx.Services {
 		nodes = append(nodes, "Service "+svc)
 	}
+	for _, claim := range ctx.Claims {
+		nodes = append(nodes, "PersistentVolumeClaim "+claim)
+	}
+	for _, pv := range ctx.PersistentVolumes {
+		nodes = append(nodes, "PersistentVolume "+pv)
+	}
+	for _, class := range ctx.StorageClasses {
+		nodes = append(nodes, "StorageClass "+class)
+	}
 	if strings.TrimSpace(ctx.Image) != "" {
 		nodes = append(nodes, "Image "+ctx.Image)
 	}
@@ -25,8 +25,11 @@ func NewDiagnosisEngine(baseRules []Rule) *DiagnosisEngine {
 	return &DiagnosisEngine{
 		ruleMap: ruleMap,
 		validators: []Validator{
+			NodeHealthValidator{},
+			ExitCodeValidator{},
 			ConfigMapValidator{},
 			SecretValidator{},
+			InitDependencyValidator{},
 			ServiceDependencyValidator{},
 		},
 		rules: defaultRuntimeRules(),
@@ -66,7 +69,7 @@ func (e *DiagnosisEngine) composeDiagnosis(orgID, clusterID string, failure k8s.
 	evidence := buildEvidence(effectiveType, failure)
 	ctx := buildContextSignals(failure)
 	ctx = append(ctx, buildDependencyGraph(buildWorkloadContext(failure))...)
-	likelyCause := deriveLikelyCause(rule.LikelyCause, effectiveType, failure, evidence)
+	likelyCause := withContainerRole(effectiveType, failure, deriveLikelyCause(rule.LikelyCause, effectiveType, failure, evidence))
 	fixSuggestions := buildFixSuggestions(effectiveType, failure, evidence)
 	fixSuggestions = sanitizeFixSuggestions(fixSuggestions)
 	suggestedFix := deriveSuggestedFix(rule.SuggestedFix, effectiveType, failure, evidence, fixSuggestions)
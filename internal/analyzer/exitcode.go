@@ -0,0 +1,338 @@
+package analyzer
+
+import (
+	"strings"
+
+	"kuberoot/internal/k8s"
+)
+
+// Exit classes: what an exit code says about how the process ended.
+const (
+	exitCompleted     = "completed"      // 0
+	exitAppError      = "app-error"      // 1 and application-defined codes
+	exitUsage         = "usage"          // 2
+	exitNotExecutable = "not-executable" // 126
+	exitNotFound      = "not-found"      // 127
+	exitSignal        = "signal"         // 128+N
+	exitOutOfRange    = "out-of-range"   // 255
+)
+
+// Senders of the SIGKILL behind an exit code 137.
+const (
+	killOOM      = "oom"
+	killLiveness = "liveness"
+	killStartup  = "startup"
+	killPreStop  = "prestop"
+)
+
+// exitStatus is a decoded container exit code.
+type exitStatus struct {
+	Code    int32
+	Class   string
+	Signal  string // for 128+N, e.g. "SIGSEGV"
+	Meaning string
+}
+
+// exitSignals are the signals containers commonly die of, by number.
+var exitSignals = map[int32]struct{ name, meaning string }{
+	1:  {"SIGHUP", "hangup"},
+	2:  {"SIGINT", "interrupted"},
+	3:  {"SIGQUIT", "quit, usually with a core or thread dump"},
+	4:  {"SIGILL", "illegal instruction: the binary was built for another CPU architecture or instruction set"},
+	6:  {"SIGABRT", "aborted by the process itself, e.g. a failed assertion or a runtime that detected heap corruption"},
+	7:  {"SIGBUS", "bus error: a bad memory access, often a memory-mapped file that shrank or a full /dev/shm"},
+	8:  {"SIGFPE", "arithmetic error such as an integer division by zero"},
+	9:  {"SIGKILL", "killed: by the kernel OOM killer, or by the kubelet after a failed probe or an expired termination grace period"},
+	11: {"SIGSEGV", "segmentation fault: the process accessed invalid memory"},
+	13: {"SIGPIPE", "wrote to a closed pipe or socket"},
+	15: {"SIGTERM", "terminated: the container was asked to stop and the process exited on the signal"},
+}
+
+// decodeExitCode maps an exit code to its conventional meaning. Codes above
+// 128 are the shell's 128+N for a process killed by signal N.
+func decodeExitCode(code int32) exitStatus {
+	status := exitStatus{Code: code, Class: exitAppError, Meaning: "application-defined error"}
+	switch {
+	case code == 0:
+		status.Class, status.Meaning = exitCompleted, "exited successfully"
+	case code == 1:
+		status.Meaning = "general application error"
+	case code == 2:
+		status.Class, status.Meaning = exitUsage, "invalid arguments or shell misuse; Go programs also exit 2 on panic"
+	case code == 126:
+		status.Class, status.Meaning = exitNotExecutable, "command found but not executable"
+	case code == 127:
+		status.Class, status.Meaning = exitNotFound, "command not found"
+	case code == 255:
+		status.Class, status.Meaning = exitOutOfRange, "exit status out of range, usually exit(-1) from the application"
+	case code > 128 && code < 128+65:
+		status.Class = exitSignal
+		if sig, ok := exitSignals[code-128]; ok {
+			status.Signal, status.Meaning = sig.name, sig.meaning
+		} else {
+			status.Signal, status.Meaning = "signal "+itoa32(code-128), "killed by a signal"
+		}
+	}
+	return status
+}
+
+// exitLabel names a code with its signal or meaning, e.g. "137 (SIGKILL)"
+// or "127 (command not found)".
+func exitLabel(code int32) string {
+	status := decodeExitCode(code)
+	switch status.Class {
+	case exitSignal:
+		return itoa32(code) + " (" + status.Signal + ")"
+	case exitAppError:
+		return itoa32(code)
+	}
+	return itoa32(code) + " (" + status.Meaning + ")"
+}
+
+// containerExit is the exit code and termination reason that ended the
+// container: the current termination's, else the last one's.
+func containerExit(failure k8s.PodFailure) (int32, string) {
+	if failure.ExitCode != 0 || failure.TerminatedReason != "" {
+		return failure.ExitCode, failure.TerminatedReason
+	}
+	return failure.LastExitCode, failure.LastTerminationReason
+}
+
+// sigkillSource tells what sent the SIGKILL behind an exit code 137, from
+// the termination reason and the pod's events, or "" when nothing says.
+// A probe or preStop kill outranks an OOM mentioned in passing.
+func sigkillSource(reason string, events []string) string {
+	if reason == "OOMKilled" {
+		return killOOM
+	}
+	source := ""
+	for _, event := range events {
+		lower := strings.ToLower(event)
+		switch {
+		case strings.Contains(lower, "liveness probe failed") || strings.Contains(lower, "failed liveness probe"):
+			return killLiveness
+		case strings.Contains(lower, "startup probe failed") || strings.Contains(lower, "failed startup probe"):
+			source = killStartup
+		case source != killStartup && (strings.Contains(lower, "prestophook") || strings.Contains(lower, "prestop hook")):
+			source = killPreStop
+		case source == "" && (strings.Contains(lower, "oomkilled") || strings.Contains(lower, "out of memory")):
+			source = killOOM
+		}
+	}
+	return source
+}
+
+// exitEvidence decodes the container's exit code for the evidence list.
+func exitEvidence(failure k8s.PodFailure) []string {
+	code, reason := containerExit(failure)
+	status := decodeExitCode(code)
+	if code == 0 && reason != "Completed" {
+		return nil
+	}
+	label := itoa32(code)
+	if status.Signal != "" {
+		label += " (" + status.Signal + ")"
+	}
+	evidence := []string{"Exit code meaning: " + label + " — " + status.Meaning}
+	if code == 137 {
+		if source := sigkillSource(reason, failure.Events); source != "" {
+			evidence = append(evidence, "SIGKILL sent by: "+killSourceLabel(source))
+		}
+	}
+	return evidence
+}
+
+func killSourceLabel(source string) string {
+	switch source {
+	case killOOM:
+		return "kernel OOM killer"
+	case killLiveness:
+		return "kubelet after failed liveness probes"
+	case killStartup:
+		return "kubelet after failed startup probes"
+	case killPreStop:
+		return "kubelet after the termination grace period (preStop hook)"
+	}
+	return "unknown"
+}
+
+// exitCause explains how the container ended from its exit code, or returns
+// "" when the code says no more than that the application failed.
+func exitCause(failureType string, failure k8s.PodFailure) string {
+	code, reason := containerExit(failure)
+	status := decodeExitCode(code)
+	container := "Container " + defaultValue(failure.Container, "of the pod")
+
+	switch status.Class {
+	case exitCompleted:
+		if reason == "Completed" && failureType == "CrashLoopBackOff" {
+			return container + " exits successfully (code 0), but restartPolicy: Always restarts it; its main process returns instead of staying in the foreground"
+		}
+	case exitNotFound:
+		return container + "'s command was not found in the image (exit code 127)" + commandSuffix(failure)
+	case exitNotExecutable:
+		return container + "'s command was found but is not executable (exit code 126): missing execute permission or a binary for another platform" + commandSuffix(failure)
+	case exitUsage:
+		return container + " exited with code 2: " + status.Meaning
+	case exitOutOfRange:
+		return container + " exited with code 255: " + status.Meaning
+	case exitSignal:
+		switch code {
+		case 137:
+			return sigkillCause(failure, sigkillSource(reason, failure.Events))
+		case 143:
+			if sigkillSource(reason, failure.Events) == killLiveness {
+				return container + " was stopped with SIGTERM (exit code 143) after failed liveness probes"
+			}
+			return container + " exited on SIGTERM (exit code 143): something stopped it, such as a liveness probe restart, a node drain or a rollout"
+		case 139:
+			return container + " crashed with a segmentation fault (exit code 139 = SIGSEGV): the process accessed invalid memory, often a native library built for another libc or base image"
+		}
+		return container + " was killed by " + status.Signal + " (exit code " + itoa32(code) + "): " + status.Meaning
+	}
+	return ""
+}
+
+func sigkillCause(failure k8s.PodFailure, source string) string {
+	name := defaultValue(failure.Container, "of the pod")
+	switch source {
+	case killOOM:
+		if failure.MemoryLimit != "" {
+			return "Container " + name + " was killed by the kernel OOM killer (exit code 137 = SIGKILL) at its memory limit of " + failure.MemoryLimit
+		}
+		return "Container " + name + " was killed by the kernel OOM killer (exit code 137 = SIGKILL)"
+	case killLiveness:
+		return "The kubelet killed container " + name + " after failed liveness probes (exit code 137 = SIGKILL); it did not run out of memory"
+	case killStartup:
+		return "The kubelet killed container " + name + " because its startup probe did not pass in time (exit code 137 = SIGKILL); it did not run out of memory"
+	case killPreStop:
+		return "Container " + name + " did not stop within its termination grace period and was killed (exit code 137 = SIGKILL); the preStop hook failed or ran too long"
+	}
+	if failure.MemoryLimit != "" {
+		return "Container " + name + " was killed with SIGKILL (exit code 137), most likely by the OOM killer near its memory limit of " + failure.MemoryLimit + ", though no OOMKilled reason was reported"
+	}
+	return "Container " + name + " was killed with SIGKILL (exit code 137) without an OOMKilled reason; the OOM killer or a kubelet probe kill are the usual senders"
+}
+
+func commandSuffix(failure k8s.PodFailure) string {
+	if failure.ContainerCommand == "" {
+		return ""
+	}
+	return ": " + failure.ContainerCommand
+}
+
+// exitFixes suggests fixes for what the exit code says, or nil when it says
+// only that the application failed.
+func exitFixes(failureType string, failure k8s.PodFailure) []FixSuggestion {
+	code, reason := containerExit(failure)
+	status := decodeExitCode(code)
+	ns := failure.Namespace
+	container := defaultValue(failure.Container, "<container-name>")
+	image := defaultValue(failure.Image, "<image>")
+
+	switch {
+	case status.Class == exitCompleted && reason == "Completed" && failureType == "CrashLoopBackOff":
+		return []FixSuggestion{
+			{
+				Title:       "Keep the main process in the foreground",
+				Explanation: "A container that exits 0 under restartPolicy: Always is restarted forever; run the server without daemon or background mode.",
+				Command:     containerQuery(failure, "command"),
+			},
+			{
+				Title:       "Run one-off work as a Job",
+				Explanation: "If " + container + " is meant to finish, a Job runs it to completion instead of restarting it.",
+				Command:     "kubectl -n " + ns + " create job " + container + "-run --image=" + image,
+			},
+		}
+	case status.Class == exitNotFound || status.Class == exitNotExecutable:
+		fixes := []FixSuggestion{{
+			Title:       "Check the command of " + container,
+			Explanation: "The command overrides the image's ENTRYPOINT and must name a file the image contains.",
+			Command:     containerQuery(failure, "command"),
+		}}
+		if status.Class == exitNotExecutable {
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Make the entrypoint executable",
+				Explanation: "Set the execute bit in the image, and build it for the node's architecture.",
+				Command:     "RUN chmod +x <entrypoint>",
+			})
+		}
+		return fixes
+	case status.Class == exitUsage:
+		return []FixSuggestion{{
+			Title:       "Check the arguments of " + container,
+			Explanation: "Exit code 2 usually means the process rejected its flags; compare args with the version of the image.",
+			Command:     containerQuery(failure, "args"),
+		}}
+	case code == 137:
+		return sigkillFixes(failure, sigkillSource(reason, failure.Events))
+	case code == 132:
+		return []FixSuggestion{{
+			Title:       "Match the image to the node's architecture",
+			Explanation: "SIGILL from a freshly started process usually means the binary targets another CPU architecture or instruction set.",
+			Command:     "docker manifest inspect " + image + "\nkubectl get node " + defaultValue(failure.NodeName, "<node-name>") + " -o jsonpath='{.status.nodeInfo.architecture}'",
+		}}
+	case code == 135:
+		return []FixSuggestion{{
+			Title:       "Give " + container + " a larger /dev/shm",
+			Explanation: "The default 64Mi /dev/shm is a common cause of SIGBUS in databases and browsers.",
+			Command:     "volumes:\n  - name: dshm\n    emptyDir:\n      medium: Memory\n      sizeLimit: 1Gi\n# mounted at /dev/shm in " + container,
+		}}
+	case code == 134 || code == 139:
+		return []FixSuggestion{{
+			Title:       "Read the crash output",
+			Explanation: status.Signal + " is raised by native code; the last log lines usually name the failing library or assertion.",
+			Command:     "kubectl -n " + ns + " logs " + failure.Name + " -c " + container + " --previous",
+		}}
+	case code == 143:
+		return []FixSuggestion{{
+			Title:       "Find what stopped " + container,
+			Explanation: "SIGTERM comes from the kubelet: a liveness restart, an eviction, a node drain or a rollout all show up in the events.",
+			Command:     "kubectl -n " + ns + " get events --field-selector involvedObject.name=" + failure.Name + " --sort-by=.lastTimestamp",
+		}}
+	}
+	return nil
+}
+
+func sigkillFixes(failure k8s.PodFailure, source string) []FixSuggestion {
+	container := defaultValue(failure.Container, "<container-name>")
+	switch source {
+	case killLiveness, killStartup:
+		probe := "livenessProbe"
+		if source == killStartup {
+			probe = "startupProbe"
+		}
+		return []FixSuggestion{
+			{
+				Title:       "Relax the " + probe + " of " + container,
+				Explanation: "The kubelet kills the container when the probe fails failureThreshold times in a row; allow slow responses before restarting it.",
+				Command:     probe + ":\n  timeoutSeconds: 5\n  periodSeconds: 10\n  failureThreshold: 6",
+			},
+			{
+				Title:       "Check the probe against the container",
+				Explanation: "Confirm the probe's path and port match what " + container + " serves.",
+				Command:     containerQuery(failure, probe),
+			},
+		}
+	case killPreStop:
+		return []FixSuggestion{
+			{
+				Title:       "Check the preStop hook of " + container,
+				Explanation: "The hook and the process's SIGTERM handling must finish within terminationGracePeriodSeconds.",
+				Command:     containerQuery(failure, "lifecycle"),
+			},
+			{
+				Title:       "Allow more time to shut down",
+				Explanation: "Raise the grace period if a clean shutdown genuinely takes longer.",
+				Command:     "spec:\n  terminationGracePeriodSeconds: 60",
+			},
+		}
+	case killOOM:
+		return nil
+	}
+	return []FixSuggestion{{
+		Title:       "Find what killed " + container,
+		Explanation: "A SIGKILL without an OOMKilled reason comes from the OOM killer on older runtimes or from a kubelet probe kill; the events say which.",
+		Command:     "kubectl -n " + failure.Namespace + " get events --field-selector involvedObject.name=" + failure.Name + " --sort-by=.lastTimestamp",
+	}}
+}
@@ -0,0 +1,316 @@
+package analyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kuberoot/internal/k8s"
+)
+
+// Probe misconfigurations read from the probe spec, the container's ports
+// and its observed startup time.
+const (
+	probeUndeclaredPort  = "undeclared-port"    // the probe targets a port the container does not declare
+	probeKillsStartup    = "kills-before-start" // liveness gives up before the container has started
+	probeNoStartupProbe  = "no-startup-probe"   // a slow starter is guarded by liveness alone
+	probeMissingExecFile = "exec-missing"       // the exec probe's command is not in the image
+)
+
+// slowStartSeconds is the startup time past which a container with a
+// liveness probe should also have a startup probe.
+const slowStartSeconds = 30
+
+// probeExecMissingRE finds the command an exec probe could not run, e.g.
+// `exec: "curl": executable file not found in $PATH` or `sh: curl: not found`.
+var probeExecMissingRE = regexp.MustCompile(`exec: "([^"]+)": (?:executable file not found|stat [^:]+: no such file)|(?:^|\s)(?:sh|bash): (?:line \d+: )?([\w./-]+): (?:command )?not found`)
+
+type probeIssue struct {
+	Probe  string // liveness, readiness or startup
+	Kind   string
+	Detail string
+}
+
+// probeKind is the probe a probe failure type is about, or "".
+func probeKind(failureType string) string {
+	switch failureType {
+	case "LivenessProbeFailed":
+		return k8s.ProbeLiveness
+	case "ReadinessProbeFailed":
+		return k8s.ProbeReadiness
+	case "StartupProbeFailed":
+		return k8s.ProbeStartup
+	}
+	return ""
+}
+
+func findProbe(failure k8s.PodFailure, kind string) (k8s.ProbeSpec, bool) {
+	for _, p := range failure.Probes {
+		if p.Kind == kind {
+			return p, true
+		}
+	}
+	return k8s.ProbeSpec{}, false
+}
+
+// probeAllowance is roughly how long a probe tolerates failures after the
+// container starts before it acts: initialDelaySeconds plus failureThreshold
+// periods.
+func probeAllowance(p k8s.ProbeSpec) int64 {
+	return int64(p.InitialDelaySeconds) + int64(p.PeriodSeconds)*int64(p.FailureThreshold)
+}
+
+// probeIssues checks the container's probes against its ports, its startup
+// time and the kubelet's probe events.
+func probeIssues(failure k8s.PodFailure) []probeIssue {
+	var issues []probeIssue
+	container := defaultValue(failure.Container, "of the pod")
+
+	for _, p := range failure.Probes {
+		if p.Port == "" || (len(failure.ContainerPorts) == 0 && isNumeric(p.Port)) {
+			continue
+		}
+		if !declaresPort(failure.ContainerPorts, p.Port) {
+			detail := "the " + p.Kind + " probe targets port " + p.Port + ", but container " + container + " declares only " + portList(failure.ContainerPorts)
+			if !isNumeric(p.Port) {
+				detail = "the " + p.Kind + " probe targets the named port " + p.Port + ", which container " + container + " does not declare"
+			}
+			issues = append(issues, probeIssue{Probe: p.Kind, Kind: probeUndeclaredPort, Detail: detail})
+		}
+	}
+
+	for _, p := range failure.Probes {
+		if p.Handler != "exec" {
+			continue
+		}
+		if command := missingProbeCommand(p.Kind, failure.Events); command != "" {
+			issues = append(issues, probeIssue{Probe: p.Kind, Kind: probeMissingExecFile,
+				Detail: "the " + p.Kind + " probe runs " + command + ", which is not in the image of container " + container})
+		}
+	}
+
+	if liveness, ok := findProbe(failure, k8s.ProbeLiveness); ok {
+		_, hasStartup := findProbe(failure, k8s.ProbeStartup)
+		allowance := probeAllowance(liveness)
+		switch {
+		case hasStartup:
+		case failure.StartupSeconds > allowance:
+			issues = append(issues, probeIssue{Probe: k8s.ProbeLiveness, Kind: probeKillsStartup,
+				Detail: "the liveness probe allows " + seconds(allowance) + " after start, but container " + container + " took " + seconds(failure.StartupSeconds) + " to become ready"})
+		case failure.StartupSeconds == 0 && failure.LastRunSeconds > 0 && failure.LastRunSeconds <= allowance+int64(liveness.PeriodSeconds) && livenessKilled(failure):
+			issues = append(issues, probeIssue{Probe: k8s.ProbeLiveness, Kind: probeKillsStartup,
+				Detail: "container " + container + " was killed by its liveness probe after " + seconds(failure.LastRunSeconds) + ", within the probe's " + seconds(allowance) + " allowance, before it ever became ready"})
+		case failure.StartupSeconds >= slowStartSeconds:
+			issues = append(issues, probeIssue{Probe: k8s.ProbeLiveness, Kind: probeNoStartupProbe,
+				Detail: "container " + container + " takes " + seconds(failure.StartupSeconds) + " to start and has no startup probe; only the liveness probe's " + seconds(allowance) + " allowance covers its startup"})
+		}
+	}
+	return issues
+}
+
+// probeIssuesFor are the issues with the probe a failure type is about.
+func probeIssuesFor(failureType string, failure k8s.PodFailure) []probeIssue {
+	kind := probeKind(failureType)
+	if kind == "" {
+		return nil
+	}
+	var out []probeIssue
+	for _, issue := range probeIssues(failure) {
+		if issue.Probe == kind {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+func livenessKilled(failure k8s.PodFailure) bool {
+	_, reason := containerExit(failure)
+	return sigkillSource(reason, failure.Events) == killLiveness
+}
+
+// missingProbeCommand is the command a probe of the given kind failed to
+// run, from the kubelet's "<Kind> probe failed" events.
+func missingProbeCommand(kind string, events []string) string {
+	prefix := kind + " probe failed"
+	for _, event := range events {
+		if !strings.Contains(strings.ToLower(event), prefix) {
+			continue
+		}
+		if m := probeExecMissingRE.FindStringSubmatch(event); m != nil {
+			return m[1] + m[2]
+		}
+	}
+	return ""
+}
+
+func declaresPort(ports []k8s.ContainerPort, port string) bool {
+	for _, p := range ports {
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func portList(ports []k8s.ContainerPort) string {
+	if len(ports) == 0 {
+		return "no ports"
+	}
+	labels := make([]string, 0, len(ports))
+	for _, p := range ports {
+		label := itoa32(p.Port)
+		if p.Name != "" {
+			label += " (" + p.Name + ")"
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, ", ")
+}
+
+func seconds(n int64) string {
+	return strconv.FormatInt(n, 10) + "s"
+}
+
+// probeLabel describes a probe the way it reads in the pod spec, e.g.
+// "httpGet :8080/healthz (delay 0s, period 10s, timeout 1s, failureThreshold 3)".
+func probeLabel(p k8s.ProbeSpec) string {
+	target := p.Handler
+	switch p.Handler {
+	case "httpGet":
+		target += " :" + p.Port + p.Path
+	case "tcpSocket", "grpc":
+		target += " :" + p.Port
+	case "exec":
+		target += " " + p.Command
+	}
+	return target + " (delay " + itoa32(p.InitialDelaySeconds) + "s, period " + itoa32(p.PeriodSeconds) + "s, timeout " + itoa32(p.TimeoutSeconds) + "s, failureThreshold " + itoa32(p.FailureThreshold) + ")"
+}
+
+// probeEvidence lists the container's probes, ports and startup timing, and
+// any misconfigurations found in them, for probe failures.
+func probeEvidence(failureType string, failure k8s.PodFailure) []string {
+	if probeKind(failureType) == "" {
+		return nil
+	}
+	var evidence []string
+	for _, p := range failure.Probes {
+		evidence = append(evidence, strings.ToUpper(p.Kind[:1])+p.Kind[1:]+" probe: "+probeLabel(p))
+	}
+	if len(failure.ContainerPorts) > 0 {
+		evidence = append(evidence, "Container ports: "+portList(failure.ContainerPorts))
+	}
+	if failure.StartupSeconds > 0 {
+		evidence = append(evidence, "Observed startup time: "+seconds(failure.StartupSeconds))
+	}
+	if failure.LastRunSeconds > 0 {
+		evidence = append(evidence, "Last instance ran for: "+seconds(failure.LastRunSeconds))
+	}
+	for _, issue := range probeIssues(failure) {
+		evidence = append(evidence, "Probe misconfiguration: "+issue.Detail)
+	}
+	return evidence
+}
+
+// probeCause explains a probe failure from the first misconfiguration of its
+// probe, or returns "" when none was found.
+func probeCause(failureType string, failure k8s.PodFailure) string {
+	issues := probeIssuesFor(failureType, failure)
+	if len(issues) == 0 {
+		return ""
+	}
+	issue := issues[0]
+	detail := strings.ToUpper(issue.Detail[:1]) + issue.Detail[1:]
+	switch issue.Kind {
+	case probeUndeclaredPort:
+		return detail + " — the probe is most likely pointed at the wrong port"
+	case probeKillsStartup:
+		return detail + " — liveness checks start before the application is up"
+	case probeNoStartupProbe:
+		return detail + " — any slower start gets it killed"
+	case probeMissingExecFile:
+		return detail + " — the probe fails however healthy the application is"
+	}
+	return detail
+}
+
+// probeFixes suggests fixes for the misconfigurations of the failing probe.
+func probeFixes(failureType string, failure k8s.PodFailure) []FixSuggestion {
+	var fixes []FixSuggestion
+	container := defaultValue(failure.Container, "<container-name>")
+	execMissing := false
+	for _, issue := range probeIssuesFor(failureType, failure) {
+		field := issue.Probe + "Probe"
+		p, _ := findProbe(failure, issue.Probe)
+		switch issue.Kind {
+		case probeUndeclaredPort:
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Point the " + field + " at a port " + container + " serves",
+				Explanation: "Probe port " + p.Port + " is not among the container's ports (" + portList(failure.ContainerPorts) + ").",
+				Command:     field + ":\n  " + p.Handler + ":\n    port: " + declaredProbePort(failure, p),
+			})
+		case probeKillsStartup, probeNoStartupProbe:
+			p.Port = declaredProbePort(failure, p)
+			if execMissing {
+				p.Handler = "" // the snippet should not copy a command the image lacks
+			}
+			startup := maxInt64(failure.StartupSeconds, failure.LastRunSeconds)
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Add a startupProbe to " + container,
+				Explanation: "Liveness checks only begin once the startup probe passes, so the slow start is no longer mistaken for a hang.",
+				Command:     startupProbeSnippet(p, startup),
+			})
+		case probeMissingExecFile:
+			execMissing = true
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Probe " + container + " without the missing command",
+				Explanation: "Use an httpGet or tcpSocket probe, or a command the image ships; slim images often lack curl and wget.",
+				Command:     containerQuery(failure, field),
+			})
+		}
+	}
+	return fixes
+}
+
+// declaredProbePort is the probe's port if the container declares it, else
+// the container's first declared port, preferring its name.
+func declaredProbePort(failure k8s.PodFailure, p k8s.ProbeSpec) string {
+	if len(failure.ContainerPorts) == 0 || declaresPort(failure.ContainerPorts, p.Port) {
+		return defaultValue(p.Port, "<port>")
+	}
+	first := failure.ContainerPorts[0]
+	return defaultValue(first.Name, itoa32(first.Port))
+}
+
+// startupProbeSnippet copies the liveness probe's handler into a startup
+// probe that allows about twice the observed startup time.
+func startupProbeSnippet(liveness k8s.ProbeSpec, startup int64) string {
+	period := int64(10)
+	threshold := int64(30)
+	if startup > 0 {
+		threshold = maxInt64((2*startup+period-1)/period, 3)
+	}
+	handler := "  " + liveness.Handler + ":\n"
+	switch liveness.Handler {
+	case "httpGet":
+		handler += "    path: " + defaultValue(liveness.Path, "/") + "\n    port: " + liveness.Port + "\n"
+	case "tcpSocket", "grpc":
+		handler += "    port: " + liveness.Port + "\n"
+	case "exec":
+		handler += "    command: [" + strings.Join(strings.Fields(liveness.Command), ", ") + "]\n"
+	default:
+		handler = "  httpGet:\n    path: /healthz\n    port: <port>\n"
+	}
+	return "startupProbe:\n" + handler + "  periodSeconds: " + strconv.FormatInt(period, 10) + "\n  failureThreshold: " + strconv.FormatInt(threshold, 10)
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
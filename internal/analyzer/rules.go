@@ -1,9 +1,13 @@
 package analyzer
 
 import (
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+
 	"kuberoot/internal/k8s"
 )
 
@@ -80,16 +84,22 @@ var v1Rules = []Rule{
 		SuggestedFix: "Validate liveness endpoint and tune probe configuration",
 		Confidence:   "high",
 	},
+	{
+		FailureType:  "StartupProbeFailed",
+		LikelyCause:  "Startup probe never passed — the kubelet restarts the container before it starts serving",
+		SuggestedFix: "Give the startup probe enough time for the application to start, or fix its endpoint",
+		Confidence:   "high",
+	},
 	{
 		FailureType:  "ConfigMapMissing",
 		LikelyCause:  "Pod references a ConfigMap that does not exist",
-		SuggestedFix: "Create the missing ConfigMap or fix the reference in the Deployment",
+		SuggestedFix: "Create the missing ConfigMap or fix the reference in the workload spec",
 		Confidence:   "high",
 	},
 	{
 		FailureType:  "SecretMissing",
 		LikelyCause:  "Pod references a Secret that does not exist",
-		SuggestedFix: "Create the missing Secret or fix the reference in the Deployment",
+		SuggestedFix: "Create the missing Secret or fix the reference in the workload spec",
 		Confidence:   "high",
 	},
 	{
@@ -122,6 +132,132 @@ var v1Rules = []Rule{
 		SuggestedFix: "Inspect deployment rollout status, recent spec changes, and failing pod diagnostics",
 		Confidence:   "high",
 	},
+	{
+		FailureType:  "NonZeroExit",
+		LikelyCause:  "Container exited with a non-zero code and its pod does not restart",
+		SuggestedFix: "Inspect the container logs for the error that ended the run",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "JobBackoffLimitExceeded",
+		LikelyCause:  "Job pods kept failing until the Job's backoffLimit was reached",
+		SuggestedFix: "Fix the error shown in the failed pod logs, then re-run the Job",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "JobDeadlineExceeded",
+		LikelyCause:  "Job ran longer than its activeDeadlineSeconds and was terminated",
+		SuggestedFix: "Find what slowed the run down, or raise activeDeadlineSeconds",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "CronJobMissedSchedule",
+		LikelyCause:  "CronJob did not start a scheduled run",
+		SuggestedFix: "Check startingDeadlineSeconds and the CronJob controller, then trigger the run manually",
+		Confidence:   "medium",
+	},
+	{
+		FailureType:  "CronJobForbidStalled",
+		LikelyCause:  "A long-running Job is blocking new runs under concurrencyPolicy: Forbid",
+		SuggestedFix: "Stop the stuck Job and bound run time with activeDeadlineSeconds",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "NodeNotReady",
+		LikelyCause:  "Node is NotReady; its kubelet is down or cannot reach the control plane",
+		SuggestedFix: "Check the kubelet on the node, then cordon and drain it if it does not recover",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "NodeDiskPressure",
+		LikelyCause:  "Node is low on disk space and the kubelet is reclaiming it by evicting pods and removing images",
+		SuggestedFix: "Free disk space on the node and find pods writing heavily to ephemeral storage",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "NodeMemoryPressure",
+		LikelyCause:  "Node is low on memory and the kubelet is evicting pods to reclaim it",
+		SuggestedFix: "Find pods using memory without limits on the node and set requests and limits",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "NodePIDPressure",
+		LikelyCause:  "Node is running out of process IDs, so new processes and containers fail to start",
+		SuggestedFix: "Find the pod leaking processes on the node and set a pod PID limit",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "PVCUnbound",
+		LikelyCause:  "A PersistentVolumeClaim the pod mounts is not bound to a volume",
+		SuggestedFix: "Check the claim's StorageClass and provisioner events",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "FailedAttachVolume",
+		LikelyCause:  "The volume could not be attached to the pod's node",
+		SuggestedFix: "Check the VolumeAttachment and the CSI controller logs",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "VolumeMultiAttach",
+		LikelyCause:  "A ReadWriteOnce volume is still attached to another node",
+		SuggestedFix: "Remove the pod holding the volume on the other node, or use a Recreate update strategy",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "VolumeMountFailed",
+		LikelyCause:  "The volume driver timed out mounting the volume on the node",
+		SuggestedFix: "Check the CSI node plugin on the pod's node",
+		Confidence:   "medium",
+	},
+	{
+		FailureType:  "VolumeZoneMismatch",
+		LikelyCause:  "The pod's volume is pinned to a zone with no node that can run the pod",
+		SuggestedFix: "Add capacity in the volume's zone or recreate the volume with WaitForFirstConsumer binding",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "Evicted",
+		LikelyCause:  "The kubelet evicted the pod to reclaim a node resource",
+		SuggestedFix: "Set requests and limits for the evicted resource, including ephemeral-storage",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "CreateContainerConfigError",
+		LikelyCause:  "A ConfigMap, Secret or key the container's environment references does not exist",
+		SuggestedFix: "Create the referenced object or key, or mark the reference optional",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "CreateContainerError",
+		LikelyCause:  "The container runtime could not create the container",
+		SuggestedFix: "Check the runtime error in the pod events and the container runtime on the node",
+		Confidence:   "medium",
+	},
+	{
+		FailureType:  "InvalidImageName",
+		LikelyCause:  "The container's image reference cannot be parsed",
+		SuggestedFix: "Fix the image field: lowercase repository, no URL scheme, tag after a single colon",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "RunContainerError",
+		LikelyCause:  "The container was created but its process could not be started",
+		SuggestedFix: "Check the container's command, entrypoint and volume mounts against the image",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "ErrImageNeverPull",
+		LikelyCause:  "The image is not on the node and imagePullPolicy Never forbids pulling it",
+		SuggestedFix: "Load the image onto the node or change imagePullPolicy to IfNotPresent",
+		Confidence:   "high",
+	},
+	{
+		FailureType:  "InitContainerBlocked",
+		LikelyCause:  "An init container has not completed, so the pod's app containers never start",
+		SuggestedFix: "Check what the init container waits for and whether that dependency is reachable",
+		Confidence:   "medium",
+	},
 }
 
 func DiagnoseFailures(orgID, clusterID string, failures []k8s.PodFailure) []Diagnosis {
@@ -129,11 +265,15 @@ func DiagnoseFailures(orgID, clusterID string, failures []k8s.PodFailure) []Diag
 
 	out := make([]Diagnosis, 0, len(failures))
 	for _, failure := range failures {
+		// Several types of one failure can resolve to the same diagnosis,
+		// e.g. an exit 137 flagged as OOMKilled that was a liveness kill.
+		seen := make(map[string]bool, len(failure.Types))
 		for _, failureType := range failure.Types {
 			diagnosis, ok := engine.Diagnose(orgID, clusterID, failure, failureType)
-			if !ok {
+			if !ok || seen[diagnosis.FailureType] {
 				continue
 			}
+			seen[diagnosis.FailureType] = true
 			out = append(out, diagnosis)
 		}
 	}
@@ -226,7 +366,7 @@ func enrichConfidence(base, failureType string, failure k8s.PodFailure, evidence
 				evidenceScore = maxInt(evidenceScore, 1)
 				reasons = append(reasons, "scheduler event reports node/resource constraints")
 			}
-		case "ReadinessProbeFailed", "LivenessProbeFailed":
+		case "ReadinessProbeFailed", "LivenessProbeFailed", "StartupProbeFailed":
 			if strings.Contains(lowerEvent, "probe failed") || strings.Contains(lowerEvent, "unhealthy") {
 				evidenceScore = maxInt(evidenceScore, 1)
 				reasons = append(reasons, "kubelet reported probe failure")
@@ -259,6 +399,54 @@ func enrichConfidence(base, failureType string, failure k8s.PodFailure, evidence
 		}
 	}
 
+	if failure.Batch != nil {
+		switch failureType {
+		case "JobBackoffLimitExceeded", "JobDeadlineExceeded":
+			evidenceScore = maxInt(evidenceScore, 1)
+			reasons = append(reasons, "job controller marked the Job failed")
+		case "CronJobMissedSchedule", "CronJobForbidStalled":
+			evidenceScore = maxInt(evidenceScore, 1)
+			reasons = append(reasons, "CronJob status shows no run since the missed schedule")
+		}
+	}
+	if isStorageFailureType(failureType) {
+		if vol, ok := problemVolume(failureType, failure); ok && (vol.ClaimPhase != "Bound" || vol.ZoneConflict != "" || vol.PersistentVolume != "") {
+			evidenceScore = maxInt(evidenceScore, 1)
+			reasons = append(reasons, "claim "+vol.Claim+" was resolved to its volume and class")
+		}
+	}
+	if failureType == "Evicted" && failure.Eviction != nil && failure.Eviction.Resource != "" {
+		evidenceScore = maxInt(evidenceScore, 1)
+		reasons = append(reasons, "eviction message names the resource")
+	}
+	if se := failure.StartError; se != nil && isStartFailureType(failureType) && (se.Name != "" || se.Key != "") {
+		evidenceScore = maxInt(evidenceScore, 1)
+		reasons = append(reasons, "kubelet message names the "+strings.ToLower(se.Kind)+" it failed on")
+	}
+	if len(failure.NodeConditions) > 0 && isNodeFailureType(failureType) {
+		evidenceScore = maxInt(evidenceScore, 1)
+		reasons = append(reasons, "kubelet reports the node condition")
+	}
+	if len(probeIssuesFor(failureType, failure)) > 0 {
+		evidenceScore = maxInt(evidenceScore, 1)
+		reasons = append(reasons, "probe spec contradicts the container's ports, startup time or image")
+	}
+	if failureType == "NonZeroExit" && failure.ExitCode != 0 {
+		evidenceScore = maxInt(evidenceScore, 1)
+		reasons = append(reasons, "container exit code recorded")
+	}
+	if code, reason := containerExit(failure); code != 0 || reason == "Completed" {
+		status := decodeExitCode(code)
+		switch {
+		case failureType == "OOMKilled" && code == 137 && reason != "OOMKilled" && sigkillSource(reason, failure.Events) != killOOM:
+			baseScore = maxInt(1, baseScore-1)
+			reasons = append(reasons, "exit code 137 without an OOMKilled termination reason")
+		case status.Class != exitAppError && (failureType == "CrashLoopBackOff" || failureType == "NonZeroExit" || failureType == "LivenessProbeFailed"):
+			evidenceScore = maxInt(evidenceScore, 1)
+			reasons = append(reasons, "exit code "+exitLabel(code)+" identifies how the process ended")
+		}
+	}
+
 	if len(failure.Events) == 0 {
 		baseScore = maxInt(1, baseScore-1)
 		reasons = append(reasons, "no recent events were captured")
@@ -278,6 +466,10 @@ func enrichConfidence(base, failureType string, failure k8s.PodFailure, evidence
 		reasons = append(reasons, "non-zero process exit code observed")
 	}
 
+	if len(failure.PreviousLogTail) > 0 && failureType == "CrashLoopBackOff" {
+		reasons = append(reasons, "previous container logs captured")
+	}
+
 	finalScore := baseScore + evidenceScore
 	if finalScore > 3 {
 		finalScore = 3
@@ -316,6 +508,34 @@ func computeSeverity(confidence, failureType string, failure k8s.PodFailure) str
 		score += 1
 	case "DeploymentRolloutFailed":
 		score += 2
+	case "JobBackoffLimitExceeded", "JobDeadlineExceeded", "CronJobForbidStalled":
+		score += 1
+	case "CronJobMissedSchedule":
+		if failure.Batch != nil && failure.Batch.MissedRuns >= 3 {
+			score += 1
+		}
+	case "NodeNotReady":
+		score += 2
+	case "NodeDiskPressure", "NodeMemoryPressure", "NodePIDPressure":
+		score += 1
+	case "PVCUnbound", "VolumeMultiAttach", "VolumeZoneMismatch", "FailedAttachVolume":
+		score += 1
+	case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError", "ErrImageNeverPull":
+		score += 1
+	case "StartupProbeFailed":
+		score += 1
+	case "Evicted":
+		if e := failure.Eviction; e != nil && e.EvictedCount >= 10 {
+			score += 2
+		} else if e != nil && e.EvictedCount >= 3 {
+			score += 1
+		}
+	}
+	switch failure.ContainerRole {
+	case k8s.ContainerRoleInit:
+		score += 1 // no container of the pod can start
+	case k8s.ContainerRoleEphemeral:
+		score -= 2 // a debug container; the workload is unaffected
 	}
 	if failure.RestartCount >= 10 {
 		score += 2
@@ -398,15 +618,35 @@ func buildEvidence(failureType string, failure k8s.PodFailure) []string {
 	if failure.Message != "" {
 		evidence = append(evidence, "Kubernetes message: "+failure.Message)
 	}
-	if failure.Deployment != "" {
-		evidence = append(evidence, "Deployment: "+failure.Deployment)
+	if failure.WorkloadName != "" {
+		evidence = append(evidence, "Workload: "+workloadLabel(failure))
 	}
-	if failure.DeploymentRevision != "" {
-		evidence = append(evidence, "Deployment revision: "+failure.DeploymentRevision)
+	if failure.WorkloadRevision != "" {
+		evidence = append(evidence, "Workload revision: "+failure.WorkloadRevision)
 	}
 	if failure.ContainerCommand != "" {
 		evidence = append(evidence, "Container command: "+failure.ContainerCommand)
 	}
+	if failure.NodeName != "" {
+		evidence = append(evidence, "Node: "+failure.NodeName)
+	}
+	if len(failure.NodeConditions) > 0 {
+		evidence = append(evidence, "Node conditions: "+strings.Join(failure.NodeConditions, ", "))
+	}
+	evidence = append(evidence, batchEvidence(failure.Batch)...)
+	if isStorageFailureType(failureType) {
+		evidence = append(evidence, volumeEvidence(failure.Volumes)...)
+	}
+	evidence = append(evidence, evictionEvidence(failure)...)
+	evidence = append(evidence, startErrorEvidence(failure.StartError)...)
+	evidence = append(evidence, containerRoleEvidence(failure)...)
+	evidence = append(evidence, exitEvidence(failure)...)
+	evidence = append(evidence, probeEvidence(failureType, failure)...)
+	if failureType == "InitContainerBlocked" {
+		if dependency := initDependency(failure.ContainerCommand); dependency != "" {
+			evidence = append(evidence, "Waits for: "+dependency)
+		}
+	}
 
 	for _, event := range failure.Events {
 		lower := strings.ToLower(event)
@@ -434,7 +674,7 @@ func buildEvidence(failureType string, failure k8s.PodFailure) []string {
 			if strings.Contains(lower, "taint") {
 				evidence = append(evidence, "Node taint mismatch detected")
 			}
-		case "ReadinessProbeFailed", "LivenessProbeFailed":
+		case "ReadinessProbeFailed", "LivenessProbeFailed", "StartupProbeFailed":
 			if strings.Contains(lower, "probe failed") || strings.Contains(lower, "unhealthy") {
 				evidence = append(evidence, "Probe event: "+event)
 			}
@@ -460,6 +700,18 @@ func buildEvidence(failureType string, failure k8s.PodFailure) []string {
 			if strings.Contains(lower, "progress deadline exceeded") || strings.Contains(lower, "timed out progressing") {
 				evidence = append(evidence, "Rollout timeout event: "+event)
 			}
+		case "FailedAttachVolume", "VolumeMultiAttach":
+			if strings.HasPrefix(lower, "failedattachvolume") || strings.Contains(lower, "multi-attach error") {
+				evidence = append(evidence, "Attach failure: "+event)
+			}
+		case "VolumeMountFailed":
+			if strings.HasPrefix(lower, "failedmount") {
+				evidence = append(evidence, "Mount failure: "+event)
+			}
+		case "PVCUnbound", "VolumeZoneMismatch":
+			if strings.HasPrefix(lower, "failedscheduling") && (strings.Contains(lower, "persistentvolumeclaim") || strings.Contains(lower, "persistent volume") || strings.Contains(lower, "volume node affinity")) {
+				evidence = append(evidence, "Scheduler event: "+event)
+			}
 		case "ImageRegistryDNSFailure":
 			if strings.Contains(lower, "lookup") && strings.Contains(lower, "no such host") {
 				evidence = append(evidence, "Registry DNS resolution failure: "+event)
@@ -481,9 +733,28 @@ func buildEvidence(failureType string, failure k8s.PodFailure) []string {
 		evidence = append(evidence, "Pod events captured: "+itoa(len(failure.Events)))
 	}
 
+	evidence = append(evidence, logTailEvidence("Previous container log", failure.PreviousLogTail)...)
+	evidence = append(evidence, logTailEvidence("Container log", failure.LogTail)...)
+
 	return uniqueStrings(evidence)
 }
 
+// logTailEvidence quotes the last few lines of a captured (already redacted)
+// log tail, which is usually where a crashing process says why it exited.
+func logTailEvidence(label string, lines []string) []string {
+	const maxLines = 5
+	out := make([]string, 0, maxLines)
+	for i := len(lines) - 1; i >= 0 && len(out) < maxLines; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			out = append(out, label+": "+line)
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
 // extractQuoted extracts the first quoted string after a keyword:
 // e.g. extractQuoted(`configmap "payment-config" not found`, "configmap") → "payment-config"
 func extractQuoted(text, keyword string) string {
@@ -507,16 +778,20 @@ func buildContextSignals(failure k8s.PodFailure) []string {
 		context = append(context, "Pod appears recently created (possible rollout impact)")
 	}
 	if failure.PodAgeSeconds > 0 {
-		context = append(context, "Pod age: "+formatAge(failure.PodAgeSeconds))
+		if isNodeFailure(failure) {
+			context = append(context, "Node age: "+formatAge(failure.PodAgeSeconds))
+		} else {
+			context = append(context, "Pod age: "+formatAge(failure.PodAgeSeconds))
+		}
 	}
-	if failure.Deployment != "" {
-		context = append(context, "Deployment: "+failure.Deployment)
+	if failure.WorkloadName != "" {
+		context = append(context, "Workload: "+workloadLabel(failure))
 	}
-	if failure.DeploymentRevision != "" {
-		context = append(context, "Deployment revision: "+failure.DeploymentRevision)
+	if failure.WorkloadRevision != "" {
+		context = append(context, "Workload revision: "+failure.WorkloadRevision)
 	}
-	if failure.ReplicaStatus != "" {
-		context = append(context, "Replicas: "+failure.ReplicaStatus+" ready")
+	if failure.WorkloadReadiness != "" {
+		context = append(context, "Readiness: "+failure.WorkloadReadiness)
 	}
 	if len(failure.Services) > 0 {
 		context = append(context, "Services: "+strings.Join(failure.Services, ", "))
@@ -527,6 +802,13 @@ func buildContextSignals(failure k8s.PodFailure) []string {
 	if len(failure.Secrets) > 0 {
 		context = append(context, "Secrets: "+strings.Join(failure.Secrets, ", "))
 	}
+	if len(failure.Volumes) > 0 {
+		claims := make([]string, 0, len(failure.Volumes))
+		for _, vol := range failure.Volumes {
+			claims = append(claims, vol.Claim)
+		}
+		context = append(context, "PersistentVolumeClaims: "+strings.Join(claims, ", "))
+	}
 	if len(failure.EnvVariables) > 0 {
 		maxEnv := len(failure.EnvVariables)
 		if maxEnv > 8 {
@@ -560,7 +842,9 @@ func deriveLikelyCause(defaultCause, failureType string, failure k8s.PodFailure,
 		}
 		return "Container runtime could not resolve image registry hostname during pull"
 	case "CrashLoopBackOff":
-		combined := strings.ToLower(strings.Join(append([]string{failure.Message}, failure.Events...), "\n"))
+		signals := append([]string{failure.Message}, failure.Events...)
+		signals = append(signals, failure.PreviousLogTail...)
+		combined := strings.ToLower(strings.Join(signals, "\n"))
 		if strings.Contains(combined, "econnrefused") || strings.Contains(combined, "connection refused") {
 			if len(failure.Services) > 0 {
 				return "Application cannot connect to service " + failure.Services[0] + " (connection refused)"
@@ -576,26 +860,14 @@ func deriveLikelyCause(defaultCause, failureType string, failure k8s.PodFailure,
 		if strings.Contains(combined, "permission denied") && failure.ContainerCommand != "" {
 			return "Container command failed with permission denied (check executable path/permissions): " + failure.ContainerCommand
 		}
+		if cause := exitCause(failureType, failure); cause != "" {
+			return cause
+		}
 		if failure.ExitCode != 0 || failure.LastExitCode != 0 {
-			code := failure.ExitCode
-			if code == 0 {
-				code = failure.LastExitCode
-			}
-			if code == 137 {
-				if failure.MemoryLimit != "" {
-					return "Process terminated with exit code 137 (likely memory pressure near limit " + failure.MemoryLimit + ")"
-				}
-				return "Process terminated with exit code 137 (likely memory pressure or forced kill)"
-			}
-			if code == 127 {
-				return "Process exited with code 127 (startup command or binary not found)"
-			}
-			if code == 126 {
-				return "Process exited with code 126 (startup command found but not executable)"
-			}
+			code, _ := containerExit(failure)
 			if code == 1 && failure.RecentRollout {
-				if failure.DeploymentRevision != "" {
-					return "Application began crashing right after rollout revision " + failure.DeploymentRevision + " (exit code 1)"
+				if failure.WorkloadRevision != "" {
+					return "Application began crashing right after rollout revision " + failure.WorkloadRevision + " (exit code 1)"
 				}
 				return "Application began crashing immediately after a recent rollout (exit code 1)"
 			}
@@ -605,6 +877,9 @@ func deriveLikelyCause(defaultCause, failureType string, failure k8s.PodFailure,
 			return "Container continuously crashing shortly after launch (" + itoa32(failure.RestartCount) + " restarts)"
 		}
 	case "OOMKilled":
+		if code, reason := containerExit(failure); code == 137 && reason != "OOMKilled" {
+			return sigkillCause(failure, sigkillSource(reason, failure.Events))
+		}
 		if failure.MemoryLimit != "" {
 			return "Container terminated by kernel OOM killer — exceeded memory limit " + failure.MemoryLimit
 		}
@@ -623,25 +898,41 @@ func deriveLikelyCause(defaultCause, failureType string, failure k8s.PodFailure,
 		}
 		return "Pod cannot be scheduled — cluster capacity or placement constraint"
 	case "ReadinessProbeFailed":
+		if cause := probeCause(failureType, failure); cause != "" {
+			return cause
+		}
 		return "Application started but is not passing readiness checks — traffic is being withheld"
 	case "LivenessProbeFailed":
+		if cause := probeCause(failureType, failure); cause != "" {
+			return cause
+		}
+		if code, _ := containerExit(failure); code == 137 || code == 143 {
+			return exitCause(failureType, failure)
+		}
 		return "Liveness probe is failing — kubelet will restart the container"
+	case "StartupProbeFailed":
+		if cause := probeCause(failureType, failure); cause != "" {
+			return cause
+		}
+		if p, ok := findProbe(failure, k8s.ProbeStartup); ok {
+			return "Startup probe did not pass within its " + seconds(probeAllowance(p)) + " allowance — the kubelet restarts container " + defaultValue(failure.Container, "of the pod") + " before it starts serving"
+		}
 	case "ConfigMapMissing":
 		for _, e := range evidence {
 			if strings.HasPrefix(e, "ConfigMap not found: ") {
 				name := strings.TrimPrefix(e, "ConfigMap not found: ")
-				return "Deployment references ConfigMap \"" + name + "\" which does not exist in namespace " + failure.Namespace
+				return workloadSubject(failure) + " references ConfigMap \"" + name + "\" which does not exist in namespace " + failure.Namespace
 			}
 		}
-		return "Deployment references a ConfigMap that does not exist in namespace " + failure.Namespace
+		return workloadSubject(failure) + " references a ConfigMap that does not exist in namespace " + failure.Namespace
 	case "SecretMissing":
 		for _, e := range evidence {
 			if strings.HasPrefix(e, "Secret not found: ") {
 				name := strings.TrimPrefix(e, "Secret not found: ")
-				return "Deployment references Secret \"" + name + "\" which does not exist in namespace " + failure.Namespace
+				return workloadSubject(failure) + " references Secret \"" + name + "\" which does not exist in namespace " + failure.Namespace
 			}
 		}
-		return "Deployment references a Secret that does not exist in namespace " + failure.Namespace
+		return workloadSubject(failure) + " references a Secret that does not exist in namespace " + failure.Namespace
 	case "PodPending":
 		return "Pod is stuck in pending state — waiting for volume mounts, scheduling, or resource availability"
 	case "DNSLookupFailed":
@@ -649,13 +940,112 @@ func deriveLikelyCause(defaultCause, failureType string, failure k8s.PodFailure,
 	case "NetworkTimeout":
 		return "Application timed out while connecting to a dependency endpoint"
 	case "DeploymentRolloutFailed":
-		if failure.Deployment != "" && failure.ReplicaStatus != "" {
-			return "Deployment " + failure.Deployment + " rollout stalled with only " + failure.ReplicaStatus + " replicas ready before progress deadline"
+		if name := deploymentName(failure); name != "" && failure.WorkloadReadiness != "" {
+			return "Deployment " + name + " rollout stalled with only " + failure.WorkloadReadiness + " before progress deadline"
 		}
-		if failure.Deployment != "" {
-			return "Deployment " + failure.Deployment + " rollout stalled before progress deadline"
+		if name := deploymentName(failure); name != "" {
+			return "Deployment " + name + " rollout stalled before progress deadline"
 		}
 		return "Deployment rollout failed to progress before progress deadline"
+	case "NonZeroExit":
+		if failure.Container != "" && failure.ExitCode != 0 {
+			cause := "Container " + failure.Container + " exited with code " + itoa32(failure.ExitCode) + " and was not restarted (restartPolicy: Never)"
+			if status := decodeExitCode(failure.ExitCode); status.Class != exitAppError {
+				cause += ": " + status.Meaning
+			}
+			return cause
+		}
+	case "JobBackoffLimitExceeded":
+		if b := failure.Batch; b != nil {
+			cause := "Job " + b.Job + " failed " + itoa32(b.Failed) + " times and reached its backoffLimit of " + itoa32(backoffLimit(*b))
+			if failure.ExitCode != 0 {
+				cause += " (last exit code " + exitLabel(failure.ExitCode) + ")"
+			}
+			return cause
+		}
+	case "JobDeadlineExceeded":
+		if b := failure.Batch; b != nil && b.ActiveDeadlineSeconds != nil {
+			return "Job " + b.Job + " ran longer than its activeDeadlineSeconds (" + formatAge(*b.ActiveDeadlineSeconds) + ") and was terminated"
+		}
+	case "CronJobMissedSchedule":
+		if b := failure.Batch; b != nil && b.MissedRun != nil {
+			missed := b.MissedRun.Format(time.RFC3339)
+			if b.StartingDeadlineSeconds != nil && *b.StartingDeadlineSeconds < 60 {
+				return "CronJob " + b.CronJob + " missed its run at " + missed + "; startingDeadlineSeconds (" + itoa(int(*b.StartingDeadlineSeconds)) + "s) leaves the controller almost no time to start it"
+			}
+			if b.MissedRuns > 1 {
+				return "CronJob " + b.CronJob + " has not started a run since " + missed + " (" + missedRunsText(*b) + " missed) — the CronJob controller may be unhealthy"
+			}
+			return "CronJob " + b.CronJob + " missed its run scheduled at " + missed
+		}
+	case "CronJobForbidStalled":
+		if b := failure.Batch; b != nil {
+			cause := "Job " + b.Job + " is still running, so concurrencyPolicy: Forbid makes CronJob " + b.CronJob + " skip its runs"
+			if b.StartTime != nil {
+				cause = "Job " + b.Job + " has been running since " + b.StartTime.Format(time.RFC3339) + ", so concurrencyPolicy: Forbid makes CronJob " + b.CronJob + " skip its runs"
+			}
+			return cause + " (" + missedRunsText(*b) + " missed)"
+		}
+	case "Evicted":
+		if e := failure.Eviction; e != nil {
+			return evictionCause(failure, *e)
+		}
+	case "InitContainerBlocked":
+		return initBlockedCause(failure)
+	case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError", "ErrImageNeverPull":
+		if se := failure.StartError; se != nil {
+			if cause := startErrorCause(failureType, failure, *se); cause != "" {
+				return cause
+			}
+		}
+	case "PVCUnbound":
+		if vol, ok := problemVolume(failureType, failure); ok {
+			return unboundClaimCause(vol)
+		}
+	case "FailedAttachVolume":
+		if vol, ok := problemVolume(failureType, failure); ok {
+			cause := volumeLabel(vol) + " could not be attached to node " + defaultValue(failure.NodeName, "of the pod")
+			if vol.Driver != "" {
+				cause += " by CSI driver " + vol.Driver
+			}
+			return cause
+		}
+	case "VolumeMultiAttach":
+		if vol, ok := problemVolume(failureType, failure); ok {
+			return volumeLabel(vol) + " is " + defaultValue(strings.Join(vol.AccessModes, ", "), "single-node") + " and still attached to another node, so it cannot be attached to node " + defaultValue(failure.NodeName, "of the new pod")
+		}
+	case "VolumeMountFailed":
+		if vol, ok := problemVolume(failureType, failure); ok {
+			cause := "Mounting " + volumeLabel(vol) + " timed out on node " + defaultValue(failure.NodeName, "of the pod")
+			if vol.Driver != "" {
+				cause += "; CSI driver " + vol.Driver + " did not complete the mount"
+			}
+			return cause
+		}
+	case "VolumeZoneMismatch":
+		if vol, ok := problemVolume(failureType, failure); ok && len(vol.Zones) > 0 {
+			zones := strings.Join(vol.Zones, ", ")
+			if vol.ZoneConflict != "" {
+				return volumeLabel(vol) + " is pinned to zone " + zones + ", but the pod's node is in zone " + vol.ZoneConflict
+			}
+			return volumeLabel(vol) + " is pinned to zone " + zones + " and no node there can run the pod"
+		}
+	case "NodeNotReady":
+		if failure.NodeName != "" {
+			return "Node " + failure.NodeName + " is NotReady; its kubelet is down or cannot reach the control plane, so pods on it are not being managed"
+		}
+	case "NodeDiskPressure":
+		if failure.NodeName != "" {
+			return "Node " + failure.NodeName + " is low on disk space (DiskPressure); the kubelet is evicting pods and removing unused images"
+		}
+	case "NodeMemoryPressure":
+		if failure.NodeName != "" {
+			return "Node " + failure.NodeName + " is low on memory (MemoryPressure); the kubelet is evicting pods and the kernel may OOM-kill containers"
+		}
+	case "NodePIDPressure":
+		if failure.NodeName != "" {
+			return "Node " + failure.NodeName + " is running out of process IDs (PIDPressure); new processes and containers fail to start"
+		}
 	}
 
 	return defaultCause
@@ -678,7 +1068,7 @@ func deriveSuggestedFix(defaultFix, failureType string, failure k8s.PodFailure,
 	switch failureType {
 	case "ImagePullBackOff":
 		if failure.Image != "" {
-			return "Run: docker pull " + failure.Image + "\n\nIf the image is private, verify imagePullSecrets in the Deployment spec. If the tag is wrong, update the image field."
+			return "Run: docker pull " + failure.Image + "\n\nIf the image is private, verify imagePullSecrets in the " + workloadKind(failure) + " spec. If the tag is wrong, update the image field."
 		}
 		return "Verify image name and tag. Check imagePullSecrets. Run: kubectl -n " + ns + " get pod " + pod + " -o jsonpath='{.spec.imagePullSecrets}'"
 	case "CrashLoopBackOff":
@@ -693,24 +1083,24 @@ func deriveSuggestedFix(defaultFix, failureType string, failure k8s.PodFailure,
 		return "Add or increase resources.limits.memory in the container spec."
 	case "FailedScheduling":
 		return "1. kubectl describe nodes — check CPU/memory available\n2. kubectl -n " + ns + " describe pod " + pod + " — inspect scheduling message\n3. Reduce resource requests or add cluster nodes."
-	case "ReadinessProbeFailed", "LivenessProbeFailed":
-		return "1. Confirm the probe path/port is correct in the Deployment spec\n2. Check the app is ready to serve before probes fire (tune initialDelaySeconds)\n3. kubectl -n " + ns + " logs " + pod + " to see health endpoint errors"
+	case "ReadinessProbeFailed", "LivenessProbeFailed", "StartupProbeFailed":
+		return "1. Confirm the probe path/port is correct in the " + workloadKind(failure) + " spec\n2. Check the app is ready to serve before probes fire (tune initialDelaySeconds)\n3. kubectl -n " + ns + " logs " + pod + " to see health endpoint errors"
 	case "ConfigMapMissing":
 		for _, e := range evidence {
 			if strings.HasPrefix(e, "ConfigMap not found: ") {
 				name := strings.TrimPrefix(e, "ConfigMap not found: ")
-				return "Create the missing ConfigMap:\n\nkubectl create configmap " + name + " --from-env-file=config.env -n " + ns + "\n\nOR update the Deployment to reference an existing ConfigMap."
+				return "Create the missing ConfigMap:\n\nkubectl create configmap " + name + " --from-env-file=config.env -n " + ns + "\n\nOR update the " + workloadKind(failure) + " to reference an existing ConfigMap."
 			}
 		}
-		return "Create the missing ConfigMap referenced in the Deployment volumes or envFrom section."
+		return "Create the missing ConfigMap referenced in the " + workloadKind(failure) + " volumes or envFrom section."
 	case "SecretMissing":
 		for _, e := range evidence {
 			if strings.HasPrefix(e, "Secret not found: ") {
 				name := strings.TrimPrefix(e, "Secret not found: ")
-				return "Create the missing Secret:\n\nkubectl create secret generic " + name + " --from-literal=key=value -n " + ns + "\n\nOR update the Deployment to reference an existing Secret."
+				return "Create the missing Secret:\n\nkubectl create secret generic " + name + " --from-literal=key=value -n " + ns + "\n\nOR update the " + workloadKind(failure) + " to reference an existing Secret."
 			}
 		}
-		return "Create the missing Secret referenced in the Deployment volumes or envFrom section."
+		return "Create the missing Secret referenced in the " + workloadKind(failure) + " volumes or envFrom section."
 	case "PodPending":
 		return "Run: kubectl -n " + ns + " describe pod " + pod + "\n\nLook for volume mount errors, scheduling failures, or missing resources."
 	case "DNSLookupFailed":
@@ -720,8 +1110,8 @@ func deriveSuggestedFix(defaultFix, failureType string, failure k8s.PodFailure,
 	case "NetworkTimeout":
 		return "1. Check endpoints for the target Service\n2. Validate NetworkPolicies allow traffic\n3. Ensure destination pods are healthy and listening"
 	case "DeploymentRolloutFailed":
-		if failure.Deployment != "" {
-			return "1. Check rollout status: kubectl -n " + ns + " rollout status deployment/" + failure.Deployment + "\n2. Inspect deployment events: kubectl -n " + ns + " describe deployment " + failure.Deployment + "\n3. Compare current revision to previous and inspect failing pod logs"
+		if name := deploymentName(failure); name != "" {
+			return "1. Check rollout status: kubectl -n " + ns + " rollout status deployment/" + name + "\n2. Inspect deployment events: kubectl -n " + ns + " describe deployment " + name + "\n3. Compare current revision to previous and inspect failing pod logs"
 		}
 		return "1. Inspect deployment events for progress deadline failures\n2. Compare recent image/config changes\n3. Check logs of new pods created during rollout"
 	}
@@ -738,16 +1128,16 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 	case "ImagePullBackOff":
 		fixes := make([]FixSuggestion, 0, 3)
 		fixes = append(fixes, FixSuggestion{
-			Title:       "Check deployment image",
+			Title:       "Check workload image",
 			Explanation: "Confirm the workload is using the image you expect before patching credentials or tags.",
-			Command:     "kubectl -n " + ns + " get deployment -o yaml | grep image",
+			Command:     "kubectl -n " + ns + " get " + workloadRef(failure) + " -o yaml | grep image",
 		})
 		for _, e := range evidence {
 			if strings.Contains(e, "not found") {
 				fixes = append(fixes,
 					FixSuggestion{
 						Title:       "Use an existing image tag",
-						Explanation: "Update the Deployment to an image tag that already exists in the registry.",
+						Explanation: "Update the " + workloadKind(failure) + " to an image tag that already exists in the registry.",
 						Command:     "image: nginx:latest",
 					},
 					FixSuggestion{
@@ -767,7 +1157,7 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 					},
 					FixSuggestion{
 						Title:       "Attach imagePullSecrets",
-						Explanation: "Patch the Deployment so kubelet uses the registry credentials during image pull.",
+						Explanation: "Patch the " + workloadKind(failure) + " so kubelet uses the registry credentials during image pull.",
 						Command:     "spec:\n  imagePullSecrets:\n  - name: regcred",
 					},
 				)
@@ -783,13 +1173,13 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 		return []FixSuggestion{
 			{
 				Title:       "Create the missing ConfigMap",
-				Explanation: "Create the ConfigMap that the Deployment is already referencing.",
+				Explanation: "Create the ConfigMap that the " + workloadKind(failure) + " is already referencing.",
 				Command:     "kubectl create configmap " + name + " \\\n  --from-env-file=config.env \\\n  -n " + ns,
 			},
 			{
 				Title:       "Verify the reference name",
-				Explanation: "Check the Deployment manifest for the referenced ConfigMap name.",
-				Command:     "kubectl -n " + ns + " get deployment -o yaml | grep -A3 " + name,
+				Explanation: "Check the " + workloadKind(failure) + " manifest for the referenced ConfigMap name.",
+				Command:     "kubectl -n " + ns + " get " + workloadRef(failure) + " -o yaml | grep -A3 " + name,
 			},
 		}
 	case "SecretMissing":
@@ -800,13 +1190,13 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 		return []FixSuggestion{
 			{
 				Title:       "Create the missing Secret",
-				Explanation: "Create the Secret that the Deployment expects in this namespace.",
+				Explanation: "Create the Secret that the " + workloadKind(failure) + " expects in this namespace.",
 				Command:     "kubectl create secret generic " + name + " \\\n  --from-literal=password=<value> \\\n  -n " + ns,
 			},
 			{
 				Title:       "Verify the secret reference",
-				Explanation: "Check the Deployment manifest for the referenced Secret name.",
-				Command:     "kubectl -n " + ns + " get deployment -o yaml | grep -A3 " + name,
+				Explanation: "Check the " + workloadKind(failure) + " manifest for the referenced Secret name.",
+				Command:     "kubectl -n " + ns + " get " + workloadRef(failure) + " -o yaml | grep -A3 " + name,
 			},
 		}
 	case "CrashLoopBackOff":
@@ -818,7 +1208,8 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 		if failure.Container != "" {
 			describeCmd = "kubectl -n " + ns + " get pod " + pod + " -o jsonpath='{.spec.containers[?(@.name==\"" + failure.Container + "\")].command}'"
 		}
-		return []FixSuggestion{
+		fixes := exitFixes(failureType, failure)
+		return append(fixes, []FixSuggestion{
 			{
 				Title:       "Inspect previous logs",
 				Explanation: "Start with the last crashed container logs. This usually exposes the exact startup error.",
@@ -829,7 +1220,7 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 				Explanation: "Verify the container command/args running in the pod are what your app expects.",
 				Command:     describeCmd,
 			},
-		}
+		}...)
 	case "OOMKilled":
 		return []FixSuggestion{
 			{
@@ -856,23 +1247,28 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 				Command:     "resources:\n  requests:\n    cpu: 100m\n    memory: 128Mi",
 			},
 		}
-	case "ReadinessProbeFailed", "LivenessProbeFailed":
-		probeName := "readinessProbe"
-		if failureType == "LivenessProbeFailed" {
-			probeName = "livenessProbe"
+	case "ReadinessProbeFailed", "LivenessProbeFailed", "StartupProbeFailed":
+		probeName := probeKind(failureType) + "Probe"
+		timing := FixSuggestion{
+			Title:       "Delay probe startup",
+			Explanation: "If the application starts slowly, increase the initial delay before probes begin.",
+			Command:     probeName + ":\n  initialDelaySeconds: 20\n  timeoutSeconds: 2",
 		}
-		return []FixSuggestion{
-			{
+		if failureType == "StartupProbeFailed" {
+			timing = FixSuggestion{
+				Title:       "Allow more time to start",
+				Explanation: "The startup probe gives up after failureThreshold × periodSeconds; raise the threshold to cover the slowest start.",
+				Command:     "startupProbe:\n  periodSeconds: 10\n  failureThreshold: 30",
+			}
+		}
+		return append(probeFixes(failureType, failure),
+			FixSuggestion{
 				Title:       "Check probe config",
-				Explanation: "Verify the probe path, port, and timing in the Deployment manifest.",
-				Command:     "kubectl -n " + ns + " get deployment -o yaml | grep -A10 " + probeName,
-			},
-			{
-				Title:       "Delay probe startup",
-				Explanation: "If the application starts slowly, increase the initial delay before probes begin.",
-				Command:     probeName + ":\n  initialDelaySeconds: 20\n  timeoutSeconds: 2",
+				Explanation: "Verify the probe path, port, and timing in the " + workloadKind(failure) + " manifest.",
+				Command:     "kubectl -n " + ns + " get " + workloadRef(failure) + " -o yaml | grep -A10 " + probeName,
 			},
-		}
+			timing,
+		)
 	case "PodPending":
 		return []FixSuggestion{
 			{
@@ -920,8 +1316,289 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 				Command:     "kubectl -n " + ns + " get networkpolicy",
 			},
 		}
+	case "NonZeroExit":
+		logsCmd := "kubectl -n " + ns + " logs " + pod
+		if failure.Container != "" {
+			logsCmd += " -c " + failure.Container
+		}
+		return append(exitFixes(failureType, failure), FixSuggestion{
+			Title:       "Inspect the container logs",
+			Explanation: "The pod is not restarted, so its logs still hold the error that ended the run.",
+			Command:     logsCmd,
+		})
+	case "JobBackoffLimitExceeded":
+		b := batchOrEmpty(failure)
+		limit := backoffLimit(b)
+		return []FixSuggestion{
+			{
+				Title:       "Inspect the failed run's logs",
+				Explanation: "Every retry failed; the logs of the last attempt usually show why.",
+				Command:     batchLogsCommand(failure),
+			},
+			{
+				Title:       "Raise backoffLimit for transient failures",
+				Explanation: "Only if the failures are transient: allow more retries than the current limit of " + itoa32(limit) + ".",
+				Command:     jobSpecSnippet(b, "backoffLimit: "+itoa32(maxInt32(limit*2, 3))),
+			},
+			rerunSuggestion(ns, b),
+		}
+	case "JobDeadlineExceeded":
+		b := batchOrEmpty(failure)
+		deadline := int64(3600)
+		if b.ActiveDeadlineSeconds != nil {
+			deadline = *b.ActiveDeadlineSeconds * 2
+		}
+		return []FixSuggestion{
+			{
+				Title:       "See where the run spent its time",
+				Explanation: "Check whether pods were slow to schedule, pull or start before concluding the work itself is too slow.",
+				Command:     "kubectl -n " + ns + " describe job/" + defaultValue(b.Job, "<job-name>"),
+			},
+			{
+				Title:       "Raise activeDeadlineSeconds",
+				Explanation: "If the run legitimately needs longer, give the Job more time.",
+				Command:     jobSpecSnippet(b, "activeDeadlineSeconds: "+strconv.FormatInt(deadline, 10)),
+			},
+			rerunSuggestion(ns, b),
+		}
+	case "CronJobMissedSchedule":
+		b := batchOrEmpty(failure)
+		fixes := []FixSuggestion{rerunSuggestion(ns, b)}
+		if b.StartingDeadlineSeconds != nil {
+			deadline := *b.StartingDeadlineSeconds * 2
+			if deadline < 300 {
+				deadline = 300
+			}
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Widen startingDeadlineSeconds",
+				Explanation: "Runs that cannot start within " + strconv.FormatInt(*b.StartingDeadlineSeconds, 10) + "s of their schedule are skipped; allow more slack.",
+				Command:     "spec:\n  startingDeadlineSeconds: " + strconv.FormatInt(deadline, 10),
+			})
+		}
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Check the CronJob controller",
+			Explanation: "Runs are started by kube-controller-manager; look for CronJob warnings and controller restarts.",
+			Command:     "kubectl -n " + ns + " get events --field-selector involvedObject.kind=CronJob,involvedObject.name=" + defaultValue(b.CronJob, failure.Name),
+		})
+		return fixes
+	case "CronJobForbidStalled":
+		b := batchOrEmpty(failure)
+		active := defaultValue(b.Job, "<job-name>")
+		deadline := "activeDeadlineSeconds: 3600"
+		if b.ActiveDeadlineSeconds != nil {
+			deadline = "activeDeadlineSeconds: " + strconv.FormatInt(*b.ActiveDeadlineSeconds/2, 10)
+		}
+		return []FixSuggestion{
+			{
+				Title:       "Inspect the running Job",
+				Explanation: "Find out whether the run is stuck or just slow before stopping it.",
+				Command:     "kubectl -n " + ns + " describe job/" + active,
+			},
+			{
+				Title:       "Stop the stuck run",
+				Explanation: "Deleting the Job lets the CronJob start its next scheduled run.",
+				Command:     "kubectl -n " + ns + " delete job " + active,
+			},
+			{
+				Title:       "Bound each run's duration",
+				Explanation: "With a deadline on the job template a hung run is terminated instead of blocking the schedule.",
+				Command:     "spec:\n  jobTemplate:\n    spec:\n      " + deadline,
+			},
+			{
+				Title:       "Let new runs replace stuck ones",
+				Explanation: "If only the latest run matters, Replace stops the old Job when the next one is due.",
+				Command:     "spec:\n  concurrencyPolicy: Replace",
+			},
+		}
+	case "Evicted":
+		return evictionFixes(failure)
+	case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError", "ErrImageNeverPull":
+		return startErrorFixes(failureType, failure)
+	case "InitContainerBlocked":
+		return initBlockedFixes(failure)
+	case "PVCUnbound":
+		vol, _ := problemVolume(failureType, failure)
+		claim := defaultValue(vol.Claim, "<claim-name>")
+		fixes := []FixSuggestion{{
+			Title:       "Inspect claim " + claim,
+			Explanation: "The claim's events say whether provisioning failed, is waiting, or found no matching volume.",
+			Command:     "kubectl -n " + ns + " describe pvc " + claim,
+		}}
+		switch {
+		case vol.ClaimPhase == "Missing":
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Create claim " + claim,
+				Explanation: "The pod mounts a claim that does not exist; create it or fix the claimName in the " + workloadKind(failure) + " spec.",
+				Command:     "apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: " + claim + "\n  namespace: " + ns + "\nspec:\n  accessModes: [ReadWriteOnce]\n  resources:\n    requests:\n      storage: 10Gi",
+			})
+		case vol.StorageClassMissing || vol.StorageClass == "":
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Recreate " + claim + " with an existing StorageClass",
+				Explanation: "storageClassName cannot be changed on an existing claim; pick a class from the list and recreate the claim with it.",
+				Command:     "kubectl get storageclass\nkubectl -n " + ns + " get pvc " + claim + " -o yaml > " + claim + ".yaml",
+			})
+		case vol.Provisioner != "":
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Check provisioner " + vol.Provisioner,
+				Explanation: "Dynamic provisioning for this claim is done by the provisioner's controller; its logs show quota, permission or parameter errors.",
+				Command:     "kubectl get pods -A | grep -i " + provisionerHint(vol.Provisioner),
+			})
+		}
+		return fixes
+	case "FailedAttachVolume":
+		vol, _ := problemVolume(failureType, failure)
+		pv := defaultValue(vol.PersistentVolume, "<pv-name>")
+		return []FixSuggestion{
+			{
+				Title:       "Inspect the attachment of " + pv,
+				Explanation: "The VolumeAttachment status carries the attach error reported by the storage backend.",
+				Command:     "kubectl get volumeattachments -o wide | grep " + pv,
+			},
+			{
+				Title:       "Check the CSI controller",
+				Explanation: "Attach is performed by the driver's controller plugin; look for cloud API, quota or permission errors.",
+				Command:     "kubectl get pods -A | grep -i " + provisionerHint(defaultValue(vol.Driver, vol.Provisioner)),
+			},
+		}
+	case "VolumeMultiAttach":
+		vol, _ := problemVolume(failureType, failure)
+		pv := defaultValue(vol.PersistentVolume, "<pv-name>")
+		fixes := []FixSuggestion{
+			{
+				Title:       "Find the node still holding " + pv,
+				Explanation: "A ReadWriteOnce volume attaches to one node at a time; the old pod or a stale attachment keeps it there.",
+				Command:     "kubectl get volumeattachments -o wide | grep " + pv + "\nkubectl -n " + ns + " get pods -o wide",
+			},
+			{
+				Title:       "Release the volume",
+				Explanation: "Delete the old pod if it is stuck terminating; once it is gone the volume detaches and the new pod can start.",
+				Command:     "kubectl -n " + ns + " delete pod <old-pod> --wait=false",
+			},
+		}
+		if failure.WorkloadKind == "Deployment" {
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Use the Recreate strategy",
+				Explanation: "A rolling update starts the new pod before the old one releases the volume; Recreate stops the old pod first.",
+				Command:     "spec:\n  strategy:\n    type: Recreate",
+			})
+		}
+		return fixes
+	case "VolumeMountFailed":
+		vol, _ := problemVolume(failureType, failure)
+		node := defaultValue(failure.NodeName, "<node-name>")
+		return []FixSuggestion{
+			{
+				Title:       "Check the CSI node plugin on " + node,
+				Explanation: "Mounts are done by the driver's node plugin on the pod's node; a crashed or unregistered plugin times out every mount there.",
+				Command:     "kubectl get pods -A -o wide --field-selector spec.nodeName=" + node + " | grep -i " + provisionerHint(defaultValue(vol.Driver, vol.Provisioner)),
+			},
+			{
+				Title:       "Inspect " + defaultValue(vol.PersistentVolume, "the volume"),
+				Explanation: "Confirm the volume exists in the backend and is not still attached elsewhere.",
+				Command:     "kubectl describe pv " + defaultValue(vol.PersistentVolume, "<pv-name>"),
+			},
+		}
+	case "VolumeZoneMismatch":
+		vol, _ := problemVolume(failureType, failure)
+		zone := defaultValue(strings.Join(vol.Zones, ","), "<zone>")
+		fixes := []FixSuggestion{
+			{
+				Title:       "Check nodes in zone " + zone,
+				Explanation: "The volume can only be used from its zone; the pod needs a schedulable node there.",
+				Command:     "kubectl get nodes -L topology.kubernetes.io/zone",
+			},
+			{
+				Title:       "Delay binding until scheduling",
+				Explanation: "With WaitForFirstConsumer new volumes are created in the zone the pod is scheduled to, avoiding this conflict for future claims.",
+				Command:     "apiVersion: storage.k8s.io/v1\nkind: StorageClass\nmetadata:\n  name: " + defaultValue(vol.StorageClass, "<class-name>") + "-wffc\nprovisioner: " + defaultValue(vol.Provisioner, "<provisioner>") + "\nvolumeBindingMode: WaitForFirstConsumer",
+			},
+		}
+		if len(vol.Zones) == 1 {
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Pin the " + workloadKind(failure) + " to the volume's zone",
+				Explanation: "Until there is capacity elsewhere, schedule the pod where claim " + defaultValue(vol.Claim, "<claim-name>") + " lives.",
+				Command:     "spec:\n  template:\n    spec:\n      nodeSelector:\n        topology.kubernetes.io/zone: " + vol.Zones[0],
+			})
+		}
+		return fixes
+	case "NodeNotReady":
+		node := defaultValue(failure.NodeName, "<node-name>")
+		return []FixSuggestion{
+			{
+				Title:       "Check the node's conditions",
+				Explanation: "The Ready condition says whether the kubelet stopped posting status or reported itself unhealthy.",
+				Command:     "kubectl describe node " + node,
+			},
+			{
+				Title:       "Check the kubelet on the node",
+				Explanation: "On the node itself, the kubelet and container runtime logs show why it stopped reporting.",
+				Command:     "journalctl -u kubelet --since '30 min ago' | tail -n 100",
+			},
+			{
+				Title:       "Move workloads off the node",
+				Explanation: "If the node does not recover, stop scheduling onto it and evict its pods so their controllers recreate them elsewhere.",
+				Command:     "kubectl cordon " + node + "\nkubectl drain " + node + " --ignore-daemonsets --delete-emptydir-data",
+			},
+		}
+	case "NodeDiskPressure":
+		node := defaultValue(failure.NodeName, "<node-name>")
+		return []FixSuggestion{
+			{
+				Title:       "Find pods filling ephemeral storage",
+				Explanation: "Container logs, emptyDir volumes and writable layers count against the node's disk.",
+				Command:     "kubectl get --raw /api/v1/nodes/" + node + "/proxy/stats/summary | grep -E '\"(name|usedBytes)\"'",
+			},
+			{
+				Title:       "Remove unused images",
+				Explanation: "On the node itself, pruning images the kubelet has not collected yet frees space quickly.",
+				Command:     "crictl rmi --prune",
+			},
+			{
+				Title:       "Cap ephemeral storage per container",
+				Explanation: "A limit makes the kubelet evict the offending pod instead of the whole node running out of disk.",
+				Command:     "resources:\n  requests:\n    ephemeral-storage: 1Gi\n  limits:\n    ephemeral-storage: 2Gi",
+			},
+		}
+	case "NodeMemoryPressure":
+		node := defaultValue(failure.NodeName, "<node-name>")
+		return []FixSuggestion{
+			{
+				Title:       "Find the pods using the node's memory",
+				Explanation: "Pods without memory limits are the usual cause and the first the kubelet evicts.",
+				Command:     "kubectl top pods -A --sort-by=memory | head -20\nkubectl get pods -A --field-selector spec.nodeName=" + node + " -o wide",
+			},
+			{
+				Title:       "Set memory requests and limits",
+				Explanation: "Requests keep the scheduler from overcommitting the node; limits contain a leaking container.",
+				Command:     "resources:\n  requests:\n    memory: 256Mi\n  limits:\n    memory: 512Mi",
+			},
+			{
+				Title:       "Drain the node if pressure persists",
+				Explanation: "Moving pods elsewhere relieves the node while the heavy workload is fixed.",
+				Command:     "kubectl drain " + node + " --ignore-daemonsets --delete-emptydir-data",
+			},
+		}
+	case "NodePIDPressure":
+		node := defaultValue(failure.NodeName, "<node-name>")
+		return []FixSuggestion{
+			{
+				Title:       "Find the process leak",
+				Explanation: "On the node itself, count threads per process to find the container spawning them.",
+				Command:     "ps -eLo pid,comm --no-headers | awk '{print $2}' | sort | uniq -c | sort -rn | head",
+			},
+			{
+				Title:       "Limit PIDs per pod",
+				Explanation: "The kubelet's podPidsLimit stops one pod from exhausting the node's process IDs.",
+				Command:     "# KubeletConfiguration\npodPidsLimit: 4096",
+			},
+			{
+				Title:       "Drain the node if pressure persists",
+				Explanation: "Moving pods elsewhere relieves the node while the leaking workload is fixed.",
+				Command:     "kubectl drain " + node + " --ignore-daemonsets --delete-emptydir-data",
+			},
+		}
 	case "DeploymentRolloutFailed":
-		target := failure.Deployment
+		target := deploymentName(failure)
 		if strings.TrimSpace(target) == "" {
 			target = "<deployment-name>"
 		}
@@ -947,8 +1624,832 @@ func buildFixSuggestions(failureType string, failure k8s.PodFailure, evidence []
 	return nil
 }
 
-func missingResourceName(evidence []string, prefix string) string {
-	for _, e := range evidence {
+// workloadLabel names the failing pod's owner, e.g. "StatefulSet db".
+func workloadLabel(failure k8s.PodFailure) string {
+	return strings.TrimSpace(failure.WorkloadKind + " " + failure.WorkloadName)
+}
+
+// workloadSubject is workloadLabel for the start of a sentence, falling back
+// to the pod when its owner is unknown.
+func workloadSubject(failure k8s.PodFailure) string {
+	if failure.WorkloadName == "" {
+		return "Pod " + failure.Name
+	}
+	return workloadLabel(failure)
+}
+
+// workloadKind is the owner's kind for prose, or "workload" when unknown.
+func workloadKind(failure k8s.PodFailure) string {
+	return defaultValue(failure.WorkloadKind, "workload")
+}
+
+// workloadRef is the kubectl reference to the failing pod's owner, e.g.
+// "statefulset/db", falling back to the pod itself.
+func workloadRef(failure k8s.PodFailure) string {
+	if failure.WorkloadKind == "" || failure.WorkloadName == "" {
+		return "pod/" + failure.Name
+	}
+	return strings.ToLower(failure.WorkloadKind) + "/" + failure.WorkloadName
+}
+
+// evictionEvidence lists what the kubelet's eviction message measured and
+// how many of the workload's pods were evicted.
+func evictionEvidence(failure k8s.PodFailure) []string {
+	e := failure.Eviction
+	if e == nil {
+		return nil
+	}
+	evidence := make([]string, 0, 8)
+	if e.Resource != "" {
+		evidence = append(evidence, "Evicted for: "+e.Resource)
+	}
+	if e.Scope != "" {
+		evidence = append(evidence, "Eviction scope: "+e.Scope)
+	}
+	if e.Volume != "" {
+		evidence = append(evidence, "emptyDir volume: "+e.Volume)
+	}
+	if e.Usage != "" {
+		evidence = append(evidence, "Usage: "+e.Usage+" (request "+defaultValue(e.Request, "none")+")")
+	}
+	if e.Limit != "" {
+		evidence = append(evidence, "Limit: "+e.Limit)
+	}
+	if e.Threshold != "" {
+		evidence = append(evidence, "Node eviction threshold: "+e.Threshold+", available "+defaultValue(e.Available, "unknown"))
+	}
+	if e.EvictedCount > 1 {
+		evidence = append(evidence, "Evicted pods of "+defaultValue(workloadLabel(failure), "the workload")+": "+itoa(e.EvictedCount))
+	}
+	return evidence
+}
+
+func evictionCause(failure k8s.PodFailure, e k8s.EvictionStatus) string {
+	var cause string
+	switch e.Scope {
+	case k8s.EvictionEmptyDirLimit:
+		cause = "emptyDir volume " + e.Volume + " outgrew its sizeLimit of " + e.Limit + " and the kubelet evicted the pod"
+	case k8s.EvictionContainerLimit:
+		cause = "Container " + defaultValue(e.Container, "of the pod") + " wrote more to local disk than its ephemeral-storage limit"
+		if e.Limit != "" {
+			cause += " of " + e.Limit
+		}
+		cause += " and the kubelet evicted the pod"
+	case k8s.EvictionPodLimit:
+		cause = "The pod's ephemeral storage exceeded its containers' total limit of " + e.Limit + " and the kubelet evicted it"
+	default:
+		cause = "Node " + defaultValue(failure.NodeName, "of the pod") + " ran low on " + defaultValue(e.Resource, "a resource")
+		if e.Available != "" && e.Threshold != "" {
+			cause += " (" + e.Available + " available, eviction threshold " + e.Threshold + ")"
+		}
+		cause += " and the kubelet evicted the pod"
+		if e.Container != "" && e.Usage != "" {
+			cause += "; container " + e.Container + " was using " + e.Usage + " against a request of " + e.Request + ", more than any other pod"
+		}
+	}
+	if e.EvictedCount > 1 && failure.WorkloadName != "" {
+		cause += " (" + itoa(e.EvictedCount) + " pods of " + workloadLabel(failure) + " evicted)"
+	}
+	return cause
+}
+
+func evictionFixes(failure k8s.PodFailure) []FixSuggestion {
+	ns := failure.Namespace
+	e := failure.Eviction
+	if e == nil {
+		e = &k8s.EvictionStatus{}
+	}
+	container := defaultValue(e.Container, "<container-name>")
+	fixes := make([]FixSuggestion, 0, 3)
+
+	switch {
+	case e.Scope == k8s.EvictionEmptyDirLimit:
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Size emptyDir " + e.Volume + " for its real usage",
+			Explanation: "Raise sizeLimit if the data is expected, or clean up what the pod writes there.",
+			Command:     "volumes:\n  - name: " + e.Volume + "\n    emptyDir:\n      sizeLimit: " + doubledQuantity(e.Limit, "2Gi"),
+		})
+	case e.Resource == "memory":
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Request the memory container " + container + " uses",
+			Explanation: "Under node memory pressure the kubelet evicts pods using the most memory above their requests first.",
+			Command:     "resources:\n  requests:\n    memory: " + defaultValue(e.Usage, "512Mi") + "\n  limits:\n    memory: " + doubledQuantity(e.Usage, "1Gi"),
+		})
+	case e.Resource == "" || e.Resource == "ephemeral-storage" || strings.HasPrefix(e.Resource, "nodefs") || strings.HasPrefix(e.Resource, "imagefs"):
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Set ephemeral-storage requests and limits on " + container,
+			Explanation: "A request reserves local disk for the container; a limit evicts only this pod when it writes too much, before the node runs out.",
+			Command:     "resources:\n  requests:\n    ephemeral-storage: " + defaultValue(e.Usage, "1Gi") + "\n  limits:\n    ephemeral-storage: " + doubledQuantity(defaultValue(e.Limit, e.Usage), "2Gi"),
+		})
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Cap scratch space with emptyDir sizeLimit",
+			Explanation: "Move temporary files to an emptyDir with a sizeLimit, and keep container logs and writable layers small.",
+			Command:     "volumes:\n  - name: scratch\n    emptyDir:\n      sizeLimit: 1Gi",
+		})
+	}
+	if e.EvictedCount > 1 {
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Clean up evicted pods",
+			Explanation: "Evicted pods are kept until garbage collection; removing them clears the churn once the cause is fixed.",
+			Command:     "kubectl -n " + ns + " delete pods --field-selector status.phase=Failed",
+		})
+	}
+	return fixes
+}
+
+// doubledQuantity doubles a resource quantity such as "512Mi", falling back
+// when it cannot be parsed.
+func doubledQuantity(quantity, fallback string) string {
+	q, err := resource.ParseQuantity(quantity)
+	if err != nil || q.IsZero() {
+		return fallback
+	}
+	q.Add(q.DeepCopy())
+	return q.String()
+}
+
+// startErrorEvidence lists the object, key, image or executable the
+// kubelet's message names.
+func startErrorEvidence(se *k8s.StartError) []string {
+	if se == nil {
+		return nil
+	}
+	switch {
+	case se.Key != "":
+		return []string{"Missing key: " + se.Key + " in " + se.Kind + " " + se.Name}
+	case (se.Kind == "ConfigMap" || se.Kind == "Secret") && se.Name != "":
+		return []string{se.Kind + " not found: " + se.Name}
+	case se.Kind == "Image" && se.Name != "":
+		return []string{"Image reference: " + se.Name + " (" + se.Reason + ")"}
+	case se.Kind == "Executable" && se.Name != "":
+		return []string{"Executable: " + se.Name + " (" + se.Reason + ")"}
+	case se.Kind == "Mount" && se.Name != "":
+		return []string{"Mount path: " + se.Name + " (" + se.Reason + ")"}
+	case se.Kind == "SecurityContext":
+		evidence := []string{"runAsNonRoot check failed: " + se.Reason}
+		if se.Name != "" {
+			evidence = append(evidence, "Image user: "+se.Name)
+		}
+		return evidence
+	case se.Reason != "":
+		return []string{"Runtime error: " + se.Reason}
+	}
+	return nil
+}
+
+func startErrorCause(failureType string, failure k8s.PodFailure, se k8s.StartError) string {
+	container := defaultValue(failure.Container, "of the pod")
+	node := defaultValue(failure.NodeName, "of the pod")
+	switch {
+	case se.Key != "":
+		return workloadSubject(failure) + " reads key \"" + se.Key + "\" from " + se.Kind + " \"" + se.Name + "\", which has no such key"
+	case (se.Kind == "ConfigMap" || se.Kind == "Secret") && se.Name != "":
+		return workloadSubject(failure) + " references " + se.Kind + " \"" + se.Name + "\" which does not exist in namespace " + failure.Namespace
+	case se.Kind == "SecurityContext" && se.Name != "":
+		return "Container " + container + " sets runAsNonRoot, but its image runs as user \"" + se.Name + "\", which the kubelet cannot verify is non-root"
+	case se.Kind == "SecurityContext":
+		return "Container " + container + " sets runAsNonRoot, but its image runs as root"
+	case failureType == "ErrImageNeverPull":
+		return "Image " + defaultValue(se.Name, failure.Image) + " is not present on node " + node + " and imagePullPolicy: Never forbids pulling it"
+	case se.Kind == "Image":
+		return "Image reference \"" + se.Name + "\" is not valid: " + se.Reason
+	case se.Kind == "Executable":
+		return "Container " + container + " could not start \"" + se.Name + "\": " + se.Reason
+	case se.Kind == "Mount":
+		return "The volume mount at " + se.Name + " in container " + container + " could not be set up: " + se.Reason
+	case failureType == "CreateContainerError":
+		return "The container runtime on node " + node + " could not create container " + container + ": " + se.Reason
+	case failureType == "RunContainerError":
+		return "The container runtime could not start container " + container + ": " + se.Reason
+	}
+	return ""
+}
+
+func startErrorFixes(failureType string, failure k8s.PodFailure) []FixSuggestion {
+	ns := failure.Namespace
+	se := failure.StartError
+	if se == nil {
+		se = &k8s.StartError{}
+	}
+	container := defaultValue(failure.Container, "<container-name>")
+	image := defaultValue(failure.Image, "<image>")
+
+	switch {
+	case se.Key != "" && se.Kind == "ConfigMap":
+		return []FixSuggestion{
+			{
+				Title:       "Add key " + se.Key + " to ConfigMap " + se.Name,
+				Explanation: "The container is created as soon as the key exists.",
+				Command:     "kubectl -n " + ns + " patch configmap " + se.Name + " --type merge -p '{\"data\":{\"" + se.Key + "\":\"<value>\"}}'",
+			},
+			{
+				Title:       "List the keys in ConfigMap " + se.Name,
+				Explanation: "Check whether the key is misspelled or was renamed.",
+				Command:     "kubectl -n " + ns + " get configmap " + se.Name + " -o jsonpath='{.data}'",
+			},
+			{
+				Title:       "Or make the reference optional",
+				Explanation: "With optional: true the container starts without the variable when the key is missing.",
+				Command:     "valueFrom:\n  configMapKeyRef:\n    name: " + se.Name + "\n    key: " + se.Key + "\n    optional: true",
+			},
+		}
+	case se.Key != "" && se.Kind == "Secret":
+		return []FixSuggestion{
+			{
+				Title:       "Add key " + se.Key + " to Secret " + se.Name,
+				Explanation: "stringData is encoded by the API server and merged into the existing data.",
+				Command:     "kubectl -n " + ns + " patch secret " + se.Name + " -p '{\"stringData\":{\"" + se.Key + "\":\"<value>\"}}'",
+			},
+			{
+				Title:       "List the keys in Secret " + se.Name,
+				Explanation: "Check whether the key is misspelled or was renamed; values are not printed.",
+				Command:     "kubectl -n " + ns + " describe secret " + se.Name,
+			},
+			{
+				Title:       "Or make the reference optional",
+				Explanation: "With optional: true the container starts without the variable when the key is missing.",
+				Command:     "valueFrom:\n  secretKeyRef:\n    name: " + se.Name + "\n    key: " + se.Key + "\n    optional: true",
+			},
+		}
+	case se.Kind == "ConfigMap" && se.Name != "":
+		return []FixSuggestion{
+			{
+				Title:       "Create ConfigMap " + se.Name,
+				Explanation: "Create it in namespace " + ns + ", or fix the name the " + workloadKind(failure) + " references.",
+				Command:     "kubectl -n " + ns + " create configmap " + se.Name + " --from-env-file=config.env",
+			},
+			{
+				Title:       "Compare with existing ConfigMaps",
+				Explanation: "A typo or a ConfigMap created in another namespace looks the same to the kubelet.",
+				Command:     "kubectl -n " + ns + " get configmaps",
+			},
+		}
+	case se.Kind == "Secret" && se.Name != "":
+		return []FixSuggestion{
+			{
+				Title:       "Create Secret " + se.Name,
+				Explanation: "Create it in namespace " + ns + ", or fix the name the " + workloadKind(failure) + " references.",
+				Command:     "kubectl -n " + ns + " create secret generic " + se.Name + " --from-literal=<key>=<value>",
+			},
+			{
+				Title:       "Compare with existing Secrets",
+				Explanation: "A typo or a Secret created in another namespace looks the same to the kubelet.",
+				Command:     "kubectl -n " + ns + " get secrets",
+			},
+		}
+	case se.Kind == "SecurityContext":
+		return []FixSuggestion{
+			{
+				Title:       "Run " + container + " as a numeric non-root user",
+				Explanation: "runAsUser tells the kubelet which user to run as, so it no longer relies on the image's USER.",
+				Command:     "securityContext:\n  runAsNonRoot: true\n  runAsUser: 10001",
+			},
+			{
+				Title:       "Set a numeric USER in the image",
+				Explanation: "The kubelet can only verify a numeric user ID against runAsNonRoot.",
+				Command:     "USER 10001",
+			},
+		}
+	case failureType == "InvalidImageName":
+		return []FixSuggestion{{
+			Title:       "Fix the image reference of " + container,
+			Explanation: "Repository names must be lowercase, have no scheme such as https://, and carry the tag after a single colon or the digest after @.",
+			Command:     "kubectl -n " + ns + " set image " + workloadRef(failure) + " " + container + "=<registry>/<repository>:<tag>",
+		}}
+	case failureType == "ErrImageNeverPull":
+		return []FixSuggestion{
+			{
+				Title:       "Allow the kubelet to pull " + image,
+				Explanation: "IfNotPresent uses a preloaded image when there is one and pulls it otherwise.",
+				Command:     "imagePullPolicy: IfNotPresent",
+			},
+			{
+				Title:       "Load the image onto the node",
+				Explanation: "With imagePullPolicy: Never every node that may run the pod needs the image; for kind or minikube load it from the local Docker daemon.",
+				Command:     "kind load docker-image " + image + "\nminikube image load " + image,
+			},
+		}
+	case se.Kind == "Executable":
+		fixes := []FixSuggestion{
+			{
+				Title:       "Check the command of " + container,
+				Explanation: "The command overrides the image's ENTRYPOINT and must exist in the image.",
+				Command:     containerQuery(failure, "command"),
+			},
+			{
+				Title:       "Look for " + se.Name + " in the image",
+				Explanation: "Distroless and scratch images have no shell or common tools; use an absolute path to a binary the image ships.",
+				Command:     "docker run --rm --entrypoint ls " + image + " -l " + se.Name,
+			},
+		}
+		if strings.Contains(se.Reason, "permission denied") {
+			fixes = append(fixes, FixSuggestion{
+				Title:       "Make " + se.Name + " executable",
+				Explanation: "The file exists but lacks the execute bit in the image.",
+				Command:     "RUN chmod +x " + se.Name,
+			})
+		}
+		return fixes
+	case se.Kind == "Mount":
+		return []FixSuggestion{{
+			Title:       "Check the volume mounts of " + container,
+			Explanation: "A subPath mount of a file onto a directory in the image, or of a directory onto a file, fails when the container starts.",
+			Command:     containerQuery(failure, "volumeMounts"),
+		}}
+	case failure.NodeName != "":
+		return []FixSuggestion{{
+			Title:       "Check the container runtime on node " + failure.NodeName,
+			Explanation: "Repeated create or start errors with timeouts or reserved names point to a slow or stuck runtime rather than the workload.",
+			Command:     "journalctl -u containerd -u kubelet --since \"30 min ago\"\ncrictl ps -a --name " + container,
+		}}
+	}
+	return nil
+}
+
+// startErrorCommands inspects the object the container failed on, or the
+// container's spec when the kubelet names none.
+func startErrorCommands(failureType string, failure k8s.PodFailure) []string {
+	ns := failure.Namespace
+	pod := failure.Name
+	se := failure.StartError
+	switch {
+	case se != nil && se.Kind == "ConfigMap" && se.Name != "":
+		return []string{"kubectl -n " + ns + " get configmap " + se.Name + " -o yaml"}
+	case se != nil && se.Kind == "Secret" && se.Name != "":
+		return []string{"kubectl -n " + ns + " describe secret " + se.Name}
+	case failureType == "InvalidImageName" || failureType == "ErrImageNeverPull":
+		return []string{"kubectl -n " + ns + " get pod " + pod + " -o jsonpath='{range .spec." + containerList(failure) + "[*]}{.name}{\"\\t\"}{.image}{\"\\t\"}{.imagePullPolicy}{\"\\n\"}{end}'"}
+	}
+	return []string{containerStatusQuery(failure)}
+}
+
+// podSpecPath is the JSONPath of the pod spec within the failing pod's owner.
+func podSpecPath(failure k8s.PodFailure) string {
+	switch {
+	case failure.WorkloadKind == "" || failure.WorkloadName == "":
+		return ".spec"
+	case failure.WorkloadKind == "CronJob":
+		return ".spec.jobTemplate.spec.template.spec"
+	}
+	return ".spec.template.spec"
+}
+
+// containerList is the pod spec field listing the failing container:
+// containers, initContainers or ephemeralContainers.
+func containerList(failure k8s.PodFailure) string {
+	switch failure.ContainerRole {
+	case k8s.ContainerRoleInit, k8s.ContainerRoleSidecar:
+		return "initContainers"
+	case k8s.ContainerRoleEphemeral:
+		return "ephemeralContainers"
+	}
+	return "containers"
+}
+
+// containerQuery reads one field of the failing container's spec from its
+// owner's pod template. Ephemeral containers exist only in the pod.
+func containerQuery(failure k8s.PodFailure, field string) string {
+	ref, path := workloadRef(failure), podSpecPath(failure)
+	if failure.ContainerRole == k8s.ContainerRoleEphemeral {
+		ref, path = "pod/"+failure.Name, ".spec"
+	}
+	container := defaultValue(failure.Container, "<container-name>")
+	return "kubectl -n " + failure.Namespace + " get " + ref + " -o jsonpath='{" + path + "." + containerList(failure) + "[?(@.name==\"" + container + "\")]." + field + "}'"
+}
+
+// containerStatusQuery reads the failing container's status from the pod.
+func containerStatusQuery(failure k8s.PodFailure) string {
+	statuses := "containerStatuses"
+	switch failure.ContainerRole {
+	case k8s.ContainerRoleInit, k8s.ContainerRoleSidecar:
+		statuses = "initContainerStatuses"
+	case k8s.ContainerRoleEphemeral:
+		statuses = "ephemeralContainerStatuses"
+	}
+	return "kubectl -n " + failure.Namespace + " get pod " + failure.Name + " -o jsonpath='{.status." + statuses + "[?(@.name==\"" + failure.Container + "\")]}'"
+}
+
+// initDependencyPatterns find the host an init container's command waits
+// for, e.g. "until nslookup db; do sleep 2; done" or "wait-for-it db:5432".
+var initDependencyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bnslookup\s+([\w.-]+)`),
+	regexp.MustCompile(`\bgetent\s+hosts\s+([\w.-]+)`),
+	regexp.MustCompile(`\bnc\s+(?:-\w+\s+)*([A-Za-z][\w.-]*)\s+(\d+)`),
+	regexp.MustCompile(`\bwait-for(?:-it)?(?:\.sh)?\s+(?:-\w+\s+)*([\w.-]+:\d+)`),
+	regexp.MustCompile(`\b(?:pg_isready|mysqladmin|redis-cli)\b.*?\s-h\s*([\w.-]+)`),
+	regexp.MustCompile(`https?://([\w.-]+(?::\d+)?)`),
+}
+
+// initDependency is the host, with its port when the command names one,
+// that an init container's command waits for.
+func initDependency(command string) string {
+	for _, re := range initDependencyPatterns {
+		if m := re.FindStringSubmatch(command); m != nil {
+			if len(m) > 2 && m[2] != "" {
+				return m[1] + ":" + m[2]
+			}
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// dependencyService maps a dependency host to the Service and namespace it
+// names: "db", "db.data" and "db.data.svc.cluster.local" are in-cluster,
+// anything else is external and returns "".
+func dependencyService(dependency, namespace string) (string, string) {
+	host, _, _ := strings.Cut(dependency, ":")
+	labels := strings.Split(host, ".")
+	switch {
+	case len(labels) == 1:
+		return labels[0], namespace
+	case len(labels) == 2 || labels[2] == "svc":
+		return labels[0], labels[1]
+	}
+	return "", ""
+}
+
+// containerRoleEvidence says what the failing container is within its pod
+// and, for init containers, the pod status kubectl shows.
+func containerRoleEvidence(failure k8s.PodFailure) []string {
+	switch failure.ContainerRole {
+	case k8s.ContainerRoleInit:
+		evidence := []string{"Container role: init container", "Pod status: " + initPodStatus(failure)}
+		if failure.InitProgress != "" {
+			evidence = append(evidence, "Init containers completed: "+failure.InitProgress)
+		}
+		return evidence
+	case k8s.ContainerRoleSidecar:
+		return []string{"Container role: sidecar (init container with restartPolicy: Always)"}
+	case k8s.ContainerRoleEphemeral:
+		return []string{"Container role: ephemeral container"}
+	}
+	return nil
+}
+
+// initPodStatus is the pod STATUS kubectl shows while an init container
+// fails or runs, e.g. "Init:CrashLoopBackOff" or "Init:1/3".
+func initPodStatus(failure k8s.PodFailure) string {
+	switch {
+	case failure.WaitingReason != "" && failure.WaitingReason != "PodInitializing":
+		return "Init:" + failure.WaitingReason
+	case failure.TerminatedReason != "" && failure.ExitCode != 0:
+		return "Init:" + failure.TerminatedReason
+	case failure.ExitCode != 0:
+		return "Init:ExitCode:" + itoa32(failure.ExitCode)
+	}
+	return "Init:" + defaultValue(failure.InitProgress, "0/1")
+}
+
+// initPending reports whether some init containers have not completed, from
+// an InitProgress such as "1/3".
+func initPending(progress string) bool {
+	done, total, ok := strings.Cut(progress, "/")
+	return ok && done != total
+}
+
+// withContainerRole adds what the container's role means for the pod to a
+// container failure's cause: a failing init container holds the whole pod
+// in Init, a sidecar holds back the app containers until it has started,
+// and an ephemeral container leaves the pod's own containers alone.
+func withContainerRole(failureType string, failure k8s.PodFailure, cause string) string {
+	if failure.Container == "" || failureType == "InitContainerBlocked" || isNodeFailureType(failureType) {
+		return cause
+	}
+	switch failure.ContainerRole {
+	case k8s.ContainerRoleInit:
+		return cause + "; init container " + failure.Container + " must complete before the app containers start, so the pod stays in " + initPodStatus(failure)
+	case k8s.ContainerRoleSidecar:
+		if initPending(failure.InitProgress) {
+			return cause + "; " + failure.Container + " is a sidecar, so the app containers do not start until it is running"
+		}
+		return cause + "; " + failure.Container + " is a sidecar, restarted alongside the running app containers"
+	case k8s.ContainerRoleEphemeral:
+		return cause + "; " + failure.Container + " is an ephemeral debug container, so the pod's own containers are unaffected"
+	}
+	return cause
+}
+
+func initBlockedCause(failure k8s.PodFailure) string {
+	container := defaultValue(failure.Container, "of the pod")
+	status := initPodStatus(failure)
+	dependency := initDependency(failure.ContainerCommand)
+	if dependency == "" {
+		return "Init container " + container + " has not completed, so the pod stays in " + status + " and its app containers never start"
+	}
+	if svc, svcNamespace := dependencyService(dependency, failure.Namespace); svc != "" {
+		return "Init container " + container + " is waiting for " + dependency + " (Service " + svc + " in namespace " + svcNamespace + ") and the pod stays in " + status + "; the Service is missing, has no ready endpoints, or is not reachable from the pod"
+	}
+	return "Init container " + container + " is waiting for " + dependency + ", which is not reachable from the pod, and the pod stays in " + status
+}
+
+func initBlockedFixes(failure k8s.PodFailure) []FixSuggestion {
+	ns := failure.Namespace
+	container := defaultValue(failure.Container, "<container-name>")
+	fixes := make([]FixSuggestion, 0, 4)
+	dependency := initDependency(failure.ContainerCommand)
+	host, port, _ := strings.Cut(dependency, ":")
+	if svc, svcNamespace := dependencyService(dependency, ns); svc != "" {
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Check that Service " + svc + " has ready endpoints",
+			Explanation: "The init container waits until " + dependency + " answers; a missing Service, or one whose pods are not ready, never does.",
+			Command:     "kubectl -n " + svcNamespace + " get service,endpoints " + svc,
+		})
+	}
+	if host != "" {
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Resolve " + host + " from namespace " + ns,
+			Explanation: "Short names resolve only within the same namespace; use <service>.<namespace> for a Service elsewhere.",
+			Command:     "kubectl -n " + ns + " run dns-check --rm -it --restart=Never --image=busybox:1.36 -- nslookup " + host,
+		})
+		wait := "until nslookup " + host + "; do sleep 2; done"
+		if port != "" {
+			wait = "until nc -z " + host + " " + port + "; do sleep 2; done"
+		}
+		fixes = append(fixes, FixSuggestion{
+			Title:       "Bound the wait in " + container,
+			Explanation: "A timeout makes the init container fail visibly instead of holding the pod in Init indefinitely.",
+			Command:     "timeout 300 sh -c '" + wait + "'",
+		})
+	}
+	fixes = append(fixes, FixSuggestion{
+		Title:       "Read the output of " + container,
+		Explanation: "The init container's log shows what it is waiting for and what it gets back.",
+		Command:     "kubectl -n " + ns + " logs " + failure.Name + " -c " + container,
+	})
+	return fixes
+}
+
+// volumeEvidence lists the claim, volume and class chain of each claim.
+func volumeEvidence(volumes []k8s.VolumeStatus) []string {
+	evidence := make([]string, 0, 6*len(volumes))
+	for _, vol := range volumes {
+		evidence = append(evidence, "Claim: "+vol.Claim+" ("+defaultValue(vol.ClaimPhase, "unknown")+")")
+		if len(vol.AccessModes) > 0 {
+			evidence = append(evidence, "Claim "+vol.Claim+" access modes: "+strings.Join(vol.AccessModes, ", "))
+		}
+		if vol.PersistentVolume != "" {
+			evidence = append(evidence, "PersistentVolume: "+vol.PersistentVolume)
+		}
+		if vol.Driver != "" {
+			evidence = append(evidence, "CSI driver: "+vol.Driver)
+		}
+		switch {
+		case vol.StorageClassMissing:
+			evidence = append(evidence, "StorageClass: "+vol.StorageClass+" (not found)")
+		case vol.StorageClass != "":
+			evidence = append(evidence, "StorageClass: "+vol.StorageClass+" (provisioner "+defaultValue(vol.Provisioner, "unknown")+", "+defaultValue(vol.BindingMode, "Immediate")+")")
+		case vol.ClaimPhase != "Missing" && vol.ClaimPhase != "":
+			evidence = append(evidence, "StorageClass: none set on claim "+vol.Claim)
+		}
+		if len(vol.Zones) > 0 {
+			evidence = append(evidence, "Volume zones: "+strings.Join(vol.Zones, ", "))
+		}
+		if vol.ZoneConflict != "" {
+			evidence = append(evidence, "Node zone: "+vol.ZoneConflict+" (outside the volume's zones)")
+		}
+		for _, event := range vol.ClaimEvents {
+			evidence = append(evidence, "Claim event: "+event)
+		}
+	}
+	return evidence
+}
+
+// problemVolume picks the claim a storage failure is about: the first
+// unbound claim for PVCUnbound, the first zone-pinned volume for a zone
+// mismatch, and otherwise the first bound volume.
+func problemVolume(failureType string, failure k8s.PodFailure) (k8s.VolumeStatus, bool) {
+	for _, vol := range failure.Volumes {
+		switch failureType {
+		case "PVCUnbound":
+			if vol.ClaimPhase != "Bound" {
+				return vol, true
+			}
+		case "VolumeZoneMismatch":
+			if vol.ZoneConflict != "" {
+				return vol, true
+			}
+		default:
+			if vol.PersistentVolume != "" {
+				return vol, true
+			}
+		}
+	}
+	if failureType == "VolumeZoneMismatch" {
+		for _, vol := range failure.Volumes {
+			if len(vol.Zones) > 0 {
+				return vol, true
+			}
+		}
+	}
+	if len(failure.Volumes) > 0 && failureType != "PVCUnbound" {
+		return failure.Volumes[0], true
+	}
+	return k8s.VolumeStatus{}, false
+}
+
+// volumeLabel names a volume by its PersistentVolume and claim, e.g.
+// "Volume pvc-1a2b (claim data-db-0)".
+func volumeLabel(vol k8s.VolumeStatus) string {
+	if vol.PersistentVolume == "" {
+		return "Claim " + vol.Claim
+	}
+	return "Volume " + vol.PersistentVolume + " (claim " + vol.Claim + ")"
+}
+
+func unboundClaimCause(vol k8s.VolumeStatus) string {
+	claim := "PersistentVolumeClaim " + vol.Claim
+	switch {
+	case vol.ClaimPhase == "Missing":
+		return claim + " does not exist, so the pod cannot start"
+	case vol.ClaimPhase == "Lost":
+		return claim + " lost its PersistentVolume " + vol.PersistentVolume
+	case vol.StorageClassMissing:
+		return claim + " requests StorageClass " + vol.StorageClass + ", which does not exist"
+	}
+	for _, event := range vol.ClaimEvents {
+		if strings.HasPrefix(event, "ProvisioningFailed") {
+			return "Provisioner " + defaultValue(vol.Provisioner, "of StorageClass "+vol.StorageClass) + " could not create a volume for " + claim + ": " + strings.TrimPrefix(event, "ProvisioningFailed: ")
+		}
+	}
+	if vol.StorageClass == "" {
+		return claim + " sets no StorageClass and no existing PersistentVolume matches it"
+	}
+	return claim + " is still Pending; StorageClass " + vol.StorageClass + " has not provided a volume"
+}
+
+// provisionerHint is the part of a provisioner or driver name most likely to
+// appear in its pod names, e.g. "ebs" for "ebs.csi.aws.com".
+func provisionerHint(name string) string {
+	if name == "" {
+		return "csi"
+	}
+	name = strings.TrimPrefix(name, "kubernetes.io/")
+	if first, _, ok := strings.Cut(name, "."); ok {
+		return first
+	}
+	return name
+}
+
+func isStorageFailureType(failureType string) bool {
+	switch failureType {
+	case "PVCUnbound", "FailedAttachVolume", "VolumeMultiAttach", "VolumeMountFailed", "VolumeZoneMismatch":
+		return true
+	}
+	return false
+}
+
+// isStartFailureType reports whether the container could not be created or
+// started, the waiting reasons that carry a StartError.
+func isStartFailureType(failureType string) bool {
+	switch failureType {
+	case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError", "ErrImageNeverPull":
+		return true
+	}
+	return false
+}
+
+// isNodeFailure reports whether the failure is the node itself rather than a
+// pod on it.
+func isNodeFailure(failure k8s.PodFailure) bool {
+	return failure.Namespace == "" && failure.NodeName != "" && failure.Name == failure.NodeName
+}
+
+// deploymentName is the owning Deployment, or "" for other workload kinds.
+func deploymentName(failure k8s.PodFailure) string {
+	if failure.WorkloadKind != "Deployment" {
+		return ""
+	}
+	return failure.WorkloadName
+}
+
+// batchEvidence lists the Job and CronJob settings behind a batch failure.
+func batchEvidence(b *k8s.BatchStatus) []string {
+	if b == nil {
+		return nil
+	}
+	evidence := make([]string, 0, 10)
+	if b.Job != "" {
+		evidence = append(evidence, "Job: "+b.Job)
+	}
+	if b.BackoffLimit != nil {
+		evidence = append(evidence, "Backoff limit: "+itoa32(*b.BackoffLimit))
+	}
+	if b.Failed > 0 {
+		evidence = append(evidence, "Failed pods: "+itoa32(b.Failed))
+	}
+	if b.ActiveDeadlineSeconds != nil {
+		evidence = append(evidence, "Active deadline: "+strconv.FormatInt(*b.ActiveDeadlineSeconds, 10)+"s")
+	}
+	if b.StartTime != nil {
+		evidence = append(evidence, "Job started: "+b.StartTime.Format(time.RFC3339))
+	}
+	if b.Schedule != "" {
+		schedule := b.Schedule
+		if b.TimeZone != "" {
+			schedule += " (" + b.TimeZone + ")"
+		}
+		evidence = append(evidence, "CronJob schedule: "+schedule)
+	}
+	if b.ConcurrencyPolicy != "" {
+		evidence = append(evidence, "Concurrency policy: "+b.ConcurrencyPolicy)
+	}
+	if b.StartingDeadlineSeconds != nil {
+		evidence = append(evidence, "Starting deadline: "+strconv.FormatInt(*b.StartingDeadlineSeconds, 10)+"s")
+	}
+	if b.LastScheduleTime != nil {
+		evidence = append(evidence, "Last scheduled run: "+b.LastScheduleTime.Format(time.RFC3339))
+	}
+	if b.MissedRun != nil {
+		evidence = append(evidence, "Missed run: "+b.MissedRun.Format(time.RFC3339))
+	}
+	if b.MissedRuns > 0 {
+		evidence = append(evidence, "Missed runs: "+missedRunsText(*b))
+	}
+	return evidence
+}
+
+func batchOrEmpty(failure k8s.PodFailure) k8s.BatchStatus {
+	if failure.Batch == nil {
+		return k8s.BatchStatus{}
+	}
+	return *failure.Batch
+}
+
+// backoffLimit is the Job's retry limit, defaulted as the API server does.
+func backoffLimit(b k8s.BatchStatus) int32 {
+	if b.BackoffLimit == nil {
+		return 6
+	}
+	return *b.BackoffLimit
+}
+
+func missedRunsText(b k8s.BatchStatus) string {
+	if b.MissedRuns >= 100 {
+		return "100+ runs"
+	}
+	if b.MissedRuns == 1 {
+		return "1 run"
+	}
+	return itoa(b.MissedRuns) + " runs"
+}
+
+// batchObjectRef is the kubectl reference to the Job or CronJob a batch
+// failure was reported on.
+func batchObjectRef(failureType string, failure k8s.PodFailure) string {
+	b := batchOrEmpty(failure)
+	if strings.HasPrefix(failureType, "CronJob") {
+		return "cronjob/" + defaultValue(b.CronJob, failure.Name)
+	}
+	return "job/" + defaultValue(b.Job, failure.Name)
+}
+
+// batchLogsCommand reads the failed pod's logs when the failure was reported
+// on one, and otherwise lets kubectl pick a pod of the Job.
+func batchLogsCommand(failure k8s.PodFailure) string {
+	ns := failure.Namespace
+	if failure.Container != "" {
+		return "kubectl -n " + ns + " logs " + failure.Name + " -c " + failure.Container
+	}
+	return "kubectl -n " + ns + " logs job/" + defaultValue(batchOrEmpty(failure).Job, failure.Name)
+}
+
+// jobSpecSnippet places a Job spec field where it belongs: in the Job, or
+// in the CronJob's job template.
+func jobSpecSnippet(b k8s.BatchStatus, field string) string {
+	if b.CronJob != "" {
+		return "spec:\n  jobTemplate:\n    spec:\n      " + field
+	}
+	return "spec:\n  " + field
+}
+
+// rerunSuggestion starts a fresh run once the cause is fixed. A CronJob run
+// can be started from its template; a failed Job has to be recreated.
+func rerunSuggestion(ns string, b k8s.BatchStatus) FixSuggestion {
+	if b.CronJob != "" {
+		return FixSuggestion{
+			Title:       "Trigger a run now",
+			Explanation: "Start a one-off Job from the CronJob's template instead of waiting for the next schedule.",
+			Command:     "kubectl -n " + ns + " create job " + b.CronJob + "-manual --from=cronjob/" + b.CronJob,
+		}
+	}
+	job := defaultValue(b.Job, "<job-name>")
+	return FixSuggestion{
+		Title:       "Re-run the Job",
+		Explanation: "A failed Job does not retry again; recreate it from its manifest once the cause is fixed.",
+		Command:     "kubectl -n " + ns + " delete job " + job + " && kubectl apply -f <job-manifest>.yaml",
+	}
+}
+
+func maxInt32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func missingResourceName(evidence []string, prefix string) string {
+	for _, e := range evidence {
 		if strings.HasPrefix(e, prefix) {
 			return strings.TrimPrefix(e, prefix)
 		}
@@ -960,11 +2461,11 @@ func categorizeFailure(failureType string) string {
 	switch failureType {
 	case "ImagePullBackOff":
 		return "Registry error"
-	case "ConfigMapMissing", "SecretMissing":
+	case "ConfigMapMissing", "SecretMissing", "CreateContainerConfigError", "InvalidImageName", "ErrImageNeverPull":
 		return "Configuration error"
-	case "CrashLoopBackOff":
+	case "CrashLoopBackOff", "RunContainerError", "InitContainerBlocked":
 		return "Application startup"
-	case "OOMKilled", "FailedScheduling", "PodPending":
+	case "OOMKilled", "FailedScheduling", "PodPending", "Evicted":
 		return "Resource constraint"
 	case "DNSLookupFailed", "NetworkTimeout":
 		return "Connectivity"
@@ -972,8 +2473,14 @@ func categorizeFailure(failureType string) string {
 		return "Registry error"
 	case "DeploymentRolloutFailed":
 		return "Rollout"
-	case "ReadinessProbeFailed", "LivenessProbeFailed":
+	case "ReadinessProbeFailed", "LivenessProbeFailed", "StartupProbeFailed":
 		return "Health check"
+	case "NonZeroExit", "JobBackoffLimitExceeded", "JobDeadlineExceeded", "CronJobMissedSchedule", "CronJobForbidStalled":
+		return "Batch job"
+	case "NodeNotReady", "NodeDiskPressure", "NodeMemoryPressure", "NodePIDPressure":
+		return "Node health"
+	case "PVCUnbound", "FailedAttachVolume", "VolumeMultiAttach", "VolumeMountFailed", "VolumeZoneMismatch":
+		return "Storage"
 	default:
 		return "Runtime issue"
 	}
@@ -993,9 +2500,22 @@ func buildQuickCommands(failureType string, failure k8s.PodFailure, evidence []s
 		"kubectl -n " + ns + " describe pod " + pod,
 		"kubectl -n " + ns + " get events --field-selector involvedObject.name=" + pod + " --sort-by=.lastTimestamp",
 	}
+	if failure.Batch != nil && failure.Container == "" {
+		// Reported on the Job or CronJob itself rather than on a pod.
+		commands[0] = "kubectl -n " + ns + " describe " + batchObjectRef(failureType, failure)
+	}
+	if isNodeFailure(failure) {
+		commands = []string{
+			"kubectl describe node " + failure.NodeName,
+			"kubectl get events -A --field-selector involvedObject.kind=Node,involvedObject.name=" + failure.NodeName + " --sort-by=.lastTimestamp",
+		}
+	}
+	if failure.WorkloadName != "" {
+		commands = append(commands, "kubectl -n "+ns+" describe "+workloadRef(failure))
+	}
 
 	switch failureType {
-	case "CrashLoopBackOff", "OOMKilled", "ReadinessProbeFailed", "LivenessProbeFailed":
+	case "CrashLoopBackOff", "OOMKilled", "ReadinessProbeFailed", "LivenessProbeFailed", "StartupProbeFailed":
 		if failure.Container != "" {
 			commands = append(commands,
 				"kubectl -n "+ns+" logs "+pod+" -c "+failure.Container+" --previous",
@@ -1033,7 +2553,7 @@ func buildQuickCommands(failureType string, failure k8s.PodFailure, evidence []s
 			"kubectl -n "+ns+" get networkpolicy",
 		)
 	case "DeploymentRolloutFailed":
-		target := failure.Deployment
+		target := deploymentName(failure)
 		if strings.TrimSpace(target) == "" {
 			target = "<deployment-name>"
 		}
@@ -1072,6 +2592,76 @@ func buildQuickCommands(failureType string, failure k8s.PodFailure, evidence []s
 		}
 	case "PodPending":
 		commands = append(commands, "kubectl -n "+ns+" get pod "+pod+" -o yaml")
+	case "NonZeroExit":
+		if failure.Container != "" {
+			commands = append(commands, "kubectl -n "+ns+" logs "+pod+" -c "+failure.Container)
+		} else {
+			commands = append(commands, "kubectl -n "+ns+" logs "+pod)
+		}
+	case "JobBackoffLimitExceeded", "JobDeadlineExceeded":
+		job := defaultValue(batchOrEmpty(failure).Job, "<job-name>")
+		commands = append(commands,
+			"kubectl -n "+ns+" get pods -l job-name="+job,
+			batchLogsCommand(failure),
+			"kubectl -n "+ns+" get job "+job+" -o jsonpath='{.spec.backoffLimit} {.spec.activeDeadlineSeconds}'",
+		)
+	case "CronJobMissedSchedule", "CronJobForbidStalled":
+		b := batchOrEmpty(failure)
+		commands = append(commands,
+			"kubectl -n "+ns+" get cronjob "+defaultValue(b.CronJob, pod),
+			"kubectl -n "+ns+" get jobs --sort-by=.metadata.creationTimestamp",
+		)
+		if failureType == "CronJobForbidStalled" && b.Job != "" {
+			commands = append(commands, "kubectl -n "+ns+" describe job/"+b.Job)
+		}
+	case "Evicted":
+		commands = append(commands, "kubectl -n "+ns+" get pods --field-selector status.phase=Failed -o wide")
+		if failure.NodeName != "" {
+			commands = append(commands, "kubectl get --raw /api/v1/nodes/"+failure.NodeName+"/proxy/stats/summary")
+		}
+	case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError", "ErrImageNeverPull":
+		commands = append(commands, startErrorCommands(failureType, failure)...)
+	case "InitContainerBlocked":
+		commands = append(commands, "kubectl -n "+ns+" logs "+pod+" -c "+defaultValue(failure.Container, "<container-name>"))
+		if svc, svcNamespace := dependencyService(initDependency(failure.ContainerCommand), ns); svc != "" {
+			commands = append(commands, "kubectl -n "+svcNamespace+" get endpoints "+svc)
+		}
+	case "PVCUnbound", "FailedAttachVolume", "VolumeMultiAttach", "VolumeMountFailed", "VolumeZoneMismatch":
+		vol, _ := problemVolume(failureType, failure)
+		commands = append(commands, "kubectl -n "+ns+" describe pvc "+defaultValue(vol.Claim, "<claim-name>"))
+		if vol.PersistentVolume != "" {
+			commands = append(commands, "kubectl describe pv "+vol.PersistentVolume)
+		}
+		switch failureType {
+		case "PVCUnbound":
+			if vol.StorageClass != "" && !vol.StorageClassMissing {
+				commands = append(commands, "kubectl get storageclass "+vol.StorageClass+" -o yaml")
+			} else {
+				commands = append(commands, "kubectl get storageclass")
+			}
+		case "FailedAttachVolume", "VolumeMultiAttach":
+			commands = append(commands, "kubectl get volumeattachments | grep "+defaultValue(vol.PersistentVolume, "<pv-name>"))
+		case "VolumeZoneMismatch":
+			commands = append(commands, "kubectl get nodes -L topology.kubernetes.io/zone")
+		}
+	case "NodeNotReady", "NodeDiskPressure", "NodeMemoryPressure", "NodePIDPressure":
+		node := defaultValue(failure.NodeName, "<node-name>")
+		commands = append(commands,
+			"kubectl get node "+node+" -o wide",
+			"kubectl get pods -A --field-selector spec.nodeName="+node+" -o wide",
+		)
+		if failureType == "NodeMemoryPressure" {
+			commands = append(commands, "kubectl top node "+node)
+		}
+	}
+	switch failure.ContainerRole {
+	case k8s.ContainerRoleInit, k8s.ContainerRoleSidecar:
+		commands = append(commands, "kubectl -n "+ns+" get pod "+pod+" -o jsonpath='{range .status.initContainerStatuses[*]}{.name}{\"\\t\"}{.state}{\"\\n\"}{end}'")
+	case k8s.ContainerRoleEphemeral:
+		commands = append(commands, containerStatusQuery(failure))
+	}
+	if failure.NodeName != "" {
+		commands = append(commands, "kubectl describe node "+failure.NodeName)
 	}
 
 	return uniqueStrings(commands)
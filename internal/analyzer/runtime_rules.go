@@ -27,10 +27,22 @@ func detectImagePull(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
 	return &DiagnosisDecision{FailureType: "ImagePullBackOff"}
 }
 
+func detectContainerStart(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
+	if !isStartFailureType(signal.FailureType) && signal.FailureType != "InitContainerBlocked" {
+		return nil
+	}
+	return &DiagnosisDecision{FailureType: signal.FailureType}
+}
+
 func detectOOM(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
-	if signal.FailureType == "OOMKilled" || signal.ExitCode == 137 {
+	if signal.FailureType == "OOMKilled" {
 		return &DiagnosisDecision{FailureType: "OOMKilled"}
 	}
+	if signal.ExitCode == 137 {
+		if source := sigkillSource(signal.ExitReason, signal.Events); source == killOOM || source == "" {
+			return &DiagnosisDecision{FailureType: "OOMKilled"}
+		}
+	}
 	combined := strings.ToLower(strings.Join(append([]string{signal.Message}, signal.Events...), "\n"))
 	if strings.Contains(combined, "oomkilled") || strings.Contains(combined, "out of memory") {
 		return &DiagnosisDecision{FailureType: "OOMKilled"}
@@ -39,7 +51,7 @@ func detectOOM(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
 }
 
 func detectProbeFailure(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
-	if signal.FailureType == "ReadinessProbeFailed" || signal.FailureType == "LivenessProbeFailed" {
+	if signal.FailureType == "ReadinessProbeFailed" || signal.FailureType == "LivenessProbeFailed" || signal.FailureType == "StartupProbeFailed" {
 		return &DiagnosisDecision{FailureType: signal.FailureType}
 	}
 	return nil
@@ -66,14 +78,24 @@ func detectRollout(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
 	return nil
 }
 
+func detectBatchFailure(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
+	switch signal.FailureType {
+	case "JobBackoffLimitExceeded", "JobDeadlineExceeded", "CronJobMissedSchedule", "CronJobForbidStalled":
+		return &DiagnosisDecision{FailureType: signal.FailureType}
+	}
+	return nil
+}
+
 func defaultRuntimeRules() []RuntimeRule {
 	// Ordered with lightweight infrastructure/runtime checks first.
 	return []RuntimeRule{
 		runtimeRuleFunc{evaluate: detectImagePull},
+		runtimeRuleFunc{evaluate: detectContainerStart},
 		runtimeRuleFunc{evaluate: detectScheduling},
 		runtimeRuleFunc{evaluate: detectRollout},
-		runtimeRuleFunc{evaluate: detectOOM},
+		runtimeRuleFunc{evaluate: detectBatchFailure},
 		runtimeRuleFunc{evaluate: detectProbeFailure},
+		runtimeRuleFunc{evaluate: detectOOM},
 		runtimeRuleFunc{evaluate: detectCrashLoop},
 		runtimeRuleFunc{evaluate: detectPending},
 	}
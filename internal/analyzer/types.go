@@ -6,26 +6,36 @@ type PodSignal struct {
 	Namespace    string
 	PodName      string
 	Container    string
+	Role         string // container role: app, init, sidecar or ephemeral
 	Image        string
 	RestartCount int32
 	ExitCode     int32
+	ExitReason   string // termination reason that came with ExitCode, e.g. OOMKilled or Error
 	Message      string
 	Events       []string
+
+	NodeName       string
+	NodeConditions []string
 }
 
 // WorkloadContext captures workload-level dependency context derived from the failing pod.
 type WorkloadContext struct {
-	Namespace          string
-	Deployment         string
-	DeploymentRevision string
-	ReplicaStatus      string
-	Image              string
-	ContainerCommand   string
-	ConfigMaps         []string
-	Secrets            []string
-	Services           []string
-	EnvVariables       []string
-	DependencyGraph    []string
+	Namespace         string
+	WorkloadKind      string
+	WorkloadName      string
+	WorkloadRevision  string
+	WorkloadReadiness string
+	NodeName          string
+	Image             string
+	ContainerCommand  string
+	ConfigMaps        []string
+	Secrets           []string
+	Services          []string
+	EnvVariables      []string
+	Claims            []string
+	PersistentVolumes []string
+	StorageClasses    []string
+	DependencyGraph   []string
 }
 
 // DiagnosisDecision is an optional override produced by validators/runtime rules.
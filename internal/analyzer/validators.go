@@ -14,6 +14,50 @@ type SecretValidator struct{}
 
 type ServiceDependencyValidator struct{}
 
+// InitDependencyValidator diagnoses an init container that runs or
+// crash-loops while waiting for a dependency as the pod being blocked on it.
+type InitDependencyValidator struct{}
+
+// NodeHealthValidator attributes pod failures on an unhealthy node to the
+// node, so the symptoms of one bad node diagnose as that node.
+type NodeHealthValidator struct{}
+
+// ExitCodeValidator tells a SIGKILL sent by the kubelet apart from an OOM
+// kill: both end the container with exit code 137.
+type ExitCodeValidator struct{}
+
+// nodeConditionSymptoms lists, for each node condition in order of
+// precedence, the pod failures it plausibly causes. A NotReady node takes
+// every pod failure except those that cannot depend on the node.
+var nodeConditionSymptoms = []struct {
+	condition   string
+	failureType string
+	symptoms    map[string]bool
+}{
+	{"NotReady", "NodeNotReady", nil},
+	{"DiskPressure", "NodeDiskPressure", map[string]bool{"ImagePullBackOff": true, "PodPending": true, "CrashLoopBackOff": true, "CreateContainerError": true, "NonZeroExit": true, "JobBackoffLimitExceeded": true}},
+	{"MemoryPressure", "NodeMemoryPressure", map[string]bool{"OOMKilled": true, "CrashLoopBackOff": true, "LivenessProbeFailed": true, "ReadinessProbeFailed": true, "StartupProbeFailed": true, "NonZeroExit": true, "JobBackoffLimitExceeded": true}},
+	{"PIDPressure", "NodePIDPressure", map[string]bool{"CrashLoopBackOff": true, "PodPending": true, "CreateContainerError": true, "RunContainerError": true, "NonZeroExit": true, "JobBackoffLimitExceeded": true}},
+}
+
+// nodeIndependentFailures are never attributed to the pod's node: they do not
+// depend on it, like a missing ConfigMap key or a malformed image name, or,
+// like Evicted, their own diagnosis already names the node's condition.
+var nodeIndependentFailures = map[string]bool{
+	"FailedScheduling":           true,
+	"ConfigMapMissing":           true,
+	"SecretMissing":              true,
+	"DeploymentRolloutFailed":    true,
+	"CronJobMissedSchedule":      true,
+	"CronJobForbidStalled":       true,
+	"PVCUnbound":                 true,
+	"VolumeZoneMismatch":         true,
+	"Evicted":                    true,
+	"CreateContainerConfigError": true,
+	"InvalidImageName":           true,
+	"ErrImageNeverPull":          true,
+}
+
 func (v ConfigMapValidator) Name() string { return "configmap-validator" }
 
 func (v ConfigMapValidator) Validate(signal PodSignal, ctx WorkloadContext) *DiagnosisDecision {
@@ -46,12 +90,77 @@ func (v SecretValidator) Validate(signal PodSignal, ctx WorkloadContext) *Diagno
 	return nil
 }
 
+func (v NodeHealthValidator) Name() string { return "node-health-validator" }
+
+func (v NodeHealthValidator) Validate(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
+	if isNodeFailureType(signal.FailureType) {
+		return &DiagnosisDecision{FailureType: signal.FailureType}
+	}
+	if len(signal.NodeConditions) == 0 || nodeIndependentFailures[signal.FailureType] {
+		return nil
+	}
+	for _, c := range nodeConditionSymptoms {
+		if !containsString(signal.NodeConditions, c.condition) || (c.symptoms != nil && !c.symptoms[signal.FailureType]) {
+			continue
+		}
+		if c.condition == "NotReady" {
+			return &DiagnosisDecision{
+				FailureType: c.failureType,
+				LikelyCause: "Node " + signal.NodeName + " is NotReady; the " + signal.FailureType + " of pod " + signal.PodName + " is a symptom of the node, not of the workload",
+				Confidence:  "high",
+			}
+		}
+		return &DiagnosisDecision{
+			FailureType: c.failureType,
+			LikelyCause: "Node " + signal.NodeName + " reports " + c.condition + ", which likely caused the " + signal.FailureType + " of pod " + signal.PodName,
+			Confidence:  "medium",
+		}
+	}
+	return nil
+}
+
+func (v ExitCodeValidator) Name() string { return "exit-code-validator" }
+
+func (v ExitCodeValidator) Validate(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
+	if signal.ExitCode != 137 || (signal.FailureType != "OOMKilled" && signal.FailureType != "CrashLoopBackOff") {
+		return nil
+	}
+	switch sigkillSource(signal.ExitReason, signal.Events) {
+	case killLiveness:
+		return &DiagnosisDecision{FailureType: "LivenessProbeFailed"}
+	case killStartup:
+		return &DiagnosisDecision{FailureType: "StartupProbeFailed"}
+	case killPreStop:
+		return &DiagnosisDecision{FailureType: "CrashLoopBackOff"}
+	}
+	return nil
+}
+
+func (v InitDependencyValidator) Name() string { return "init-dependency-validator" }
+
+func (v InitDependencyValidator) Validate(signal PodSignal, ctx WorkloadContext) *DiagnosisDecision {
+	if signal.Role != "init" {
+		return nil
+	}
+	switch {
+	case signal.FailureType == "InitContainerBlocked":
+		return &DiagnosisDecision{FailureType: signal.FailureType}
+	case signal.FailureType == "CrashLoopBackOff" && initDependency(ctx.ContainerCommand) != "":
+		return &DiagnosisDecision{FailureType: "InitContainerBlocked"}
+	}
+	return nil
+}
+
 func (v ServiceDependencyValidator) Name() string { return "service-dependency-validator" }
 
 func (v ServiceDependencyValidator) Validate(signal PodSignal, _ WorkloadContext) *DiagnosisDecision {
 	if signal.FailureType == "DNSLookupFailed" || signal.FailureType == "ImageRegistryDNSFailure" || signal.FailureType == "NetworkTimeout" {
 		return &DiagnosisDecision{FailureType: signal.FailureType}
 	}
+	if isStartFailureType(signal.FailureType) {
+		// A runtime "context deadline exceeded" is not a network timeout.
+		return nil
+	}
 
 	combined := strings.ToLower(strings.Join(append([]string{signal.Message}, signal.Events...), "\n"))
 	if (strings.Contains(combined, "lookup") && strings.Contains(combined, "no such host")) || strings.Contains(combined, "temporary failure in name resolution") {
@@ -79,3 +188,20 @@ func isRegistryDNSPattern(text string) bool {
 	}
 	return false
 }
+
+func isNodeFailureType(failureType string) bool {
+	switch failureType {
+	case "NodeNotReady", "NodeDiskPressure", "NodeMemoryPressure", "NodePIDPressure":
+		return true
+	}
+	return false
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,8 @@ import (
 	"kuberoot/internal/analyzer"
 	"kuberoot/internal/auth"
 	"kuberoot/internal/k8s" // Still needed for PodFailure type
+	"kuberoot/internal/redact"
+	"kuberoot/internal/store"
 )
 
 // AgentPayload is what the agent sends (struct here is fine, payload format matters)
@@ -21,13 +24,57 @@ type AgentPayload struct {
 	ClusterID string           `json:"clusterId"`
 	Timestamp time.Time        `json:"timestamp"`
 	Failures  []k8s.PodFailure `json:"failures"`
+
+	// Delta protocol fields; empty Mode means a legacy full report.
+	Mode         string   `json:"mode,omitempty"`
+	Sequence     int64    `json:"sequence,omitempty"`
+	BaseSequence int64    `json:"baseSequence,omitempty"`
+	Cleared      []string `json:"cleared,omitempty"`
+
+	// ReportID is stable across retries; the chunk fields are only set when a
+	// report is split to fit the body limit.
+	ReportID   string `json:"reportId,omitempty"`
+	ChunkIndex int    `json:"chunkIndex,omitempty"`
+	ChunkCount int    `json:"chunkCount,omitempty"`
+
+	// Filters is the pod filter set the agent is running with, if any.
+	Filters *k8s.FailureFilter `json:"filters,omitempty"`
+
+	// Leader identifies the agent replica that sent the report when the agent
+	// runs with leader election.
+	Leader string `json:"leader,omitempty"`
+
+	// ConfigVersion is the backend config version the agent is running, 0
+	// when it runs on local config only.
+	ConfigVersion int64 `json:"configVersion,omitempty"`
+
+	// Redactions counts what the agent scrubbed before sending, kept for
+	// audit.
+	Redactions *redact.Summary `json:"redactions,omitempty"`
 }
 
+// maxReportChunks bounds how many parts a single report may be split into.
+const maxReportChunks = 256
+
+// Report modes. "full" (or empty) re-diagnoses every failure; the others are
+// reconciled against the stored failure state for the cluster.
+const (
+	reportModeFull      = "full"
+	reportModeSnapshot  = "snapshot"
+	reportModeDelta     = "delta"
+	reportModeHeartbeat = "heartbeat"
+)
+
 // AgentReportResponse is what we return
 type AgentReportResponse struct {
-	Status  string `json:"status"`
-	ID      string `json:"id"`
-	Message string `json:"message,omitempty"`
+	Status         string `json:"status"`
+	ID             string `json:"id"`
+	Message        string `json:"message,omitempty"`
+	ResyncRequired bool   `json:"resyncRequired,omitempty"`
+
+	// ConfigVersion is the config version the cluster should be running;
+	// an agent on another version fetches its config again.
+	ConfigVersion int64 `json:"configVersion,omitempty"`
 }
 
 // AgentReport receives failure reports from cluster agents
@@ -48,6 +95,11 @@ func (h *Handler) AgentReport(w http.ResponseWriter, r *http.Request) {
 	// Parse payload
 	var payload AgentPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -57,13 +109,91 @@ func (h *Handler) AgentReport(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "clusterId required", http.StatusBadRequest)
 		return
 	}
+	if payload.ChunkCount > 1 {
+		if payload.ReportID == "" || payload.ChunkCount > maxReportChunks || payload.ChunkIndex < 0 || payload.ChunkIndex >= payload.ChunkCount {
+			http.Error(w, "invalid chunk header", http.StatusBadRequest)
+			return
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
+	if payload.ReportID != "" {
+		processed, err := h.store.ReportProcessed(ctx, orgID, payload.ClusterID, payload.ReportID)
+		if err != nil {
+			log.Printf("[ERROR] failed to check report %s: %v", payload.ReportID, err)
+			http.Error(w, "failed to check report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if processed {
+			writeAgentReportResponse(w, AgentReportResponse{Status: "accepted", ID: payload.ClusterID, Message: "duplicate report ignored"})
+			return
+		}
+	}
+
+	// Hold chunks until the whole report has arrived, then process it as one.
+	if payload.ChunkCount > 1 {
+		assembled, complete, err := h.assembleReportChunk(ctx, orgID, payload)
+		if err != nil {
+			log.Printf("[ERROR] failed to store report chunk: %v", err)
+			http.Error(w, "failed to store report chunk: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !complete {
+			writeAgentReportResponse(w, AgentReportResponse{
+				Status:  "accepted",
+				ID:      payload.ClusterID,
+				Message: fmt.Sprintf("chunk %d/%d received", payload.ChunkIndex+1, payload.ChunkCount),
+			})
+			return
+		}
+		payload = assembled
+	}
+
+	// Decide which failures need diagnosing; delta-protocol reports only
+	// carry what changed since the last applied sequence.
+	toDiagnose := payload.Failures
+	var stateUpdate *store.FailureStateUpdate
+	switch payload.Mode {
+	case "", reportModeFull:
+	case reportModeSnapshot, reportModeDelta, reportModeHeartbeat:
+		state, err := h.store.LoadFailureState(ctx, orgID, payload.ClusterID)
+		if err != nil {
+			log.Printf("[ERROR] failed to load failure state: %v", err)
+			http.Error(w, "failed to load failure state: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if payload.Mode != reportModeSnapshot && payload.Sequence == state.Sequence {
+			// Retried delivery of a report we already applied.
+			writeAgentReportResponse(w, AgentReportResponse{Status: "accepted", ID: payload.ClusterID, Message: "duplicate report ignored"})
+			return
+		}
+		if payload.Mode != reportModeSnapshot && payload.BaseSequence != state.Sequence {
+			log.Printf("[AGENT] org=%s cluster=%s %s base=%d stored=%d, requesting snapshot", orgID, payload.ClusterID, payload.Mode, payload.BaseSequence, state.Sequence)
+			if err := h.store.RegisterCluster(ctx, orgID, payload.ClusterID); err != nil {
+				log.Printf("[WARN] register cluster on resync: %v", err)
+			}
+			writeAgentReportResponse(w, AgentReportResponse{Status: "accepted", ID: payload.ClusterID, Message: "state out of sync", ResyncRequired: true})
+			return
+		}
+
+		var reconcileErr error
+		toDiagnose, stateUpdate, reconcileErr = reconcileFailureState(state, payload)
+		if reconcileErr != nil {
+			http.Error(w, "failed to reconcile failure state: "+reconcileErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		stateUpdate.ReceivedAt = time.Now().UTC()
+	default:
+		http.Error(w, "unknown report mode: "+payload.Mode, http.StatusBadRequest)
+		return
+	}
+
 	// Run analyzer
-	diagnoses := analyzer.DiagnoseFailures(orgID, payload.ClusterID, payload.Failures)
-	log.Printf("[AGENT] org=%s cluster=%s failures=%d diagnoses=%d", orgID, payload.ClusterID, len(payload.Failures), len(diagnoses))
+	diagnoses := analyzer.DiagnoseFailures(orgID, payload.ClusterID, toDiagnose)
+	log.Printf("[AGENT] org=%s cluster=%s mode=%s failures=%d cleared=%d diagnoses=%d leader=%q", orgID, payload.ClusterID, defaultReportMode(payload.Mode), len(payload.Failures), len(payload.Cleared), len(diagnoses), payload.Leader)
 
 	newIssues := make([]analyzer.Diagnosis, 0, len(diagnoses))
 	for _, d := range diagnoses {
@@ -83,10 +213,25 @@ func (h *Handler) AgentReport(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Store diagnoses (this also registers/updates cluster in DB)
-	if err := h.store.SaveDiagnoses(ctx, orgID, payload.ClusterID, diagnoses); err != nil {
-		log.Printf("[ERROR] failed to store diagnoses: %v", err)
-		http.Error(w, "failed to store diagnoses: "+err.Error(), http.StatusInternalServerError)
+	// Store diagnoses (this also registers/updates cluster in DB). Delta
+	// protocol reports store them together with the failure state, which is
+	// refused if another report for the cluster was applied since the state
+	// was loaded; the agent retries, and the retry is then seen as a
+	// duplicate or answered with a resync.
+	var saveErr error
+	if stateUpdate == nil {
+		saveErr = h.store.SaveDiagnoses(ctx, orgID, payload.ClusterID, diagnoses)
+	} else {
+		saveErr = h.store.SaveFailureState(ctx, orgID, payload.ClusterID, *stateUpdate, diagnoses)
+	}
+	if errors.Is(saveErr, store.ErrFailureStateChanged) {
+		log.Printf("[AGENT] org=%s cluster=%s %s seq=%d overlapped another report, asking for a retry", orgID, payload.ClusterID, payload.Mode, payload.Sequence)
+		http.Error(w, "failure state changed, retry the report", http.StatusConflict)
+		return
+	}
+	if saveErr != nil {
+		log.Printf("[ERROR] failed to store diagnoses: %v", saveErr)
+		http.Error(w, "failed to store diagnoses: "+saveErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -96,18 +241,136 @@ func (h *Handler) AgentReport(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Return success
-	response := AgentReportResponse{
-		Status:  "accepted",
-		ID:      payload.ClusterID,
-		Message: "processed diagnoses",
+	if filtersJSON, err := json.Marshal(payload.Filters); err != nil {
+		log.Printf("[WARN] marshal cluster filters: %v", err)
+	} else if err := h.store.SaveClusterFilters(ctx, orgID, payload.ClusterID, filtersJSON); err != nil {
+		log.Printf("[WARN] save cluster filters: %v", err)
+	}
+
+	if payload.Redactions != nil && !payload.Redactions.Empty() {
+		log.Printf("[REDACTION] org=%s cluster=%s report=%s total=%d dropped=%s", orgID, payload.ClusterID, payload.ReportID, payload.Redactions.Total, strings.Join(payload.Redactions.DroppedFields, ","))
+		if summaryJSON, err := json.Marshal(payload.Redactions); err != nil {
+			log.Printf("[WARN] marshal redaction summary: %v", err)
+		} else if err := h.store.SaveRedactionSummary(ctx, orgID, payload.ClusterID, payload.ReportID, summaryJSON); err != nil {
+			log.Printf("[WARN] save redaction summary: %v", err)
+		}
+	}
+
+	if err := h.store.SaveClusterConfigVersion(ctx, orgID, payload.ClusterID, payload.ConfigVersion); err != nil {
+		log.Printf("[WARN] save cluster config version: %v", err)
 	}
 
+	if payload.ReportID != "" {
+		if err := h.store.MarkReportProcessed(ctx, orgID, payload.ClusterID, payload.ReportID); err != nil {
+			log.Printf("[WARN] mark report processed: %v", err)
+		}
+	}
+
+	// Return success
+	writeAgentReportResponse(w, AgentReportResponse{
+		Status:        "accepted",
+		ID:            payload.ClusterID,
+		Message:       "processed diagnoses",
+		ConfigVersion: h.desiredConfigVersion(ctx, orgID, payload.ClusterID),
+	})
+}
+
+func writeAgentReportResponse(w http.ResponseWriter, response AgentReportResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// assembleReportChunk stores one chunk of a split report and, once all chunks
+// are in, merges them back into a single payload. Failures and cleared keys
+// are split across the chunks and concatenated in chunk order; every chunk
+// carries the same report header.
+func (h *Handler) assembleReportChunk(ctx context.Context, orgID string, chunk AgentPayload) (AgentPayload, bool, error) {
+	body, err := json.Marshal(chunk)
+	if err != nil {
+		return AgentPayload{}, false, fmt.Errorf("marshal chunk: %w", err)
+	}
+
+	bodies, err := h.store.SaveReportChunk(ctx, orgID, chunk.ClusterID, store.ReportChunk{
+		ReportID: chunk.ReportID,
+		Index:    chunk.ChunkIndex,
+		Count:    chunk.ChunkCount,
+		Body:     body,
+	})
+	if err != nil || bodies == nil {
+		return AgentPayload{}, false, err
+	}
+
+	var assembled AgentPayload
+	for i, raw := range bodies {
+		var part AgentPayload
+		if err := json.Unmarshal(raw, &part); err != nil {
+			return AgentPayload{}, false, fmt.Errorf("decode chunk %d of report %s: %w", i, chunk.ReportID, err)
+		}
+		if i == 0 {
+			assembled = part
+			continue
+		}
+		assembled.Failures = append(assembled.Failures, part.Failures...)
+		assembled.Cleared = append(assembled.Cleared, part.Cleared...)
+	}
+	assembled.ChunkIndex = 0
+	assembled.ChunkCount = 0
+
+	log.Printf("[AGENT] org=%s cluster=%s assembled report %s from %d chunks", orgID, chunk.ClusterID, chunk.ReportID, len(bodies))
+	return assembled, true, nil
+}
+
+// reconcileFailureState compares a delta-protocol report with the stored
+// failure state and returns the failures that are new or changed (the only
+// ones worth diagnosing again) plus the state update to persist.
+func reconcileFailureState(state store.FailureState, payload AgentPayload) ([]k8s.PodFailure, *store.FailureStateUpdate, error) {
+	update := &store.FailureStateUpdate{BaseSequence: state.Sequence, Sequence: payload.Sequence}
+	changed := make([]k8s.PodFailure, 0, len(payload.Failures))
+	reported := make(map[string]struct{}, len(payload.Failures))
+
+	for _, failure := range payload.Failures {
+		key := k8s.FailureKey(failure)
+		fingerprint := k8s.FailureFingerprint(failure)
+		reported[key] = struct{}{}
+		if state.Fingerprints[key] == fingerprint {
+			continue
+		}
+
+		failureJSON, err := json.Marshal(failure)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal failure %s: %w", key, err)
+		}
+		changed = append(changed, failure)
+		update.Upserts = append(update.Upserts, store.FailureStateEntry{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Failure:     failureJSON,
+		})
+	}
+
+	switch payload.Mode {
+	case reportModeSnapshot:
+		// Anything we knew about that the snapshot no longer lists has cleared.
+		for key := range state.Fingerprints {
+			if _, ok := reported[key]; !ok {
+				update.Cleared = append(update.Cleared, key)
+			}
+		}
+	case reportModeDelta:
+		update.Cleared = append(update.Cleared, payload.Cleared...)
+	}
+
+	return changed, update, nil
+}
+
+func defaultReportMode(mode string) string {
+	if mode == "" {
+		return reportModeFull
+	}
+	return mode
+}
+
 func notifySlack(webhookURL, clusterID string, diagnoses []analyzer.Diagnosis) error {
 	maxItems := 5
 	if len(diagnoses) < maxItems {
@@ -230,18 +493,20 @@ func notifySlack(webhookURL, clusterID string, diagnoses []analyzer.Diagnosis) e
 
 func slackImpactLine(ctx []string) string {
 	for _, line := range ctx {
-		if strings.HasPrefix(line, "Replicas: ") {
-			m := strings.TrimPrefix(line, "Replicas: ")
-			parts := strings.SplitN(m, "/", 2)
-			if len(parts) != 2 {
+		readiness, ok := strings.CutPrefix(line, "Readiness: ")
+		if !ok {
+			continue
+		}
+		// The first "ready/desired" pair leads every readiness format.
+		for _, field := range strings.Fields(readiness) {
+			ready, desired, found := strings.Cut(field, "/")
+			if !found || ready == "" || desired == "" {
 				continue
 			}
-			ready := strings.TrimSpace(parts[0])
-			desired := strings.TrimSpace(strings.TrimSuffix(parts[1], " ready"))
 			if ready == desired {
-				return "no deployment degradation detected"
+				return "no workload degradation detected"
 			}
-			return fmt.Sprintf("deployment degraded (%s/%s ready)", ready, desired)
+			return fmt.Sprintf("workload degraded (%s)", readiness)
 		}
 	}
 	return "single pod issue"
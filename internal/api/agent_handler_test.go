@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"kuberoot/internal/k8s"
+	"kuberoot/internal/store"
+)
+
+// chunkStore keeps report chunks in memory the way PostgresStore does: the
+// bodies come back in index order once every chunk of a report is in.
+type chunkStore struct {
+	store.DiagnosisStore
+	chunks map[string]map[int]json.RawMessage
+}
+
+func (s *chunkStore) SaveReportChunk(_ context.Context, organizationID, clusterID string, chunk store.ReportChunk) ([]json.RawMessage, error) {
+	key := organizationID + "/" + clusterID + "/" + chunk.ReportID
+	if s.chunks[key] == nil {
+		s.chunks[key] = make(map[int]json.RawMessage)
+	}
+	s.chunks[key][chunk.Index] = chunk.Body
+	if len(s.chunks[key]) < chunk.Count {
+		return nil, nil
+	}
+	bodies := make([]json.RawMessage, chunk.Count)
+	for i := range bodies {
+		bodies[i] = s.chunks[key][i]
+	}
+	delete(s.chunks, key)
+	return bodies, nil
+}
+
+func TestAssembleReportChunk(t *testing.T) {
+	failure := func(i int) k8s.PodFailure {
+		return k8s.PodFailure{Namespace: "shop", Name: fmt.Sprintf("api-%d", i), Container: "api"}
+	}
+	chunk := func(index, count int, failures []k8s.PodFailure, cleared []string) AgentPayload {
+		return AgentPayload{
+			ClusterID:  "prod",
+			Mode:       "delta",
+			Sequence:   8,
+			ReportID:   "r1",
+			ChunkIndex: index,
+			ChunkCount: count,
+			Failures:   failures,
+			Cleared:    cleared,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		chunks       []AgentPayload // in arrival order
+		wantFailures []k8s.PodFailure
+		wantCleared  []string
+	}{
+		{
+			name: "in order",
+			chunks: []AgentPayload{
+				chunk(0, 2, []k8s.PodFailure{failure(0)}, []string{"a"}),
+				chunk(1, 2, []k8s.PodFailure{failure(1)}, []string{"b"}),
+			},
+			wantFailures: []k8s.PodFailure{failure(0), failure(1)},
+			wantCleared:  []string{"a", "b"},
+		},
+		{
+			name: "out of order with a retried chunk",
+			chunks: []AgentPayload{
+				chunk(2, 3, nil, []string{"c"}),
+				chunk(1, 3, []k8s.PodFailure{failure(1)}, []string{"b"}),
+				chunk(2, 3, nil, []string{"c"}),
+				chunk(0, 3, []k8s.PodFailure{failure(0)}, []string{"a"}),
+			},
+			wantFailures: []k8s.PodFailure{failure(0), failure(1)},
+			wantCleared:  []string{"a", "b", "c"},
+		},
+		{
+			name: "cleared keys only",
+			chunks: []AgentPayload{
+				chunk(1, 2, nil, []string{"c", "d"}),
+				chunk(0, 2, nil, []string{"a", "b"}),
+			},
+			wantCleared: []string{"a", "b", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&chunkStore{chunks: make(map[string]map[int]json.RawMessage)}, "")
+			for i, c := range tt.chunks {
+				assembled, complete, err := h.assembleReportChunk(context.Background(), "org", c)
+				if err != nil {
+					t.Fatalf("chunk %d: %v", i, err)
+				}
+				if last := i == len(tt.chunks)-1; complete != last {
+					t.Fatalf("chunk %d: complete = %v, want %v", i, complete, last)
+				}
+				if !complete {
+					continue
+				}
+				if assembled.ChunkIndex != 0 || assembled.ChunkCount != 0 || assembled.ReportID != "r1" || assembled.Sequence != 8 {
+					t.Errorf("assembled header %+v", assembled)
+				}
+				if !reflect.DeepEqual(assembled.Failures, tt.wantFailures) {
+					t.Errorf("failures = %+v, want %+v", assembled.Failures, tt.wantFailures)
+				}
+				if !reflect.DeepEqual(assembled.Cleared, tt.wantCleared) {
+					t.Errorf("cleared = %v, want %v", assembled.Cleared, tt.wantCleared)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"kuberoot/internal/auth"
+	"kuberoot/internal/store"
+)
+
+// backendFeatures lists the protocol features this backend understands, so
+// an agent can tell what it may rely on before sending reports.
+var backendFeatures = []string{"chunked-reports", "delta-reports", "gzip", "remote-config", "signed-reports"}
+
+// maxAgentConfigBytes bounds a stored agent config document.
+const maxAgentConfigBytes = 64 * 1024
+
+// localOnlyAgentSettings are config keys agents only take from their own
+// flags and config file: where reports and the API key are sent, and which
+// namespaces' logs leave the cluster. Agents refuse them from the backend
+// too; rejecting them here tells the admin at once.
+var localOnlyAgentSettings = [][]string{
+	{"backend", "url"},
+	{"enrichment", "logTail", "namespaces"},
+}
+
+// AgentRegisterRequest is sent by an agent on startup.
+type AgentRegisterRequest struct {
+	ClusterID     string   `json:"clusterId"`
+	AgentVersion  string   `json:"agentVersion"`
+	Features      []string `json:"features"`
+	ConfigVersion int64    `json:"configVersion,omitempty"`
+}
+
+// AgentRegisterResponse tells the agent what the backend supports and which
+// config version it should be running.
+type AgentRegisterResponse struct {
+	Status        string   `json:"status"`
+	Features      []string `json:"features"`
+	ConfigVersion int64    `json:"configVersion"`
+}
+
+// AgentConfigResponse is the effective config for one cluster: the
+// organization default with the cluster's overrides merged in.
+type AgentConfigResponse struct {
+	ClusterID     string          `json:"clusterId,omitempty"`
+	ConfigVersion int64           `json:"configVersion"`
+	Config        json.RawMessage `json:"config"`
+}
+
+// AgentRegister records an agent's version and features and returns the
+// config version it should converge on.
+func (h *Handler) AgentRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	orgID := auth.GetOrganizationID(r.Context())
+	if orgID == "" {
+		http.Error(w, "missing organization context", http.StatusUnauthorized)
+		return
+	}
+
+	var payload AgentRegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload.ClusterID = strings.TrimSpace(payload.ClusterID)
+	if payload.ClusterID == "" {
+		http.Error(w, "clusterId required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	err := h.store.RegisterAgent(ctx, orgID, payload.ClusterID, store.AgentRegistration{
+		AgentVersion:  payload.AgentVersion,
+		Features:      payload.Features,
+		ConfigVersion: payload.ConfigVersion,
+	})
+	if err != nil {
+		log.Printf("[ERROR] failed to register agent: %v", err)
+		http.Error(w, "failed to register agent: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	configs, err := h.store.LoadAgentConfig(ctx, orgID, payload.ClusterID)
+	if err != nil {
+		log.Printf("[ERROR] failed to load agent config: %v", err)
+		http.Error(w, "failed to load agent config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[AGENT] org=%s cluster=%s registered version=%q features=%s config=%d/%d", orgID, payload.ClusterID, payload.AgentVersion, strings.Join(payload.Features, ","), payload.ConfigVersion, configs.Version())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(AgentRegisterResponse{
+		Status:        "registered",
+		Features:      backendFeatures,
+		ConfigVersion: configs.Version(),
+	})
+}
+
+// AgentConfig serves the effective agent config on GET and replaces one
+// config layer on PUT. clusterId selects the cluster; a PUT without it sets
+// the organization default every cluster inherits. Agent API keys can read
+// config, but a PUT also needs the internal token, so a key leaked from one
+// cluster cannot reconfigure the others.
+func (h *Handler) AgentConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	orgID := auth.GetOrganizationID(r.Context())
+	if orgID == "" {
+		http.Error(w, "missing organization context", http.StatusUnauthorized)
+		return
+	}
+	clusterID := strings.TrimSpace(r.URL.Query().Get("clusterId"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	if r.Method == http.MethodPut {
+		if !requireInternalToken(w, r, "agent config updates not configured") {
+			return
+		}
+		config, err := decodeAgentConfig(w, r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, "config too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "invalid config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		version, err := h.store.SaveAgentConfig(ctx, orgID, clusterID, config)
+		if err != nil {
+			log.Printf("[ERROR] failed to save agent config: %v", err)
+			http.Error(w, "failed to save agent config: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		scope := clusterID
+		if scope == "" {
+			scope = "(organization default)"
+		}
+		log.Printf("[CONFIG] org=%s cluster=%s version=%d", orgID, scope, version)
+	} else if clusterID == "" {
+		http.Error(w, "clusterId required", http.StatusBadRequest)
+		return
+	}
+
+	configs, err := h.store.LoadAgentConfig(ctx, orgID, clusterID)
+	if err != nil {
+		log.Printf("[ERROR] failed to load agent config: %v", err)
+		http.Error(w, "failed to load agent config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	effective, err := configs.Effective()
+	if err != nil {
+		http.Error(w, "failed to merge agent config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(AgentConfigResponse{
+		ClusterID:     clusterID,
+		ConfigVersion: configs.Version(),
+		Config:        effective,
+	})
+}
+
+// decodeAgentConfig reads a config document from the request body. Only its
+// shape is checked here; the agent validates the settings themselves and
+// keeps its last good config if it rejects them.
+func decodeAgentConfig(w http.ResponseWriter, r *http.Request) (json.RawMessage, error) {
+	var config map[string]json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAgentConfigBytes)).Decode(&config); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.New("config must be a JSON object")
+	}
+	for _, path := range localOnlyAgentSettings {
+		if hasConfigKey(config, path) {
+			return nil, fmt.Errorf("%s can only be set in the agent's local config", strings.Join(path, "."))
+		}
+	}
+	return json.Marshal(config)
+}
+
+// hasConfigKey reports whether the nested key path is set in config.
+func hasConfigKey(config map[string]json.RawMessage, path []string) bool {
+	raw, ok := config[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return false
+	}
+	return hasConfigKey(nested, path[1:])
+}
+
+// desiredConfigVersion is the config version a cluster should be running,
+// or 0 when it cannot be determined.
+func (h *Handler) desiredConfigVersion(ctx context.Context, orgID, clusterID string) int64 {
+	configs, err := h.store.LoadAgentConfig(ctx, orgID, clusterID)
+	if err != nil {
+		log.Printf("[WARN] load agent config version: %v", err)
+		return 0
+	}
+	return configs.Version()
+}
@@ -200,6 +200,44 @@ func (h *Handler) DiagnoseCurrent(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+type ClustersResponse struct {
+	Count int                    `json:"count"`
+	Items []store.ClusterSummary `json:"items"`
+}
+
+// ListClusters returns the organization's clusters along with the pod filters
+// each agent reported, so the dashboard can show what is being watched.
+func (h *Handler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	orgID := auth.GetOrganizationID(r.Context())
+	if orgID == "" {
+		http.Error(w, "missing organization context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	clusters, err := h.store.ListClusters(ctx, orgID)
+	if err != nil {
+		http.Error(w, "failed to load clusters: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := ClustersResponse{
+		Count: len(clusters),
+		Items: clusters,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Version string `json:"version"`
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"net/http"
 	"os"
@@ -17,27 +18,38 @@ type GenerateKeyRequest struct {
 
 type GenerateKeyResponse struct {
 	APIKey         string `json:"apiKey"`
+	SigningSecret  string `json:"signingSecret"`
 	OrganizationID string `json:"organizationId"`
 	ClusterID      string `json:"clusterId,omitempty"`
 	Active         bool   `json:"active"`
 	CreatedAt      string `json:"createdAt"`
 }
 
-func (h *Handler) GenerateAPIKey(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
+// requireInternalToken checks the X-Internal-Token header against
+// INTERNAL_API_TOKEN and writes the error response when it does not match.
+// unconfigured is the message sent when no token is set on the backend.
+func requireInternalToken(w http.ResponseWriter, r *http.Request, unconfigured string) bool {
 	internalToken := strings.TrimSpace(os.Getenv("INTERNAL_API_TOKEN"))
 	if internalToken == "" {
-		http.Error(w, "internal key generation not configured", http.StatusServiceUnavailable)
-		return
+		http.Error(w, unconfigured, http.StatusServiceUnavailable)
+		return false
 	}
 
 	providedToken := strings.TrimSpace(r.Header.Get("X-Internal-Token"))
-	if providedToken == "" || providedToken != internalToken {
+	if providedToken == "" || subtle.ConstantTimeCompare([]byte(providedToken), []byte(internalToken)) != 1 {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) GenerateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireInternalToken(w, r, "internal key generation not configured") {
 		return
 	}
 
@@ -58,7 +70,7 @@ func (h *Handler) GenerateAPIKey(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	rawKey, err := h.store.CreateAPIKey(ctx, payload.OrganizationID, payload.Name)
+	rawKey, signingSecret, err := h.store.CreateAPIKey(ctx, payload.OrganizationID, payload.Name)
 	if err != nil {
 		http.Error(w, "failed to create API key: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -73,6 +85,7 @@ func (h *Handler) GenerateAPIKey(w http.ResponseWriter, r *http.Request) {
 
 	response := GenerateKeyResponse{
 		APIKey:         rawKey,
+		SigningSecret:  signingSecret,
 		OrganizationID: payload.OrganizationID,
 		ClusterID:      payload.ClusterID,
 		Active:         true,
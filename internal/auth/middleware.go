@@ -12,6 +12,7 @@ type contextKey string
 
 const (
 	organizationIDKey contextKey = "organizationID"
+	apiKeyHashKey     contextKey = "apiKeyHash"
 )
 
 type APIKeyValidator interface {
@@ -46,6 +47,7 @@ func APIKeyMiddleware(validator APIKeyValidator) func(http.Handler) http.Handler
 			}
 
 			ctx := context.WithValue(r.Context(), organizationIDKey, organizationID)
+			ctx = context.WithValue(ctx, apiKeyHashKey, keyHash)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
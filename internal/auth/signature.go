@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers carrying a report signature.
+const (
+	TimestampHeader = "X-Kuberoot-Timestamp"
+	NonceHeader     = "X-Kuberoot-Nonce"
+	SignatureHeader = "X-Kuberoot-Signature"
+)
+
+// SignatureStore looks up signing secrets and remembers used nonces.
+type SignatureStore interface {
+	SigningSecret(ctx context.Context, keyHash string) (string, error)
+	UseNonce(ctx context.Context, keyHash, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// SignatureOptions tunes SignatureMiddleware.
+type SignatureOptions struct {
+	// Paths whose requests must be signed.
+	Paths []string
+	// Skew is how far a request's timestamp may be from the server clock.
+	Skew time.Duration
+	// RequireAll rejects unsigned requests even from keys created before
+	// signing existing, which have no secret to sign with.
+	RequireAll bool
+}
+
+// SignReport returns the hex HMAC-SHA256 signature of a request. timestamp
+// is Unix seconds and body is exactly as sent, compressed or not.
+func SignReport(secret, timestamp, nonce, method, path string, body []byte) string {
+	digest := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + nonce + "\n" + method + "\n" + path + "\n" + hex.EncodeToString(digest[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureMiddleware verifies report signatures. It must run inside
+// APIKeyMiddleware, which identifies the key, and outside any middleware that
+// rewrites the body, since the signature covers the body as sent.
+//
+// Keys that have a signing secret must sign every request. A request is
+// rejected if its signature does not match, its timestamp is further than
+// Skew from now, or its nonce was already used.
+func SignatureMiddleware(store SignatureStore, opts SignatureOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !containsPath(opts.Paths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			keyHash, _ := r.Context().Value(apiKeyHashKey).(string)
+			if keyHash == "" {
+				http.Error(w, "missing X-API-Key header", http.StatusUnauthorized)
+				return
+			}
+			secret, err := store.SigningSecret(r.Context(), keyHash)
+			if err != nil {
+				http.Error(w, "invalid API key", http.StatusUnauthorized)
+				return
+			}
+
+			signature := strings.TrimSpace(r.Header.Get(SignatureHeader))
+			if signature == "" {
+				if secret == "" && !opts.RequireAll {
+					next.ServeHTTP(w, r)
+					return
+				}
+				http.Error(w, "missing "+SignatureHeader+" header", http.StatusUnauthorized)
+				return
+			}
+			if secret == "" {
+				http.Error(w, "API key has no signing secret; generate a new key", http.StatusUnauthorized)
+				return
+			}
+
+			timestamp := strings.TrimSpace(r.Header.Get(TimestampHeader))
+			nonce := strings.TrimSpace(r.Header.Get(NonceHeader))
+			if nonce == "" || len(nonce) > 128 {
+				http.Error(w, "missing or invalid "+NonceHeader+" header", http.StatusUnauthorized)
+				return
+			}
+			signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				http.Error(w, "missing or invalid "+TimestampHeader+" header", http.StatusUnauthorized)
+				return
+			}
+			if skew := time.Since(time.Unix(signedAt, 0)); skew > opts.Skew || skew < -opts.Skew {
+				http.Error(w, "request timestamp outside allowed clock skew", http.StatusUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+
+			expected := SignReport(secret, timestamp, nonce, r.Method, r.URL.Path, body)
+			if !hmac.Equal([]byte(signature), []byte(expected)) {
+				http.Error(w, "invalid request signature", http.StatusUnauthorized)
+				return
+			}
+
+			// Checked last so a forged request cannot burn a legitimate nonce.
+			fresh, err := store.UseNonce(r.Context(), keyHash, nonce, time.Unix(signedAt, 0).Add(opts.Skew))
+			if err != nil {
+				log.Printf("[AUTH] record nonce: %v", err)
+				http.Error(w, "failed to verify request", http.StatusInternalServerError)
+				return
+			}
+			if !fresh {
+				http.Error(w, "request nonce already used", http.StatusUnauthorized)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
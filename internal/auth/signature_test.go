@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type signatureStore struct {
+	secrets map[string]string // key hash -> signing secret, "" for keys without one
+	nonces  map[string]bool
+}
+
+func (s *signatureStore) SigningSecret(_ context.Context, keyHash string) (string, error) {
+	secret, ok := s.secrets[keyHash]
+	if !ok {
+		return "", errors.New("unknown key")
+	}
+	return secret, nil
+}
+
+func (s *signatureStore) UseNonce(_ context.Context, keyHash, nonce string, _ time.Time) (bool, error) {
+	if s.nonces[keyHash+"/"+nonce] {
+		return false, nil
+	}
+	s.nonces[keyHash+"/"+nonce] = true
+	return true, nil
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	const (
+		reportPath = "/api/v1/agent/report"
+		secret     = "s3cret"
+		body       = `{"clusterId":"prod"}`
+	)
+	now := time.Now()
+
+	type request struct {
+		keyHash   string
+		path      string
+		body      string
+		signed    bool
+		signBody  string // body the signature covers, when it differs from body
+		signPath  string
+		secret    string
+		timestamp time.Time
+		nonce     string
+	}
+	valid := request{keyHash: "signing", path: reportPath, body: body, signed: true, secret: secret, timestamp: now, nonce: "n1"}
+
+	tests := []struct {
+		name       string
+		opts       SignatureOptions
+		mutate     func(*request)
+		usedNonces []string
+		wantStatus int
+	}{
+		{name: "valid", wantStatus: http.StatusOK},
+		{name: "unprotected path", mutate: func(r *request) { r.path, r.signed = "/api/v1/diagnoses", false }, wantStatus: http.StatusOK},
+		{name: "legacy key unsigned", mutate: func(r *request) { r.keyHash, r.signed = "legacy", false }, wantStatus: http.StatusOK},
+		{name: "legacy key unsigned when all must sign", opts: SignatureOptions{RequireAll: true}, mutate: func(r *request) { r.keyHash, r.signed = "legacy", false }, wantStatus: http.StatusUnauthorized},
+		{name: "legacy key signed", mutate: func(r *request) { r.keyHash = "legacy" }, wantStatus: http.StatusUnauthorized},
+		{name: "signing key unsigned", mutate: func(r *request) { r.signed = false }, wantStatus: http.StatusUnauthorized},
+		{name: "no API key", mutate: func(r *request) { r.keyHash = "" }, wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret", mutate: func(r *request) { r.secret = "guess" }, wantStatus: http.StatusUnauthorized},
+		{name: "tampered body", mutate: func(r *request) { r.signBody = `{"clusterId":"staging"}` }, wantStatus: http.StatusUnauthorized},
+		{name: "signed for another path", mutate: func(r *request) { r.signPath = "/api/v1/agent/register" }, wantStatus: http.StatusUnauthorized},
+		{name: "stale timestamp", mutate: func(r *request) { r.timestamp = now.Add(-10 * time.Minute) }, wantStatus: http.StatusUnauthorized},
+		{name: "future timestamp", mutate: func(r *request) { r.timestamp = now.Add(10 * time.Minute) }, wantStatus: http.StatusUnauthorized},
+		{name: "missing nonce", mutate: func(r *request) { r.nonce = "" }, wantStatus: http.StatusUnauthorized},
+		{name: "replayed nonce", usedNonces: []string{"signing/n1"}, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &signatureStore{
+				secrets: map[string]string{"signing": secret, "legacy": ""},
+				nonces:  make(map[string]bool),
+			}
+			for _, nonce := range tt.usedNonces {
+				store.nonces[nonce] = true
+			}
+			opts := tt.opts
+			opts.Paths = []string{reportPath}
+			if opts.Skew == 0 {
+				opts.Skew = 5 * time.Minute
+			}
+
+			spec := valid
+			if tt.mutate != nil {
+				tt.mutate(&spec)
+			}
+			req := httptest.NewRequest(http.MethodPost, spec.path, strings.NewReader(spec.body))
+			if spec.keyHash != "" {
+				req = req.WithContext(context.WithValue(req.Context(), apiKeyHashKey, spec.keyHash))
+			}
+			if spec.signed {
+				signBody, signPath := spec.body, spec.path
+				if spec.signBody != "" {
+					signBody = spec.signBody
+				}
+				if spec.signPath != "" {
+					signPath = spec.signPath
+				}
+				timestamp := strconv.FormatInt(spec.timestamp.Unix(), 10)
+				req.Header.Set(TimestampHeader, timestamp)
+				req.Header.Set(NonceHeader, spec.nonce)
+				req.Header.Set(SignatureHeader, SignReport(spec.secret, timestamp, spec.nonce, http.MethodPost, signPath, []byte(signBody)))
+			}
+
+			var forwarded string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				read, _ := io.ReadAll(r.Body)
+				forwarded = string(read)
+			})
+			rec := httptest.NewRecorder()
+			SignatureMiddleware(store, opts)(next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, strings.TrimSpace(rec.Body.String()), tt.wantStatus)
+			}
+			if rec.Code == http.StatusOK && forwarded != spec.body {
+				t.Fatalf("handler read body %q, want %q", forwarded, spec.body)
+			}
+			// A rejected request must not use up the nonce of the request it forged.
+			if rec.Code != http.StatusOK && len(tt.usedNonces) == 0 && store.nonces[spec.keyHash+"/"+spec.nonce] {
+				t.Fatalf("rejected request used nonce %q", spec.nonce)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BatchStatus is the Job and CronJob state behind a batch failure: the spec
+// limits a fix would change and what the controllers last did. Unset limits
+// are nil.
+type BatchStatus struct {
+	Job                   string // the failed Job, or the CronJob run holding the schedule
+	BackoffLimit          *int32
+	ActiveDeadlineSeconds *int64
+	Completions           *int32
+	Succeeded             int32
+	Failed                int32
+	StartTime             *time.Time
+
+	CronJob                 string
+	Schedule                string
+	TimeZone                string
+	ConcurrencyPolicy       string
+	StartingDeadlineSeconds *int64
+	LastScheduleTime        *time.Time
+	MissedRun               *time.Time // earliest scheduled run that never started
+	MissedRuns              int        // capped at maxCountedMissedRuns
+}
+
+// cronMissGrace is how late a CronJob run may start before it counts as
+// missed. The controller normally starts runs within seconds.
+const cronMissGrace = 2 * time.Minute
+
+// maxCountedMissedRuns caps the count for a CronJob stalled for a long time.
+const maxCountedMissedRuns = 100
+
+// detectBatchFailures adds Job and CronJob failures to failures, the pod
+// failures found in the same pass, and trims the exit failures of Job pods:
+//   - a Job that hit its backoff limit or deadline is reported on its latest
+//     failed pod, or on its own when no failed pod is left;
+//   - of a Job's other failed pods only the latest is reported, and none once
+//     the Job completes or a later run of its CronJob succeeds;
+//   - a CronJob whose next run is overdue is reported as missing its
+//     schedule, or as stalled when concurrencyPolicy Forbid held it back.
+//
+// Jobs and CronJobs are listed through lookup, which reads the informer
+// caches in watch mode. The filter is applied before a Job's CronJob is
+// read, so filtered-out Jobs cost no further reads.
+func detectBatchFailures(ctx context.Context, lookup workloadLookup, pods []corev1.Pod, failures []PodFailure, opts Options) ([]PodFailure, error) {
+	namespace := opts.Filter.ListNamespace()
+	jobs, err := lookup.Jobs(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	cronJobs, err := lookup.CronJobs(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("list cronjobs: %w", err)
+	}
+	now := time.Now().UTC()
+
+	// Pods by "namespace/name", for the Job behind each exit failure.
+	podsByKey := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		podsByKey[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+	jobOfFailure := func(f PodFailure) string {
+		pod, ok := podsByKey[f.Namespace+"/"+f.Name]
+		if !ok || !hasType(f.Types, string(FailureNonZeroExit)) {
+			return ""
+		}
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "Job" {
+			return f.Namespace + "/" + owner.Name
+		}
+		return ""
+	}
+	latest := make(map[string]int) // "namespace/job" -> index of its newest exit failure
+	for i, f := range failures {
+		key := jobOfFailure(f)
+		if key == "" {
+			continue
+		}
+		j, seen := latest[key]
+		if !seen || podsByKey[f.Namespace+"/"+f.Name].CreationTimestamp.After(podsByKey[failures[j].Namespace+"/"+failures[j].Name].CreationTimestamp.Time) {
+			latest[key] = i
+		}
+	}
+
+	settled := make(map[string]bool) // Jobs whose pod failures no longer matter
+	var extra []PodFailure
+	for i := range jobs {
+		job := &jobs[i]
+		key := job.Namespace + "/" + job.Name
+
+		owner := controllerOf(job.OwnerReferences)
+		kinds := []string{"Job"}
+		if owner != nil && owner.Kind == "CronJob" {
+			kinds = append(kinds, owner.Kind)
+		}
+		if jobComplete(job) || !opts.Filter.AllowsWorkload(job, kinds...) {
+			settled[key] = true
+			continue
+		}
+		failureType, message, failedAt := jobFailure(job)
+		if failureType == "" {
+			continue
+		}
+
+		var cronJob *batchv1.CronJob
+		if len(kinds) > 1 {
+			cronJob, _ = lookup.CronJob(ctx, job.Namespace, owner.Name)
+		}
+		if cronJob != nil && cronJob.Status.LastSuccessfulTime != nil && cronJob.Status.LastSuccessfulTime.After(failedAt) {
+			settled[key] = true
+			continue
+		}
+
+		status := jobBatchStatus(job)
+		if cronJob != nil {
+			status.addCronJob(cronJob)
+		}
+		if idx, ok := latest[key]; ok {
+			f := &failures[idx]
+			f.Types = appendType(removeType(f.Types, string(FailureNonZeroExit)), failureType)
+			if f.Message == "" {
+				f.Message = message
+			}
+			f.Batch = status
+			continue
+		}
+
+		workload := jobWorkload(job)
+		extra = append(extra, PodFailure{
+			Namespace:         job.Namespace,
+			Name:              job.Name,
+			Image:             templateImage(job.Spec.Template.Spec),
+			Types:             []string{failureType},
+			Message:           message,
+			WorkloadKind:      workload.Kind,
+			WorkloadName:      workload.Name,
+			WorkloadRevision:  workload.Revision,
+			WorkloadReadiness: workload.Readiness,
+			PodAgeSeconds:     ageSeconds(job.CreationTimestamp, now),
+			Batch:             status,
+		})
+	}
+
+	out := failures[:0]
+	for i, f := range failures {
+		if key := jobOfFailure(f); key != "" && (settled[key] || latest[key] != i) {
+			continue
+		}
+		out = append(out, f)
+	}
+	out = append(out, extra...)
+
+	for i := range cronJobs {
+		cronJob := &cronJobs[i]
+		if (cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend) || !opts.Filter.AllowsWorkload(cronJob, "CronJob") {
+			continue
+		}
+		if failure, ok := cronJobFailure(ctx, lookup, cronJob, now); ok {
+			out = append(out, failure)
+		}
+	}
+	return out, nil
+}
+
+// jobFailure returns the failure type, message and time of a Job that
+// failed by hitting its backoff limit or deadline.
+func jobFailure(job *batchv1.Job) (string, string, time.Time) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type != batchv1.JobFailed || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Reason {
+		case batchv1.JobReasonBackoffLimitExceeded:
+			return string(FailureJobBackoffLimit), cond.Message, cond.LastTransitionTime.Time
+		case batchv1.JobReasonDeadlineExceeded:
+			return string(FailureJobDeadline), cond.Message, cond.LastTransitionTime.Time
+		}
+	}
+	return "", "", time.Time{}
+}
+
+func jobComplete(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// cronJobFailure reports a CronJob whose next run is overdue.
+func cronJobFailure(ctx context.Context, lookup workloadLookup, cronJob *batchv1.CronJob, now time.Time) (PodFailure, bool) {
+	missed, count := missedRuns(cronJob, now)
+	if count == 0 {
+		return PodFailure{}, false
+	}
+
+	template := cronJob.Spec.JobTemplate.Spec
+	status := &BatchStatus{
+		BackoffLimit:          template.BackoffLimit,
+		ActiveDeadlineSeconds: template.ActiveDeadlineSeconds,
+		Completions:           template.Completions,
+	}
+	failure := PodFailure{
+		Namespace:     cronJob.Namespace,
+		Name:          cronJob.Name,
+		Image:         templateImage(template.Template.Spec),
+		Types:         []string{string(FailureCronJobMissed)},
+		Message:       "No job was started for the run scheduled at " + missed.Format(time.RFC3339),
+		WorkloadKind:  "CronJob",
+		WorkloadName:  cronJob.Name,
+		PodAgeSeconds: ageSeconds(cronJob.CreationTimestamp, now),
+	}
+
+	if cronJob.Spec.ConcurrencyPolicy == batchv1.ForbidConcurrent && len(cronJob.Status.Active) > 0 {
+		active := cronJob.Status.Active[0].Name
+		failure.Types = []string{string(FailureCronJobStalled)}
+		failure.Message = "Job " + active + " is still running, so concurrencyPolicy Forbid skipped the run scheduled at " + missed.Format(time.RFC3339)
+		status.Job = active
+		if job, err := lookup.Job(ctx, cronJob.Namespace, active); err == nil {
+			status = jobBatchStatus(job)
+			workload := jobWorkload(job)
+			failure.WorkloadRevision, failure.WorkloadReadiness = workload.Revision, workload.Readiness
+		}
+	}
+
+	status.addCronJob(cronJob)
+	status.MissedRun = &missed
+	status.MissedRuns = count
+	failure.Batch = status
+	return failure, true
+}
+
+// missedRuns returns the first scheduled run after the CronJob's last one
+// that has not started, and how many runs have been missed since.
+func missedRuns(cronJob *batchv1.CronJob, now time.Time) (time.Time, int) {
+	timeZone := ""
+	if cronJob.Spec.TimeZone != nil {
+		timeZone = *cronJob.Spec.TimeZone
+	}
+	schedule, err := parseCronSchedule(cronJob.Spec.Schedule, timeZone)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	since := cronJob.CreationTimestamp.Time
+	if last := cronJob.Status.LastScheduleTime; last != nil && last.After(since) {
+		since = last.Time
+	}
+
+	first := schedule.next(since)
+	count := 0
+	for t := first; !t.IsZero() && t.Add(cronMissGrace).Before(now) && count < maxCountedMissedRuns; t = schedule.next(t) {
+		count++
+	}
+	return first.UTC(), count
+}
+
+func jobBatchStatus(job *batchv1.Job) *BatchStatus {
+	status := &BatchStatus{
+		Job:                   job.Name,
+		BackoffLimit:          job.Spec.BackoffLimit,
+		ActiveDeadlineSeconds: job.Spec.ActiveDeadlineSeconds,
+		Completions:           job.Spec.Completions,
+		Succeeded:             job.Status.Succeeded,
+		Failed:                job.Status.Failed,
+	}
+	if job.Status.StartTime != nil {
+		start := job.Status.StartTime.UTC()
+		status.StartTime = &start
+	}
+	return status
+}
+
+func (b *BatchStatus) addCronJob(cronJob *batchv1.CronJob) {
+	b.CronJob = cronJob.Name
+	b.Schedule = cronJob.Spec.Schedule
+	if cronJob.Spec.TimeZone != nil {
+		b.TimeZone = *cronJob.Spec.TimeZone
+	}
+	b.ConcurrencyPolicy = string(cronJob.Spec.ConcurrencyPolicy)
+	b.StartingDeadlineSeconds = cronJob.Spec.StartingDeadlineSeconds
+	if cronJob.Status.LastScheduleTime != nil {
+		last := cronJob.Status.LastScheduleTime.UTC()
+		b.LastScheduleTime = &last
+	}
+}
+
+func templateImage(spec corev1.PodSpec) string {
+	if len(spec.Containers) == 0 {
+		return ""
+	}
+	return spec.Containers[0].Image
+}
+
+func ageSeconds(created metav1.Time, now time.Time) int64 {
+	if created.IsZero() || now.Before(created.Time) {
+		return 0
+	}
+	return int64(now.Sub(created.Time).Seconds())
+}
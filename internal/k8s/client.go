@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -31,33 +32,61 @@ type PodInfo struct {
 type FailureType string
 
 const (
-	FailureCrashLoopBackOff FailureType = "CrashLoopBackOff"
-	FailureImagePullBackOff FailureType = "ImagePullBackOff"
-	FailureOOMKilled        FailureType = "OOMKilled"
-	FailureFailedScheduling FailureType = "FailedScheduling"
-	FailureReadinessProbe   FailureType = "ReadinessProbeFailed"
-	FailureLivenessProbe    FailureType = "LivenessProbeFailed"
-	FailureConfigMapMissing FailureType = "ConfigMapMissing"
-	FailureSecretMissing    FailureType = "SecretMissing"
-	FailurePodPending       FailureType = "PodPending"
-	FailureDNSLookup        FailureType = "DNSLookupFailed"
-	FailureImageRegistryDNS FailureType = "ImageRegistryDNSFailure"
-	FailureNetworkTimeout   FailureType = "NetworkTimeout"
-	FailureRolloutFailed    FailureType = "DeploymentRolloutFailed"
+	FailureCrashLoopBackOff  FailureType = "CrashLoopBackOff"
+	FailureImagePullBackOff  FailureType = "ImagePullBackOff"
+	FailureOOMKilled         FailureType = "OOMKilled"
+	FailureFailedScheduling  FailureType = "FailedScheduling"
+	FailureReadinessProbe    FailureType = "ReadinessProbeFailed"
+	FailureLivenessProbe     FailureType = "LivenessProbeFailed"
+	FailureStartupProbe      FailureType = "StartupProbeFailed"
+	FailureConfigMapMissing  FailureType = "ConfigMapMissing"
+	FailureSecretMissing     FailureType = "SecretMissing"
+	FailurePodPending        FailureType = "PodPending"
+	FailureDNSLookup         FailureType = "DNSLookupFailed"
+	FailureImageRegistryDNS  FailureType = "ImageRegistryDNSFailure"
+	FailureNetworkTimeout    FailureType = "NetworkTimeout"
+	FailureRolloutFailed     FailureType = "DeploymentRolloutFailed"
+	FailureNonZeroExit       FailureType = "NonZeroExit"
+	FailureJobBackoffLimit   FailureType = "JobBackoffLimitExceeded"
+	FailureJobDeadline       FailureType = "JobDeadlineExceeded"
+	FailureCronJobMissed     FailureType = "CronJobMissedSchedule"
+	FailureCronJobStalled    FailureType = "CronJobForbidStalled"
+	FailureNodeNotReady      FailureType = "NodeNotReady"
+	FailureNodeDisk          FailureType = "NodeDiskPressure"
+	FailureNodeMemory        FailureType = "NodeMemoryPressure"
+	FailureNodePID           FailureType = "NodePIDPressure"
+	FailurePVCUnbound        FailureType = "PVCUnbound"
+	FailureVolumeAttach      FailureType = "FailedAttachVolume"
+	FailureVolumeMultiAttach FailureType = "VolumeMultiAttach"
+	FailureVolumeMount       FailureType = "VolumeMountFailed"
+	FailureVolumeZone        FailureType = "VolumeZoneMismatch"
+	FailureEvicted           FailureType = "Evicted"
+	FailureCreateConfig      FailureType = "CreateContainerConfigError"
+	FailureCreateContainer   FailureType = "CreateContainerError"
+	FailureInvalidImage      FailureType = "InvalidImageName"
+	FailureRunContainer      FailureType = "RunContainerError"
+	FailureImageNeverPull    FailureType = "ErrImageNeverPull"
+	FailureInitBlocked       FailureType = "InitContainerBlocked"
 )
 
 type PodFailure struct {
 	Namespace             string
 	Name                  string
 	Container             string // container name (if applicable)
+	ContainerRole         string // app, init, sidecar or ephemeral; empty for pod-level failures
+	InitProgress          string // init containers done, e.g. "1/3", for init and sidecar failures
 	Image                 string
-	Deployment            string
-	DeploymentRevision    string
-	ReplicaStatus         string
+	NodeName              string
+	NodeConditions        []string // the node's problems, e.g. NotReady or DiskPressure; empty when healthy
+	WorkloadKind          string   // top-level owner kind, e.g. Deployment, StatefulSet, CronJob
+	WorkloadName          string
+	WorkloadRevision      string
+	WorkloadReadiness     string
 	Services              []string
 	ConfigMaps            []string
 	Secrets               []string
 	EnvVariables          []string
+	Volumes               []VolumeStatus // claims the pod mounts
 	ContainerCommand      string
 	Types                 []string // one or more of the above failure types
 	Message               string   // optional: short message we can print now; events on Day 4
@@ -73,6 +102,48 @@ type PodFailure struct {
 	CPURequest            string
 	PodAgeSeconds         int64
 	RecentRollout         bool
+	PreviousLogTail       []string // log tail of the last terminated instance, when captured
+	LogTail               []string // log tail of the current instance, when captured
+	Batch                 *BatchStatus
+	Eviction              *EvictionStatus
+	StartError            *StartError // what a container could not be created or started over
+
+	Probes         []ProbeSpec     // the container's probes, startup first
+	ContainerPorts []ContainerPort // ports the container declares
+	StartupSeconds int64           // how long the running container took to become ready; 0 if not observed
+	LastRunSeconds int64           // how long the last terminated instance ran
+}
+
+// UnmarshalJSON also reads the Deployment, DeploymentRevision and
+// ReplicaStatus keys that agents sent before workload owners were
+// generalised, so their reports and spooled payloads keep the owner.
+func (f *PodFailure) UnmarshalJSON(data []byte) error {
+	type plain PodFailure
+	var decoded struct {
+		plain
+		Deployment         string
+		DeploymentRevision string
+		ReplicaStatus      string
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*f = PodFailure(decoded.plain)
+	if f.WorkloadName == "" && decoded.Deployment != "" {
+		f.WorkloadKind, f.WorkloadName = "Deployment", decoded.Deployment
+		f.WorkloadRevision, f.WorkloadReadiness = decoded.DeploymentRevision, decoded.ReplicaStatus
+	}
+	return nil
+}
+
+// Options tunes failure collection. The zero value inspects every pod, runs
+// every enrichment and captures no logs.
+type Options struct {
+	Filter  *FailureFilter
+	LogTail *LogTailConfig
+
+	SkipEvents          bool // leave Events empty and skip event-derived signals
+	SkipWorkloadContext bool // skip owner, service and config ref lookups
 }
 
 // --- Config helpers (unchanged) ---
@@ -102,6 +173,31 @@ func LoadConfig() (*rest.Config, error) {
 	return nil, errors.New("unable to load kubeconfig or in-cluster config")
 }
 
+// LoadContextConfig builds a client config for contextName, read from
+// kubeconfig or, when that is empty, from $KUBECONFIG or ~/.kube/config. An
+// empty contextName uses the current context. It also returns the name of
+// the context used.
+func LoadContextConfig(kubeconfig, contextName string) (*rest.Config, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: contextName})
+
+	raw, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("read kubeconfig: %w", err)
+	}
+	if contextName == "" {
+		contextName = raw.CurrentContext
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("load context %q: %w", contextName, err)
+	}
+	return cfg, contextName, nil
+}
+
 func NewClientset() (*kubernetes.Clientset, error) {
 	cfg, err := LoadConfig()
 	if err != nil {
@@ -147,12 +243,31 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 	}
 	recentRollout := podAgeSeconds > 0 && podAgeSeconds <= 10*60
 
+	// 0) Evicted pods: the kubelet killed every container, so their
+	// statuses say nothing about the cause; the eviction message does.
+	if isEvicted(pod) {
+		eviction := parseEviction(pod)
+		return []PodFailure{{
+			Namespace:     pod.Namespace,
+			Name:          pod.Name,
+			Container:     eviction.Container,
+			Types:         []string{string(FailureEvicted)},
+			Message:       pod.Status.Message,
+			NodeName:      pod.Spec.NodeName,
+			PodAgeSeconds: podAgeSeconds,
+			Eviction:      eviction,
+		}}
+	}
+
 	// 1) Container-level states (CrashLoopBackOff, ImagePullBackOff, OOMKilled)
-	checkContainerStatuses := func(statuses []corev1.ContainerStatus) {
+	checkContainerStatuses := func(statuses []corev1.ContainerStatus, statusRole string) {
 		for _, cs := range statuses {
 			var types []string
 			msg := ""
-			containerSpec, foundContainerSpec := findContainerSpec(pod.Spec.Containers, cs.Name)
+			containerSpec, role, foundContainerSpec := findContainerSpec(&pod, cs.Name)
+			if !foundContainerSpec {
+				role = statusRole
+			}
 			containerImage := cs.Image
 			if foundContainerSpec && containerSpec.Image != "" {
 				containerImage = containerSpec.Image
@@ -167,6 +282,9 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 			memoryLimit := ""
 			cpuRequest := ""
 			containerCommand := ""
+			var probes []ProbeSpec
+			var ports []ContainerPort
+			var startError *StartError
 
 			if foundContainerSpec {
 				if q, ok := containerSpec.Resources.Limits[corev1.ResourceMemory]; ok {
@@ -183,6 +301,8 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 				} else if len(containerSpec.Args) > 0 {
 					containerCommand = strings.Join(containerSpec.Args, " ")
 				}
+				probes = containerProbes(containerSpec)
+				ports = containerPorts(containerSpec)
 			}
 
 			// Waiting reasons
@@ -197,6 +317,10 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 				case "ImagePullBackOff", "ErrImagePull":
 					types = appendType(types, string(FailureImagePullBackOff))
 					msg = cs.State.Waiting.Message
+				case "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError", "ErrImageNeverPull":
+					types = appendType(types, reason)
+					msg = cs.State.Waiting.Message
+					startError = parseStartError(msg)
 				case "ContainerCreating":
 					// Only flag pods stuck in ContainerCreating — likely a missing ConfigMap/Secret
 					if podAgeSeconds > 30 {
@@ -218,26 +342,61 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 					if cs.State.Terminated.Message != "" {
 						msg = cs.State.Terminated.Message
 					}
+				} else if exitCode != 0 && pod.Spec.RestartPolicy == corev1.RestartPolicyNever && role != ContainerRoleSidecar && role != ContainerRoleEphemeral {
+					// Never restarted, so this never shows up as CrashLoopBackOff.
+					// Sidecars are killed when the pod finishes and ephemeral
+					// containers exit with their debug session, neither a failure.
+					types = appendType(types, string(FailureNonZeroExit))
+					msg = cs.State.Terminated.Message
 				}
 			}
 
 			if cs.State.Running != nil {
 				containerState = "Running"
+				if role == ContainerRoleInit {
+					if initBlocked(&pod, cs, now) {
+						types = appendType(types, string(FailureInitBlocked))
+						msg = "Init container " + cs.Name + " has been running for more than " + initBlockedAfter.String() + "; the pod stays in Init:" + initProgress(&pod) + " until it completes"
+					}
+				}
+				if foundContainerSpec && (role == ContainerRoleApp || role == ContainerRoleSidecar) {
+					if notReady(&pod, containerSpec, cs, now) {
+						types = appendType(types, string(FailureReadinessProbe))
+						msg = "Container " + cs.Name + " has been running for more than " + notReadyAfter.String() + " without passing its readiness probe"
+					}
+				}
 			}
 
 			if cs.LastTerminationState.Terminated != nil {
 				lastTerminationReason = cs.LastTerminationState.Terminated.Reason
 				lastExitCode = cs.LastTerminationState.Terminated.ExitCode
-				if lastTerminationReason == "OOMKilled" || lastExitCode == 137 {
+				// A startup probe that never passed ends in the kubelet's kill,
+				// which backs off like a crash and exits 137 like an OOM kill.
+				if foundContainerSpec && startupProbeKilled(containerSpec, cs) {
+					types = appendType(removeType(types, string(FailureCrashLoopBackOff)), string(FailureStartupProbe))
+				} else if lastTerminationReason == "OOMKilled" || lastExitCode == 137 {
 					types = appendType(types, string(FailureOOMKilled))
 				}
+				// A container the runtime could not start backs off like a
+				// crash; the last termination holds the RunContainerError cause.
+				if (lastTerminationReason == "StartError" || lastTerminationReason == "ContainerCannotRun") && hasType(types, string(FailureCrashLoopBackOff)) {
+					types = appendType(removeType(types, string(FailureCrashLoopBackOff)), string(FailureRunContainer))
+					msg = cs.LastTerminationState.Terminated.Message
+					startError = parseStartError(msg)
+				}
 			}
 
 			if len(types) > 0 {
+				progress := ""
+				if role == ContainerRoleInit || role == ContainerRoleSidecar {
+					progress = initProgress(&pod)
+				}
 				results = append(results, PodFailure{
 					Namespace:             pod.Namespace,
 					Name:                  pod.Name,
 					Container:             cs.Name,
+					ContainerRole:         role,
+					InitProgress:          progress,
 					Image:                 containerImage,
 					Types:                 types,
 					Message:               msg,
@@ -251,6 +410,12 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 					MemoryLimit:           memoryLimit,
 					CPURequest:            cpuRequest,
 					ContainerCommand:      containerCommand,
+					Probes:                probes,
+					ContainerPorts:        ports,
+					StartupSeconds:        observedStartup(&pod, cs),
+					LastRunSeconds:        lastRunSeconds(cs),
+					StartError:            startError,
+					NodeName:              pod.Spec.NodeName,
 					PodAgeSeconds:         podAgeSeconds,
 					RecentRollout:         recentRollout,
 				})
@@ -258,8 +423,9 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 		}
 	}
 
-	checkContainerStatuses(pod.Status.InitContainerStatuses)
-	checkContainerStatuses(pod.Status.ContainerStatuses)
+	checkContainerStatuses(pod.Status.InitContainerStatuses, ContainerRoleInit)
+	checkContainerStatuses(pod.Status.ContainerStatuses, ContainerRoleApp)
+	checkContainerStatuses(pod.Status.EphemeralContainerStatuses, ContainerRoleEphemeral)
 
 	// 2) Pod-level conditions (FailedScheduling)
 	for _, cond := range pod.Status.Conditions {
@@ -269,7 +435,7 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 				Namespace:     pod.Namespace,
 				Name:          pod.Name,
 				Container:     "", // N/A at pod level
-				Types:         []string{string(FailureFailedScheduling)},
+				Types:         []string{string(schedulingFailureType(cond.Message))},
 				Message:       cond.Message,
 				PodAgeSeconds: podAgeSeconds,
 				RecentRollout: recentRollout,
@@ -281,94 +447,87 @@ func DetectFailures(pod corev1.Pod) []PodFailure {
 }
 
 // GetFailedPods returns only pods with detected failures plus details per container.
-func GetFailedPods(ctx context.Context, cs *kubernetes.Clientset) ([]PodFailure, error) {
-	podList, err := cs.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+// Pods rejected by opts.Filter are skipped before any enrichment, and
+// enrichment lists each affected namespace's objects once rather than
+// fetching them per failure.
+func GetFailedPods(ctx context.Context, cs kubernetes.Interface, opts Options) ([]PodFailure, error) {
+	podList, err := cs.CoreV1().Pods(opts.Filter.ListNamespace()).List(ctx, opts.Filter.ListOptions())
 	if err != nil {
 		return nil, fmt.Errorf("list pods for failures: %w", err)
 	}
+	return collectFailures(ctx, cs, newCycleLookup(cs), podList.Items, opts)
+}
+
+// collectFailures runs detection over pods and enriches every failing pod
+// through lookup, which may be backed by the API server or informer caches.
+// Job, CronJob and node failures are detected from the same lookup.
+// Log tails always come from cs, as they are not cached.
+func collectFailures(ctx context.Context, cs kubernetes.Interface, lookup workloadLookup, pods []corev1.Pod, opts Options) ([]PodFailure, error) {
 	var out []PodFailure
-	for _, p := range podList.Items {
+	for _, p := range pods {
+		if !opts.Filter.Allows(ctx, lookup, &p) {
+			continue
+		}
 		failures := DetectFailures(p)
 		if len(failures) == 0 {
 			continue
 		}
 
-		recentEvents, eventsErr := getRecentPodEvents(ctx, cs, p.Namespace, p.Name, 8)
-		if eventsErr != nil {
-			return nil, fmt.Errorf("list events for pod %s/%s: %w", p.Namespace, p.Name, eventsErr)
+		var recentEvents []string
+		if !opts.SkipEvents {
+			events, eventsErr := lookup.PodEvents(ctx, p.Namespace, p.Name)
+			if eventsErr != nil {
+				return nil, fmt.Errorf("list events for pod %s/%s: %w", p.Namespace, p.Name, eventsErr)
+			}
+			recentEvents = recentEventMessages(events, 8)
 		}
 
 		for i := range failures {
-			failures[i].Events = recentEvents
-			enrichFailureWithEventSignals(&failures[i])
-			enrichFailureWithWorkloadContext(ctx, cs, p, &failures[i])
+			if !opts.SkipEvents {
+				failures[i].Events = recentEvents
+				enrichFailureWithEventSignals(&failures[i])
+			}
+			if !opts.SkipWorkloadContext {
+				enrichFailureWithWorkloadContext(ctx, lookup, p, &failures[i])
+			}
+			captureLogTails(ctx, cs, opts.LogTail, &failures[i])
 		}
 		out = append(out, failures...)
 	}
-	return out, nil
+	out = aggregateEvictions(out)
+	out, err := detectBatchFailures(ctx, lookup, pods, out, opts)
+	if err != nil {
+		return nil, err
+	}
+	return detectNodeFailures(ctx, lookup, pods, out, opts)
 }
 
-func enrichFailureWithWorkloadContext(ctx context.Context, cs *kubernetes.Clientset, pod corev1.Pod, failure *PodFailure) {
+func enrichFailureWithWorkloadContext(ctx context.Context, lookup workloadLookup, pod corev1.Pod, failure *PodFailure) {
 	if failure == nil {
 		return
 	}
 
-	failure.Deployment, failure.DeploymentRevision, failure.ReplicaStatus = resolveDeploymentStatus(ctx, cs, pod)
-	failure.Services = listMatchingServices(ctx, cs, pod)
+	workload := resolveWorkload(ctx, lookup, pod)
+	failure.WorkloadKind, failure.WorkloadName = workload.Kind, workload.Name
+	failure.WorkloadRevision, failure.WorkloadReadiness = workload.Revision, workload.Readiness
+	failure.Services = listMatchingServices(ctx, lookup, pod)
 	failure.ConfigMaps, failure.Secrets, failure.EnvVariables = collectPodConfigRefs(pod, failure.Container)
+	failure.Volumes = resolveVolumes(ctx, lookup, pod)
+	classifyVolumeFailure(failure)
 }
 
-func resolveDeploymentStatus(ctx context.Context, cs *kubernetes.Clientset, pod corev1.Pod) (string, string, string) {
-	for _, owner := range pod.OwnerReferences {
-		if owner.Kind == "Deployment" {
-			dep, err := cs.AppsV1().Deployments(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
-			if err != nil {
-				return owner.Name, "", ""
-			}
-			desired := int32(1)
-			if dep.Spec.Replicas != nil {
-				desired = *dep.Spec.Replicas
-			}
-			revision := dep.Annotations["deployment.kubernetes.io/revision"]
-			return owner.Name, revision, fmt.Sprintf("%d/%d", dep.Status.ReadyReplicas, desired)
-		}
-		if owner.Kind == "ReplicaSet" {
-			rs, err := cs.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
-			if err != nil {
-				return "", "", ""
-			}
-			for _, rsOwner := range rs.OwnerReferences {
-				if rsOwner.Kind == "Deployment" {
-					dep, depErr := cs.AppsV1().Deployments(pod.Namespace).Get(ctx, rsOwner.Name, metav1.GetOptions{})
-					if depErr != nil {
-						return rsOwner.Name, "", ""
-					}
-					desired := int32(1)
-					if dep.Spec.Replicas != nil {
-						desired = *dep.Spec.Replicas
-					}
-					revision := dep.Annotations["deployment.kubernetes.io/revision"]
-					return rsOwner.Name, revision, fmt.Sprintf("%d/%d", dep.Status.ReadyReplicas, desired)
-				}
-			}
-		}
-	}
-
-	return "", "", ""
-}
-
-func listMatchingServices(ctx context.Context, cs *kubernetes.Clientset, pod corev1.Pod) []string {
+func listMatchingServices(ctx context.Context, lookup workloadLookup, pod corev1.Pod) []string {
 	if len(pod.Labels) == 0 {
 		return nil
 	}
 
-	svcs, err := cs.CoreV1().Services(pod.Namespace).List(ctx, metav1.ListOptions{})
+	svcs, err := lookup.Services(ctx, pod.Namespace)
 	if err != nil {
 		return nil
 	}
 
 	matches := make([]string, 0, 4)
-	for _, svc := range svcs.Items {
+	for _, svc := range svcs {
 		if len(svc.Spec.Selector) == 0 {
 			continue
 		}
@@ -402,7 +561,7 @@ func collectPodConfigRefs(pod corev1.Pod, targetContainer string) ([]string, []s
 		}
 	}
 
-	containers := pod.Spec.Containers
+	containers := podContainerSpecs(&pod)
 	for _, c := range containers {
 		if targetContainer != "" && c.Name != targetContainer {
 			continue
@@ -452,27 +611,28 @@ func mapKeys(values map[string]struct{}) []string {
 	return out
 }
 
-func appendType(types []string, t string) []string {
+func hasType(types []string, t string) bool {
 	for _, existing := range types {
 		if existing == t {
-			return types
+			return true
 		}
 	}
-	return append(types, t)
+	return false
 }
 
-func findContainerSpec(containers []corev1.Container, name string) (corev1.Container, bool) {
-	for _, c := range containers {
-		if c.Name == name {
-			return c, true
+func appendType(types []string, t string) []string {
+	for _, existing := range types {
+		if existing == t {
+			return types
 		}
 	}
-	return corev1.Container{}, false
+	return append(types, t)
 }
 
 func enrichFailureWithEventSignals(failure *PodFailure) {
 	configMapHit := false
 	secretHit := false
+	volumeHit := false
 
 	for _, event := range failure.Events {
 		lower := strings.ToLower(event)
@@ -483,6 +643,9 @@ func enrichFailureWithEventSignals(failure *PodFailure) {
 		if strings.Contains(lower, "liveness probe failed") {
 			failure.Types = appendType(failure.Types, string(FailureLivenessProbe))
 		}
+		if strings.Contains(lower, "startup probe failed") {
+			failure.Types = appendType(failure.Types, string(FailureStartupProbe))
+		}
 
 		// ConfigMap mount failure: "MountVolume.SetUp failed ... configmap "name" not found"
 		isMountFail := strings.Contains(lower, "mountvolume") || strings.Contains(lower, "mount failed")
@@ -507,6 +670,10 @@ func enrichFailureWithEventSignals(failure *PodFailure) {
 		if strings.Contains(lower, "progress deadline exceeded") || strings.Contains(lower, "timed out progressing") {
 			failure.Types = appendType(failure.Types, string(FailureRolloutFailed))
 		}
+		if volumeType := volumeEventType(lower); volumeType != "" {
+			volumeHit = true
+			failure.Types = appendType(failure.Types, string(volumeType))
+		}
 	}
 
 	if configMapHit {
@@ -517,6 +684,12 @@ func enrichFailureWithEventSignals(failure *PodFailure) {
 		failure.Types = removeType(failure.Types, string(FailurePodPending))
 		failure.Types = appendType(failure.Types, string(FailureSecretMissing))
 	}
+	if volumeHit {
+		failure.Types = removeType(failure.Types, string(FailurePodPending))
+		if hasType(failure.Types, string(FailureVolumeZone)) {
+			failure.Types = removeType(failure.Types, string(FailureFailedScheduling))
+		}
+	}
 }
 
 func removeType(types []string, t string) []string {
@@ -542,21 +715,17 @@ func isImagePullDNSFailure(eventLower string) bool {
 	return false
 }
 
-func getRecentPodEvents(ctx context.Context, cs *kubernetes.Clientset, namespace, podName string, limit int) ([]string, error) {
-	selector := fmt.Sprintf("involvedObject.kind=Pod,involvedObject.name=%s", podName)
-	eventList, err := cs.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
-	if err != nil {
-		return nil, err
-	}
-
+// recentEventMessages flattens pod events into de-duplicated "Reason: message"
+// strings, newest first, capped at limit.
+func recentEventMessages(events []corev1.Event, limit int) []string {
 	type eventEntry struct {
 		text string
 		ts   time.Time
 	}
-	entries := make([]eventEntry, 0, len(eventList.Items))
+	entries := make([]eventEntry, 0, len(events))
 	seen := make(map[string]struct{})
 
-	for _, event := range eventList.Items {
+	for _, event := range events {
 		reason := strings.TrimSpace(event.Reason)
 		message := strings.TrimSpace(event.Message)
 		if reason == "" && message == "" {
@@ -593,7 +762,7 @@ func getRecentPodEvents(ctx context.Context, cs *kubernetes.Clientset, namespace
 	for _, entry := range entries {
 		out = append(out, entry.text)
 	}
-	return out, nil
+	return out
 }
 
 func eventTimestamp(event corev1.Event) time.Time {
@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Container roles: how a container runs within its pod.
+const (
+	ContainerRoleApp       = "app"
+	ContainerRoleInit      = "init"      // runs to completion, in order, before the app containers start
+	ContainerRoleSidecar   = "sidecar"   // native sidecar: an init container with restartPolicy Always
+	ContainerRoleEphemeral = "ephemeral" // added to a running pod, e.g. by kubectl debug
+)
+
+// initBlockedAfter is how long an init container may run before the pod is
+// reported as blocked on it.
+const initBlockedAfter = 5 * time.Minute
+
+// findContainerSpec looks a container up by name among the pod's init, app
+// and ephemeral containers and reports its role.
+func findContainerSpec(pod *corev1.Pod, name string) (corev1.Container, string, bool) {
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == name {
+			return c, initContainerRole(c), true
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return c, ContainerRoleApp, true
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return corev1.Container(c.EphemeralContainerCommon), ContainerRoleEphemeral, true
+		}
+	}
+	return corev1.Container{}, "", false
+}
+
+// podContainerSpecs lists the specs of all the pod's containers, init
+// containers first.
+func podContainerSpecs(pod *corev1.Pod) []corev1.Container {
+	specs := make([]corev1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers)+len(pod.Spec.EphemeralContainers))
+	specs = append(specs, pod.Spec.InitContainers...)
+	specs = append(specs, pod.Spec.Containers...)
+	for _, c := range pod.Spec.EphemeralContainers {
+		specs = append(specs, corev1.Container(c.EphemeralContainerCommon))
+	}
+	return specs
+}
+
+func initContainerRole(c corev1.Container) string {
+	if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+		return ContainerRoleSidecar
+	}
+	return ContainerRoleInit
+}
+
+// initProgress counts the init containers that are done, as kubectl's
+// "Init:1/3": init containers that exited successfully and sidecars that
+// have started.
+func initProgress(pod *corev1.Pod) string {
+	total := len(pod.Spec.InitContainers)
+	if total == 0 {
+		return ""
+	}
+	done := 0
+	for _, cs := range pod.Status.InitContainerStatuses {
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			done++
+		case cs.Started != nil && *cs.Started && cs.State.Running != nil:
+			if _, role, ok := findContainerSpec(pod, cs.Name); ok && role == ContainerRoleSidecar {
+				done++
+			}
+		}
+	}
+	return strconv.Itoa(done) + "/" + strconv.Itoa(total)
+}
+
+// initBlocked reports whether an init container has been running for longer
+// than initBlockedAfter while the pod waits on it.
+func initBlocked(pod *corev1.Pod, cs corev1.ContainerStatus, now time.Time) bool {
+	if pod.Status.Phase != corev1.PodPending || cs.State.Running == nil || cs.State.Running.StartedAt.IsZero() {
+		return false
+	}
+	return now.Sub(cs.State.Running.StartedAt.Time) > initBlockedAfter
+}
@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		timeZone string
+		from     string
+		want     string // empty for no run within the search window
+	}{
+		{name: "step", spec: "*/15 * * * *", from: "2024-01-01T00:07:00Z", want: "2024-01-01T00:15:00Z"},
+		{name: "weekday range skips weekend", spec: "0 9 * * 1-5", from: "2024-01-05T10:00:00Z", want: "2024-01-08T09:00:00Z"},
+		{name: "names", spec: "30 6 1 feb,mar *", from: "2024-01-15T00:00:00Z", want: "2024-02-01T06:30:00Z"},
+		{name: "macro", spec: "@hourly", from: "2024-01-01T10:30:00Z", want: "2024-01-01T11:00:00Z"},
+		{name: "sunday as 7", spec: "0 0 * * 7", from: "2024-01-01T00:00:00Z", want: "2024-01-07T00:00:00Z"},
+		{name: "restricted day fields are ORed", spec: "0 0 13 * 5", from: "2024-01-01T00:00:00Z", want: "2024-01-05T00:00:00Z"},
+		// A stepped wildcard still counts as a wildcard, so both day fields
+		// must match: the 5th is the first odd day that is a Friday.
+		{name: "stepped wildcard day fields are ANDed", spec: "0 0 */2 * 5", from: "2024-01-01T00:00:00Z", want: "2024-01-05T00:00:00Z"},
+		{name: "wildcard in a list", spec: "0 0 *,1 * 5", from: "2024-01-01T00:00:00Z", want: "2024-01-05T00:00:00Z"},
+		{name: "every", spec: "@every 90m", from: "2024-01-01T00:00:30.5Z", want: "2024-01-01T01:30:30Z"},
+		{name: "every rounds to whole seconds", spec: "@every 1500ms", from: "2024-01-01T00:00:00Z", want: "2024-01-01T00:00:01Z"},
+		{name: "every is at least a second", spec: "@every 10ms", from: "2024-01-01T00:00:00Z", want: "2024-01-01T00:00:01Z"},
+		{name: "time zone field", spec: "0 9 * * *", timeZone: "America/New_York", from: "2024-01-01T00:00:00Z", want: "2024-01-01T14:00:00Z"},
+		{name: "CRON_TZ prefix", spec: "CRON_TZ=America/New_York 0 9 * * *", from: "2024-07-01T00:00:00Z", want: "2024-07-01T13:00:00Z"},
+		{name: "never", spec: "0 0 30 2 *", from: "2024-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.spec, tt.timeZone)
+			if err != nil {
+				t.Fatalf("parseCronSchedule(%q): %v", tt.spec, err)
+			}
+			got := schedule.next(mustTime(t, tt.from))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Fatalf("next = %v, want none", got)
+				}
+				return
+			}
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Fatalf("next = %v, want %v", got.UTC(), want)
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@every",
+		"@every soon",
+		"@fortnightly",
+		"TZ=Nowhere/Special * * * * *",
+	} {
+		if _, err := parseCronSchedule(spec, ""); err == nil {
+			t.Errorf("parseCronSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestMissedRuns(t *testing.T) {
+	created := mustTime(t, "2024-01-01T00:00:00Z")
+	tests := []struct {
+		name         string
+		schedule     string
+		lastSchedule string
+		now          string
+		wantFirst    string
+		wantCount    int
+	}{
+		{name: "counts runs past the grace period", schedule: "*/10 * * * *", lastSchedule: "2024-01-01T10:00:00Z", now: "2024-01-01T10:35:00Z", wantFirst: "2024-01-01T10:10:00Z", wantCount: 3},
+		{name: "run still within grace", schedule: "*/10 * * * *", lastSchedule: "2024-01-01T10:00:00Z", now: "2024-01-01T10:11:00Z", wantFirst: "2024-01-01T10:10:00Z", wantCount: 0},
+		{name: "every", schedule: "@every 1h", lastSchedule: "2024-01-01T10:00:00Z", now: "2024-01-01T12:30:00Z", wantFirst: "2024-01-01T11:00:00Z", wantCount: 2},
+		{name: "never scheduled counts from creation", schedule: "0 * * * *", now: "2024-01-01T03:30:00Z", wantFirst: "2024-01-01T01:00:00Z", wantCount: 3},
+		{name: "capped", schedule: "* * * * *", lastSchedule: "2024-01-01T10:00:00Z", now: "2024-01-02T10:00:00Z", wantFirst: "2024-01-01T10:01:00Z", wantCount: maxCountedMissedRuns},
+		{name: "invalid schedule", schedule: "not a schedule", now: "2024-01-02T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cronJob := &batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec:       batchv1.CronJobSpec{Schedule: tt.schedule},
+			}
+			if tt.lastSchedule != "" {
+				last := metav1.NewTime(mustTime(t, tt.lastSchedule))
+				cronJob.Status.LastScheduleTime = &last
+			}
+			first, count := missedRuns(cronJob, mustTime(t, tt.now))
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+			var wantFirst time.Time
+			if tt.wantFirst != "" {
+				wantFirst = mustTime(t, tt.wantFirst)
+			}
+			if !first.Equal(wantFirst) {
+				t.Errorf("first = %v, want %v", first, wantFirst)
+			}
+		})
+	}
+}
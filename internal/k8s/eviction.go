@@ -0,0 +1,150 @@
+package k8s
+
+import (
+	"regexp"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Eviction scopes: what the kubelet measured when it evicted the pod.
+const (
+	EvictionNodePressure   = "NodePressure"   // the node ran low; the pod was the largest consumer
+	EvictionContainerLimit = "ContainerLimit" // a container exceeded its ephemeral-storage limit
+	EvictionPodLimit       = "PodLimit"       // the pod exceeded its containers' total ephemeral-storage limit
+	EvictionEmptyDirLimit  = "EmptyDirLimit"  // an emptyDir volume exceeded its sizeLimit
+)
+
+// maxListedEvictedPods caps the evicted pods listed for one workload.
+const maxListedEvictedPods = 10
+
+// EvictionStatus is what the kubelet's eviction message says about an
+// evicted pod, plus how many pods of the same workload were evicted.
+type EvictionStatus struct {
+	Resource     string // memory, ephemeral-storage, pids, ...
+	Scope        string
+	Container    string // the container named by the message
+	Volume       string // the emptyDir volume over its sizeLimit
+	Usage        string
+	Request      string
+	Limit        string // the exceeded limit, or the container's limit for node pressure
+	Threshold    string // the node's eviction threshold
+	Available    string
+	EvictedCount int      // evicted pods of the same workload, this one included
+	EvictedPods  []string // their names, newest first, capped at maxListedEvictedPods
+}
+
+var (
+	evictionNodeLowRE       = regexp.MustCompile(`(?i)the node was low on resource: ([\w.-]+?)\.(?: threshold quantity: ([^,]+), available: (\S+?)\.(?:\s|$))?`)
+	evictionConsumerRE      = regexp.MustCompile(`(?i)container (\S+) was using (\S+?), request is (\S+?), has larger consumption of ([\w.-]+?)\.`)
+	evictionContainerRE     = regexp.MustCompile(`(?i)container (\S+) exceeded its local ephemeral storage limit "([^"]+)"`)
+	evictionContainerLimRE  = regexp.MustCompile(`(?i)usage of ephemeral local storage exceeds the container limit(?: "?([^".\s]+)"?)?`)
+	evictionPodLimitRE      = regexp.MustCompile(`(?i)pod ephemeral local storage usage exceeds the total limit of containers ([^.\s]+)`)
+	evictionEmptyDirLimitRE = regexp.MustCompile(`(?i)usage of emptydir volume "([^"]+)" exceeds the limit "([^"]+)"`)
+)
+
+// isEvicted reports whether the kubelet evicted the pod.
+func isEvicted(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted"
+}
+
+// parseEviction reads the kubelet's eviction message. The container's own
+// limit for the resource is filled in from the spec when the message only
+// names its usage.
+func parseEviction(pod corev1.Pod) *EvictionStatus {
+	msg := pod.Status.Message
+	status := &EvictionStatus{Scope: EvictionNodePressure}
+
+	switch {
+	case evictionEmptyDirLimitRE.MatchString(msg):
+		m := evictionEmptyDirLimitRE.FindStringSubmatch(msg)
+		status.Scope, status.Resource, status.Volume, status.Limit = EvictionEmptyDirLimit, string(corev1.ResourceEphemeralStorage), m[1], m[2]
+	case evictionContainerRE.MatchString(msg):
+		m := evictionContainerRE.FindStringSubmatch(msg)
+		status.Scope, status.Resource, status.Container, status.Limit = EvictionContainerLimit, string(corev1.ResourceEphemeralStorage), m[1], m[2]
+	case evictionContainerLimRE.MatchString(msg):
+		m := evictionContainerLimRE.FindStringSubmatch(msg)
+		status.Scope, status.Resource, status.Limit = EvictionContainerLimit, string(corev1.ResourceEphemeralStorage), m[1]
+	case evictionPodLimitRE.MatchString(msg):
+		m := evictionPodLimitRE.FindStringSubmatch(msg)
+		status.Scope, status.Resource, status.Limit = EvictionPodLimit, string(corev1.ResourceEphemeralStorage), m[1]
+	default:
+		if m := evictionNodeLowRE.FindStringSubmatch(msg); m != nil {
+			status.Resource, status.Threshold, status.Available = m[1], m[2], m[3]
+		}
+		if m := evictionConsumerRE.FindStringSubmatch(msg); m != nil {
+			status.Container, status.Usage, status.Request = m[1], m[2], m[3]
+			if status.Resource == "" {
+				status.Resource = m[4]
+			}
+		}
+	}
+
+	if status.Container == "" && len(pod.Spec.Containers) == 1 && status.Scope == EvictionContainerLimit {
+		status.Container = pod.Spec.Containers[0].Name
+	}
+	if status.Limit == "" && status.Container != "" {
+		if spec, _, ok := findContainerSpec(&pod, status.Container); ok {
+			if q, ok := spec.Resources.Limits[corev1.ResourceName(status.Resource)]; ok {
+				status.Limit = q.String()
+			}
+		}
+	}
+	status.EvictedCount = 1
+	status.EvictedPods = []string{pod.Name}
+	return status
+}
+
+// aggregateEvictions folds the evicted pods of each workload into the
+// failure of the newest one, so eviction churn is one failure that counts
+// the pods instead of one per pod. Evicted pods with no known workload are
+// kept as they are.
+func aggregateEvictions(failures []PodFailure) []PodFailure {
+	groups := make(map[string][]int)
+	var order []string
+	for i, f := range failures {
+		if f.Eviction == nil || f.WorkloadName == "" {
+			continue
+		}
+		key := f.Namespace + "/" + f.WorkloadKind + "/" + f.WorkloadName
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	drop := make(map[int]bool)
+	for _, key := range order {
+		idxs := groups[key]
+		if len(idxs) < 2 {
+			continue
+		}
+		sort.SliceStable(idxs, func(a, b int) bool {
+			return failures[idxs[a]].PodAgeSeconds < failures[idxs[b]].PodAgeSeconds
+		})
+		newest := &failures[idxs[0]]
+		eviction := *newest.Eviction
+		eviction.EvictedCount = len(idxs)
+		eviction.EvictedPods = nil
+		for n, idx := range idxs {
+			if n < maxListedEvictedPods {
+				eviction.EvictedPods = append(eviction.EvictedPods, failures[idx].Name)
+			}
+			if n > 0 {
+				drop[idx] = true
+			}
+		}
+		newest.Eviction = &eviction
+	}
+
+	if len(drop) == 0 {
+		return failures
+	}
+	out := failures[:0]
+	for i, f := range failures {
+		if !drop[i] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// FailureFilter decides which pods the agent inspects. Namespace lists take
+// glob patterns (path.Match syntax); an empty include list means every
+// namespace, and excludes win over includes. A nil filter admits every pod.
+type FailureFilter struct {
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	LabelSelector     string   `json:"labelSelector,omitempty"`
+	ExcludeOwnerKinds []string `json:"excludeOwnerKinds,omitempty"`
+
+	selector labels.Selector
+}
+
+// NewFailureFilter validates the patterns and selector. It returns nil when
+// nothing is configured, so callers can pass the result straight through.
+func NewFailureFilter(includeNamespaces, excludeNamespaces []string, labelSelector string, excludeOwnerKinds []string) (*FailureFilter, error) {
+	f := &FailureFilter{
+		IncludeNamespaces: cleanList(includeNamespaces),
+		ExcludeNamespaces: cleanList(excludeNamespaces),
+		LabelSelector:     strings.TrimSpace(labelSelector),
+		ExcludeOwnerKinds: cleanList(excludeOwnerKinds),
+	}
+	if len(f.IncludeNamespaces) == 0 && len(f.ExcludeNamespaces) == 0 && f.LabelSelector == "" && len(f.ExcludeOwnerKinds) == 0 {
+		return nil, nil
+	}
+
+	for _, pattern := range append(append([]string{}, f.IncludeNamespaces...), f.ExcludeNamespaces...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+		}
+	}
+	if f.LabelSelector != "" {
+		selector, err := labels.Parse(f.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", f.LabelSelector, err)
+		}
+		f.selector = selector
+	}
+	return f, nil
+}
+
+// Allows reports whether pod passes every configured rule. lookup resolves
+// the Job a pod belongs to so a CronJob exclusion covers its pods; it may be
+// nil, in which case only the pod's direct owners are matched.
+func (f *FailureFilter) Allows(ctx context.Context, lookup workloadLookup, pod *corev1.Pod) bool {
+	if f == nil {
+		return true
+	}
+	if !f.NamespaceAllowed(pod.Namespace) {
+		return false
+	}
+	if f.selector != nil && !f.selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	if len(f.ExcludeOwnerKinds) == 0 {
+		return true
+	}
+	for _, kind := range podOwnerKinds(ctx, lookup, pod) {
+		if containsFold(f.ExcludeOwnerKinds, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsWorkload applies the filter to a workload that is reported on its
+// own rather than through a pod, such as a failed Job. kinds are the
+// workload's kind and those of its owners, any of which can be excluded.
+func (f *FailureFilter) AllowsWorkload(obj metav1.Object, kinds ...string) bool {
+	if f == nil {
+		return true
+	}
+	if !f.NamespaceAllowed(obj.GetNamespace()) {
+		return false
+	}
+	if f.selector != nil && !f.selector.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+	for _, kind := range kinds {
+		if containsFold(f.ExcludeOwnerKinds, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClusterWide reports whether the filter admits pods in every namespace,
+// so cluster-scoped problems such as unhealthy nodes are in scope even
+// when no admitted pod is affected yet.
+func (f *FailureFilter) ClusterWide() bool {
+	return f == nil || (len(f.IncludeNamespaces) == 0 && f.selector == nil)
+}
+
+// NamespaceAllowed applies only the namespace include and exclude lists.
+func (f *FailureFilter) NamespaceAllowed(namespace string) bool {
+	if f == nil {
+		return true
+	}
+	if matchesAny(f.ExcludeNamespaces, namespace) {
+		return false
+	}
+	return len(f.IncludeNamespaces) == 0 || matchesAny(f.IncludeNamespaces, namespace)
+}
+
+// ListNamespace returns the one namespace the filter can be narrowed to on
+// the API server, or metav1.NamespaceAll when the includes need client-side
+// matching.
+func (f *FailureFilter) ListNamespace() string {
+	if f == nil || len(f.IncludeNamespaces) != 1 {
+		return metav1.NamespaceAll
+	}
+	ns := f.IncludeNamespaces[0]
+	if strings.ContainsAny(ns, `*?[\`) || matchesAny(f.ExcludeNamespaces, ns) {
+		return metav1.NamespaceAll
+	}
+	return ns
+}
+
+// ListOptions pushes the label selector down to pod list calls.
+func (f *FailureFilter) ListOptions() metav1.ListOptions {
+	if f == nil {
+		return metav1.ListOptions{}
+	}
+	return metav1.ListOptions{LabelSelector: f.LabelSelector}
+}
+
+// podOwnerKinds returns the kind of the pod's controller and of the workload
+// above it. Pods owned by a ReplicaSet with a pod-template-hash label also
+// count as Deployment pods without an extra lookup; a Job's own controller,
+// usually a CronJob, is read through lookup when one is given.
+func podOwnerKinds(ctx context.Context, lookup workloadLookup, pod *corev1.Pod) []string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil
+	}
+	kinds := []string{owner.Kind}
+	switch owner.Kind {
+	case "ReplicaSet":
+		if pod.Labels["pod-template-hash"] != "" {
+			kinds = append(kinds, "Deployment")
+		}
+	case "Job":
+		if lookup == nil {
+			break
+		}
+		if job, err := lookup.Job(ctx, pod.Namespace, owner.Name); err == nil {
+			if ref := metav1.GetControllerOfNoCopy(job); ref != nil {
+				kinds = append(kinds, ref.Kind)
+			}
+		}
+	}
+	return kinds
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// FailureKey is the stable identity of a failure across reports: the
+// container (or pod, for pod-level failures) it was detected on.
+func FailureKey(f PodFailure) string {
+	return f.Namespace + "/" + f.Name + "/" + f.Container
+}
+
+// FailureFingerprint hashes the parts of a failure that change its diagnosis.
+// Volatile fields (pod age, raw pod and claim events, log tails, missed
+// CronJob runs, evicted pod counts) are left out so a steady failure keeps
+// the same fingerprint between reports; restart counts are bucketed at the
+// thresholds that move severity.
+func FailureFingerprint(f PodFailure) string {
+	stable := f
+	stable.Events = nil
+	stable.PreviousLogTail = nil
+	stable.LogTail = nil
+	stable.PodAgeSeconds = 0
+	stable.RecentRollout = false
+	stable.RestartCount = restartBucket(f.RestartCount)
+	if len(f.Volumes) > 0 {
+		stable.Volumes = make([]VolumeStatus, len(f.Volumes))
+		for i, vol := range f.Volumes {
+			vol.ClaimEvents = nil
+			stable.Volumes[i] = vol
+		}
+	}
+	if f.Eviction != nil {
+		eviction := *f.Eviction
+		eviction.EvictedCount = 0
+		eviction.EvictedPods = nil
+		stable.Eviction = &eviction
+	}
+	if f.Batch != nil {
+		batch := *f.Batch
+		batch.MissedRuns = 0
+		stable.Batch = &batch
+	}
+
+	body, err := json.Marshal(stable)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+func restartBucket(restarts int32) int32 {
+	switch {
+	case restarts >= 10:
+		return 10
+	case restarts >= 3:
+		return 3
+	case restarts >= 1:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,134 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFailureFingerprint(t *testing.T) {
+	base := PodFailure{
+		Namespace:     "shop",
+		Name:          "api-7d9f-x2k",
+		Container:     "api",
+		Types:         []string{string(FailureCrashLoopBackOff)},
+		Message:       "back-off 5m0s restarting failed container",
+		Events:        []string{"Back-off restarting failed container"},
+		RestartCount:  4,
+		PodAgeSeconds: 600,
+	}
+	tests := []struct {
+		name   string
+		setup  func(*PodFailure) // applied to both failures before mutate
+		mutate func(*PodFailure)
+		same   bool
+	}{
+		{name: "unchanged", mutate: func(*PodFailure) {}, same: true},
+		{name: "newer events", mutate: func(f *PodFailure) { f.Events = append(f.Events, "Pulled image") }, same: true},
+		{name: "older pod", mutate: func(f *PodFailure) { f.PodAgeSeconds = 7200 }, same: true},
+		{name: "rollout window passed", mutate: func(f *PodFailure) { f.RecentRollout = !f.RecentRollout }, same: true},
+		{name: "restarts within a bucket", mutate: func(f *PodFailure) { f.RestartCount = 9 }, same: true},
+		{name: "restarts cross a bucket", mutate: func(f *PodFailure) { f.RestartCount = 10 }},
+		{name: "message", mutate: func(f *PodFailure) { f.Message = "exec format error" }},
+		{name: "failure type", mutate: func(f *PodFailure) { f.Types = []string{string(FailureOOMKilled)} }},
+		{name: "newer log lines", mutate: func(f *PodFailure) {
+			f.LogTail = []string{"listening on :8080"}
+			f.PreviousLogTail = []string{"panic: nil map"}
+		}, same: true},
+		{name: "more missed runs", setup: withBatch, mutate: func(f *PodFailure) { f.Batch.MissedRuns = 40 }, same: true},
+		{name: "new schedule", setup: withBatch, mutate: func(f *PodFailure) { f.Batch.Schedule = "0 * * * *" }},
+		{name: "newer claim events", setup: withVolume, mutate: func(f *PodFailure) { f.Volumes[0].ClaimEvents = []string{"waiting for first consumer"} }, same: true},
+		{name: "claim bound", setup: withVolume, mutate: func(f *PodFailure) { f.Volumes[0].ClaimPhase = "Bound" }},
+		{name: "more evictions", setup: withEviction, mutate: func(f *PodFailure) {
+			f.Eviction.EvictedCount = 5
+			f.Eviction.EvictedPods = append(f.Eviction.EvictedPods, "api-7d9f-q8m")
+		}, same: true},
+		{name: "other evicted resource", setup: withEviction, mutate: func(f *PodFailure) { f.Eviction.Resource = "ephemeral-storage" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, f := base, base
+			f.Events = append([]string(nil), base.Events...)
+			if tt.setup != nil {
+				tt.setup(&before)
+				tt.setup(&f)
+			}
+			want := FailureFingerprint(before)
+			tt.mutate(&f)
+			if got := FailureFingerprint(f); (got == want) != tt.same {
+				t.Fatalf("fingerprint %s, base %s, want same=%v", got, want, tt.same)
+			}
+		})
+	}
+}
+
+func withBatch(f *PodFailure) {
+	f.Batch = &BatchStatus{CronJob: "nightly", Schedule: "0 2 * * *", MissedRuns: 3}
+}
+
+func withVolume(f *PodFailure) {
+	f.Volumes = []VolumeStatus{{Volume: "data", Claim: "data-db-0", ClaimPhase: "Pending", ClaimEvents: []string{"no persistent volumes available"}}}
+}
+
+func withEviction(f *PodFailure) {
+	f.Eviction = &EvictionStatus{Resource: "memory", EvictedCount: 2, EvictedPods: []string{"api-7d9f-x2k", "api-7d9f-w4j"}}
+}
+
+// TestDetectedFailureFingerprint polls the same stuck pod at two points in
+// time; nothing about the failure changed, so neither may its fingerprint.
+func TestDetectedFailureFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      func(startedAt time.Time) corev1.Pod
+		wantType FailureType
+	}{
+		{name: "init container blocked", wantType: FailureInitBlocked, pod: func(startedAt time.Time) corev1.Pod {
+			return corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-0"},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "migrate"}},
+					Containers:     []corev1.Container{{Name: "api"}},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "migrate",
+						State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+					}},
+				},
+			}
+		}},
+		{name: "readiness probe failing", wantType: FailureReadinessProbe, pod: func(startedAt time.Time) corev1.Pod {
+			started := true
+			return corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-1"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "api", ReadinessProbe: &corev1.Probe{}}},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:    "api",
+						Started: &started,
+						State:   corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+					}},
+				},
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			earlier := DetectFailures(tt.pod(now.Add(-10 * time.Minute)))
+			later := DetectFailures(tt.pod(now.Add(-3 * time.Hour)))
+			if len(earlier) != 1 || len(later) != 1 || !hasType(earlier[0].Types, string(tt.wantType)) {
+				t.Fatalf("detected %+v and %+v, want one %s failure each", earlier, later, tt.wantType)
+			}
+			if a, b := FailureFingerprint(earlier[0]), FailureFingerprint(later[0]); a != b {
+				t.Fatalf("fingerprint changed from %s to %s: %q became %q", a, b, earlier[0].Message, later[0].Message)
+			}
+		})
+	}
+}
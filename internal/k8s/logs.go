@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogTailConfig enables capture of container log tails for failing
+// containers. Only namespaces matching one of Namespaces (globs, as in
+// FailureFilter) are read, since logs need the extra pods/log permission and
+// may hold data a team has not agreed to ship off-cluster. Tails are
+// captured raw; callers scrub them with a Redaction before they are sent.
+type LogTailConfig struct {
+	Lines      int64 // lines requested per stream
+	MaxBytes   int64 // byte budget per stream
+	Namespaces []string
+}
+
+func (c *LogTailConfig) enabledFor(namespace string) bool {
+	return c != nil && c.Lines > 0 && matchesAny(c.Namespaces, namespace)
+}
+
+// captureLogTails attaches the previous and current log tails of the failing
+// container. Read errors (missing permission, rotated logs) are not fatal:
+// the failure is still reported, just without the tail.
+func captureLogTails(ctx context.Context, cs kubernetes.Interface, cfg *LogTailConfig, failure *PodFailure) {
+	if cs == nil || failure.Container == "" || !cfg.enabledFor(failure.Namespace) {
+		return
+	}
+
+	if failure.RestartCount > 0 || failure.LastTerminationReason != "" {
+		failure.PreviousLogTail = fetchLogTail(ctx, cs, cfg, failure, true)
+	}
+	if failure.ContainerState == "Running" || failure.ContainerState == "Terminated" {
+		failure.LogTail = fetchLogTail(ctx, cs, cfg, failure, false)
+	}
+}
+
+func fetchLogTail(ctx context.Context, cs kubernetes.Interface, cfg *LogTailConfig, failure *PodFailure, previous bool) []string {
+	opts := &corev1.PodLogOptions{
+		Container: failure.Container,
+		Previous:  previous,
+		TailLines: &cfg.Lines,
+	}
+	// limitBytes keeps the start of the tail, not the end, so it is only a
+	// safety cap on the transfer; the budget is applied from the end below.
+	var transferCap int64
+	if cfg.MaxBytes > 0 {
+		transferCap = 4 * cfg.MaxBytes
+		opts.LimitBytes = &transferCap
+	}
+
+	raw, err := cs.CoreV1().Pods(failure.Namespace).GetLogs(failure.Name, opts).DoRaw(ctx)
+	if err != nil || len(raw) == 0 {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(raw), "\n"), "\n")
+	if transferCap > 0 && int64(len(raw)) >= transferCap && len(lines) > 1 {
+		lines = lines[:len(lines)-1] // cut mid-line by the transfer cap
+	}
+	return tailWithinBudget(lines, cfg.MaxBytes)
+}
+
+// tailWithinBudget keeps the most recent lines whose total size fits budget.
+func tailWithinBudget(lines []string, budget int64) []string {
+	if budget <= 0 {
+		return lines
+	}
+	var used int64
+	start := len(lines)
+	for start > 0 {
+		size := int64(len(lines[start-1])) + 1
+		if used+size > budget {
+			break
+		}
+		used += size
+		start--
+	}
+	return lines[start:]
+}
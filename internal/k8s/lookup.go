@@ -0,0 +1,308 @@
+package k8s
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// workloadLookup abstracts the reads needed to enrich a failing pod, so the
+// same enrichment code runs against the API server or against informer caches.
+type workloadLookup interface {
+	Deployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error)
+	ReplicaSet(ctx context.Context, namespace, name string) (*appsv1.ReplicaSet, error)
+	StatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error)
+	DaemonSet(ctx context.Context, namespace, name string) (*appsv1.DaemonSet, error)
+	Job(ctx context.Context, namespace, name string) (*batchv1.Job, error)
+	CronJob(ctx context.Context, namespace, name string) (*batchv1.CronJob, error)
+	Jobs(ctx context.Context, namespace string) ([]batchv1.Job, error)
+	CronJobs(ctx context.Context, namespace string) ([]batchv1.CronJob, error)
+	Nodes(ctx context.Context) ([]corev1.Node, error)
+	PersistentVolumeClaim(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error)
+	PersistentVolume(ctx context.Context, name string) (*corev1.PersistentVolume, error)
+	StorageClass(ctx context.Context, name string) (*storagev1.StorageClass, error)
+	Services(ctx context.Context, namespace string) ([]corev1.Service, error)
+	PodEvents(ctx context.Context, namespace, podName string) ([]corev1.Event, error)
+	ClaimEvents(ctx context.Context, namespace, claimName string) ([]corev1.Event, error)
+}
+
+// cycleLookup serves one detection pass from namespace-wide lists made on
+// first use, so enrichment costs a few API calls per namespace with failures
+// instead of several per failing container. It is not safe for concurrent
+// use and must not outlive the pass, as it never refreshes.
+type cycleLookup struct {
+	cs kubernetes.Interface
+
+	deployments  map[string]map[string]*appsv1.Deployment
+	replicaSets  map[string]map[string]*appsv1.ReplicaSet
+	statefulSets map[string]map[string]*appsv1.StatefulSet
+	daemonSets   map[string]map[string]*appsv1.DaemonSet
+	jobs         map[string]map[string]*batchv1.Job
+	cronJobs     map[string]map[string]*batchv1.CronJob
+	claims       map[string]map[string]*corev1.PersistentVolumeClaim
+	volumes      map[string]map[string]*corev1.PersistentVolume // cluster-scoped, under ""
+	classes      map[string]map[string]*storagev1.StorageClass  // cluster-scoped, under ""
+	services     map[string][]corev1.Service
+	nodes        []corev1.Node
+	events       map[string]map[string][]corev1.Event // namespace -> pod -> events
+	claimEvents  map[string]map[string][]corev1.Event // namespace -> claim -> events
+	errs         map[string]error                     // "kind/namespace" -> list error
+}
+
+func newCycleLookup(cs kubernetes.Interface) *cycleLookup {
+	return &cycleLookup{
+		cs:           cs,
+		deployments:  make(map[string]map[string]*appsv1.Deployment),
+		replicaSets:  make(map[string]map[string]*appsv1.ReplicaSet),
+		statefulSets: make(map[string]map[string]*appsv1.StatefulSet),
+		daemonSets:   make(map[string]map[string]*appsv1.DaemonSet),
+		jobs:         make(map[string]map[string]*batchv1.Job),
+		cronJobs:     make(map[string]map[string]*batchv1.CronJob),
+		claims:       make(map[string]map[string]*corev1.PersistentVolumeClaim),
+		volumes:      make(map[string]map[string]*corev1.PersistentVolume),
+		classes:      make(map[string]map[string]*storagev1.StorageClass),
+		services:     make(map[string][]corev1.Service),
+		events:       make(map[string]map[string][]corev1.Event),
+		claimEvents:  make(map[string]map[string][]corev1.Event),
+		errs:         make(map[string]error),
+	}
+}
+
+// cachedList is the list options for enrichment reads. ResourceVersion "0"
+// lets the API server answer from its watch cache instead of etcd; slightly
+// stale context is fine for a diagnosis.
+var cachedList = metav1.ListOptions{ResourceVersion: "0"}
+
+func (l *cycleLookup) Deployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	return lookupByName(l, l.deployments, schema.GroupResource{Group: "apps", Resource: "deployments"}, namespace, name, func() ([]appsv1.Deployment, error) {
+		list, err := l.cs.AppsV1().Deployments(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) ReplicaSet(ctx context.Context, namespace, name string) (*appsv1.ReplicaSet, error) {
+	return lookupByName(l, l.replicaSets, schema.GroupResource{Group: "apps", Resource: "replicasets"}, namespace, name, func() ([]appsv1.ReplicaSet, error) {
+		list, err := l.cs.AppsV1().ReplicaSets(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) StatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error) {
+	return lookupByName(l, l.statefulSets, schema.GroupResource{Group: "apps", Resource: "statefulsets"}, namespace, name, func() ([]appsv1.StatefulSet, error) {
+		list, err := l.cs.AppsV1().StatefulSets(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) DaemonSet(ctx context.Context, namespace, name string) (*appsv1.DaemonSet, error) {
+	return lookupByName(l, l.daemonSets, schema.GroupResource{Group: "apps", Resource: "daemonsets"}, namespace, name, func() ([]appsv1.DaemonSet, error) {
+		list, err := l.cs.AppsV1().DaemonSets(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) Job(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	return lookupByName(l, l.jobs, schema.GroupResource{Group: "batch", Resource: "jobs"}, namespace, name, func() ([]batchv1.Job, error) {
+		list, err := l.cs.BatchV1().Jobs(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) CronJob(ctx context.Context, namespace, name string) (*batchv1.CronJob, error) {
+	return lookupByName(l, l.cronJobs, schema.GroupResource{Group: "batch", Resource: "cronjobs"}, namespace, name, func() ([]batchv1.CronJob, error) {
+		list, err := l.cs.BatchV1().CronJobs(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) PersistentVolumeClaim(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	return lookupByName(l, l.claims, schema.GroupResource{Resource: "persistentvolumeclaims"}, namespace, name, func() ([]corev1.PersistentVolumeClaim, error) {
+		list, err := l.cs.CoreV1().PersistentVolumeClaims(namespace).List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) PersistentVolume(ctx context.Context, name string) (*corev1.PersistentVolume, error) {
+	return lookupByName(l, l.volumes, schema.GroupResource{Resource: "persistentvolumes"}, "", name, func() ([]corev1.PersistentVolume, error) {
+		list, err := l.cs.CoreV1().PersistentVolumes().List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+func (l *cycleLookup) StorageClass(ctx context.Context, name string) (*storagev1.StorageClass, error) {
+	return lookupByName(l, l.classes, schema.GroupResource{Group: "storage.k8s.io", Resource: "storageclasses"}, "", name, func() ([]storagev1.StorageClass, error) {
+		list, err := l.cs.StorageV1().StorageClasses().List(ctx, cachedList)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+// Jobs lists every Job in namespace, empty meaning all, and keeps them for
+// later Job reads in this pass.
+func (l *cycleLookup) Jobs(ctx context.Context, namespace string) ([]batchv1.Job, error) {
+	list, err := l.cs.BatchV1().Jobs(namespace).List(ctx, cachedList)
+	if err != nil {
+		return nil, err
+	}
+	fillByName(l.jobs, namespace, list.Items)
+	return list.Items, nil
+}
+
+// CronJobs lists every CronJob in namespace, empty meaning all, and keeps
+// them for later CronJob reads in this pass.
+func (l *cycleLookup) CronJobs(ctx context.Context, namespace string) ([]batchv1.CronJob, error) {
+	list, err := l.cs.BatchV1().CronJobs(namespace).List(ctx, cachedList)
+	if err != nil {
+		return nil, err
+	}
+	fillByName(l.cronJobs, namespace, list.Items)
+	return list.Items, nil
+}
+
+// fillByName caches a list made in namespace. An all-namespace list only
+// fills the namespaces it saw, since the rest may simply be empty.
+func fillByName[T any, PT interface {
+	*T
+	GetName() string
+	GetNamespace() string
+}](byNamespace map[string]map[string]*T, namespace string, items []T) {
+	if namespace != "" {
+		byNamespace[namespace] = make(map[string]*T, len(items))
+	}
+	for i := range items {
+		obj := PT(&items[i])
+		byName, ok := byNamespace[obj.GetNamespace()]
+		if !ok {
+			byName = make(map[string]*T)
+			byNamespace[obj.GetNamespace()] = byName
+		}
+		byName[obj.GetName()] = &items[i]
+	}
+}
+
+// lookupByName serves a get from byNamespace, filling it from list on the
+// first read of a namespace. A failed list is remembered for the pass.
+func lookupByName[T any, PT interface {
+	*T
+	GetName() string
+}](l *cycleLookup, byNamespace map[string]map[string]*T, resource schema.GroupResource, namespace, name string, list func() ([]T, error)) (*T, error) {
+	byName, ok := byNamespace[namespace]
+	if !ok {
+		key := resource.Resource + "/" + namespace
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		items, err := list()
+		if err != nil {
+			l.errs[key] = err
+			return nil, err
+		}
+		byName = make(map[string]*T, len(items))
+		for i := range items {
+			byName[PT(&items[i]).GetName()] = &items[i]
+		}
+		byNamespace[namespace] = byName
+	}
+	if obj, ok := byName[name]; ok {
+		return obj, nil
+	}
+	return nil, apierrors.NewNotFound(resource, name)
+}
+
+func (l *cycleLookup) Services(ctx context.Context, namespace string) ([]corev1.Service, error) {
+	if svcs, ok := l.services[namespace]; ok {
+		return svcs, nil
+	}
+	if err := l.errs["services/"+namespace]; err != nil {
+		return nil, err
+	}
+	list, err := l.cs.CoreV1().Services(namespace).List(ctx, cachedList)
+	if err != nil {
+		l.errs["services/"+namespace] = err
+		return nil, err
+	}
+	l.services[namespace] = list.Items
+	return list.Items, nil
+}
+
+func (l *cycleLookup) Nodes(ctx context.Context) ([]corev1.Node, error) {
+	if l.nodes != nil {
+		return l.nodes, nil
+	}
+	if err := l.errs["nodes/"]; err != nil {
+		return nil, err
+	}
+	list, err := l.cs.CoreV1().Nodes().List(ctx, cachedList)
+	if err != nil {
+		l.errs["nodes/"] = err
+		return nil, err
+	}
+	l.nodes = list.Items
+	return l.nodes, nil
+}
+
+func (l *cycleLookup) PodEvents(ctx context.Context, namespace, podName string) ([]corev1.Event, error) {
+	return l.involvedEvents(ctx, l.events, "Pod", namespace, podName)
+}
+
+func (l *cycleLookup) ClaimEvents(ctx context.Context, namespace, claimName string) ([]corev1.Event, error) {
+	return l.involvedEvents(ctx, l.claimEvents, "PersistentVolumeClaim", namespace, claimName)
+}
+
+// involvedEvents serves the events about one object of kind, listing the
+// namespace's events for that kind on first use.
+func (l *cycleLookup) involvedEvents(ctx context.Context, byNamespace map[string]map[string][]corev1.Event, kind, namespace, name string) ([]corev1.Event, error) {
+	byName, ok := byNamespace[namespace]
+	if !ok {
+		key := "events/" + kind + "/" + namespace
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		opts := cachedList
+		opts.FieldSelector = "involvedObject.kind=" + kind
+		list, err := l.cs.CoreV1().Events(namespace).List(ctx, opts)
+		if err != nil {
+			l.errs[key] = err
+			return nil, err
+		}
+		byName = make(map[string][]corev1.Event)
+		for _, event := range list.Items {
+			byName[event.InvolvedObject.Name] = append(byName[event.InvolvedObject.Name], event)
+		}
+		byNamespace[namespace] = byName
+	}
+	return byName[name], nil
+}
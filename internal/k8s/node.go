@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// nodePressureTypes maps the kubelet's pressure conditions to the failure
+// reported while they are True, in report order.
+var nodePressureTypes = []struct {
+	condition corev1.NodeConditionType
+	failure   FailureType
+}{
+	{corev1.NodeDiskPressure, FailureNodeDisk},
+	{corev1.NodeMemoryPressure, FailureNodeMemory},
+	{corev1.NodePIDPressure, FailureNodePID},
+}
+
+// detectNodeFailures adds a failure for every unhealthy node in scope and
+// records the problems of each pod failure's node on it, so the analyzer can
+// attribute the failure to the node. A node is in scope when the filter is
+// cluster-wide or when an admitted pod runs on it. Node failures have no
+// namespace and carry the node name as both Name and NodeName.
+func detectNodeFailures(ctx context.Context, lookup workloadLookup, pods []corev1.Pod, failures []PodFailure, opts Options) ([]PodFailure, error) {
+	nodes, err := lookup.Nodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+
+	unhealthy := make(map[string][]string)
+	for i := range nodes {
+		if problems := nodeProblems(&nodes[i]); len(problems) > 0 {
+			unhealthy[nodes[i].Name] = problems
+		}
+	}
+	if len(unhealthy) == 0 {
+		return failures, nil
+	}
+	for i := range failures {
+		if problems, ok := unhealthy[failures[i].NodeName]; ok && failures[i].NodeName != "" {
+			failures[i].NodeConditions = problems
+		}
+	}
+
+	inScope := make(map[string]bool)
+	if !opts.Filter.ClusterWide() {
+		for i := range pods {
+			if pods[i].Spec.NodeName != "" && opts.Filter.Allows(ctx, lookup, &pods[i]) {
+				inScope[pods[i].Spec.NodeName] = true
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for i := range nodes {
+		node := &nodes[i]
+		problems, ok := unhealthy[node.Name]
+		if !ok || (!opts.Filter.ClusterWide() && !inScope[node.Name]) {
+			continue
+		}
+		failures = append(failures, PodFailure{
+			Name:           node.Name,
+			Types:          nodeFailureTypes(node),
+			Message:        nodeMessage(node),
+			NodeName:       node.Name,
+			NodeConditions: problems,
+			PodAgeSeconds:  ageSeconds(node.CreationTimestamp, now),
+		})
+	}
+	return failures, nil
+}
+
+// nodeProblems names the node's failing conditions: NotReady when the
+// kubelet reports the node not ready or has stopped reporting, then any
+// pressure condition that is True. A node still registering, with no Ready
+// condition yet, has none.
+func nodeProblems(node *corev1.Node) []string {
+	var problems []string
+	if cond := nodeCondition(node, corev1.NodeReady); cond != nil && cond.Status != corev1.ConditionTrue {
+		problems = append(problems, "NotReady")
+	}
+	for _, p := range nodePressureTypes {
+		if cond := nodeCondition(node, p.condition); cond != nil && cond.Status == corev1.ConditionTrue {
+			problems = append(problems, string(p.condition))
+		}
+	}
+	return problems
+}
+
+func nodeFailureTypes(node *corev1.Node) []string {
+	var types []string
+	if cond := nodeCondition(node, corev1.NodeReady); cond != nil && cond.Status != corev1.ConditionTrue {
+		types = append(types, string(FailureNodeNotReady))
+	}
+	for _, p := range nodePressureTypes {
+		if cond := nodeCondition(node, p.condition); cond != nil && cond.Status == corev1.ConditionTrue {
+			types = append(types, string(p.failure))
+		}
+	}
+	return types
+}
+
+// nodeMessage joins the kubelet's messages for the failing conditions,
+// e.g. "NotReady: Kubelet stopped posting node status.".
+func nodeMessage(node *corev1.Node) string {
+	var parts []string
+	for _, problem := range nodeProblems(node) {
+		condType := corev1.NodeConditionType(problem)
+		if problem == "NotReady" {
+			condType = corev1.NodeReady
+		}
+		cond := nodeCondition(node, condType)
+		msg := strings.TrimSpace(cond.Message)
+		if msg == "" {
+			msg = cond.Reason
+		}
+		if msg == "" {
+			parts = append(parts, problem)
+			continue
+		}
+		parts = append(parts, problem+": "+msg)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func nodeCondition(node *corev1.Node, condType corev1.NodeConditionType) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == condType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Probe kinds.
+const (
+	ProbeLiveness  = "liveness"
+	ProbeReadiness = "readiness"
+	ProbeStartup   = "startup"
+)
+
+// notReadyAfter is how long a running container may fail its readiness
+// probe before the pod is reported for it.
+const notReadyAfter = 5 * time.Minute
+
+// ProbeSpec is a container probe as declared in the pod spec, with the
+// kubelet's defaults filled in for unset timings.
+type ProbeSpec struct {
+	Kind                string // liveness, readiness or startup
+	Handler             string // httpGet, tcpSocket, grpc or exec
+	Path                string // httpGet path
+	Port                string // port number or name, as declared
+	Command             string // exec command
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
+	TimeoutSeconds      int32
+	FailureThreshold    int32
+}
+
+// ContainerPort is a port the container declares in its spec.
+type ContainerPort struct {
+	Name     string
+	Port     int32
+	Protocol string
+}
+
+// containerProbes lists the container's probes, startup first, in the order
+// the kubelet runs them.
+func containerProbes(c corev1.Container) []ProbeSpec {
+	var probes []ProbeSpec
+	for _, p := range []struct {
+		kind  string
+		probe *corev1.Probe
+	}{
+		{ProbeStartup, c.StartupProbe},
+		{ProbeLiveness, c.LivenessProbe},
+		{ProbeReadiness, c.ReadinessProbe},
+	} {
+		if p.probe != nil {
+			probes = append(probes, probeSpec(p.kind, p.probe))
+		}
+	}
+	return probes
+}
+
+func probeSpec(kind string, p *corev1.Probe) ProbeSpec {
+	spec := ProbeSpec{
+		Kind:                kind,
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       defaultInt32(p.PeriodSeconds, 10),
+		TimeoutSeconds:      defaultInt32(p.TimeoutSeconds, 1),
+		FailureThreshold:    defaultInt32(p.FailureThreshold, 3),
+	}
+	switch {
+	case p.HTTPGet != nil:
+		spec.Handler, spec.Path, spec.Port = "httpGet", p.HTTPGet.Path, p.HTTPGet.Port.String()
+	case p.TCPSocket != nil:
+		spec.Handler, spec.Port = "tcpSocket", p.TCPSocket.Port.String()
+	case p.GRPC != nil:
+		spec.Handler, spec.Port = "grpc", strconv.Itoa(int(p.GRPC.Port))
+	case p.Exec != nil:
+		spec.Handler, spec.Command = "exec", strings.Join(p.Exec.Command, " ")
+	}
+	return spec
+}
+
+func defaultInt32(v, fallback int32) int32 {
+	if v <= 0 {
+		return fallback
+	}
+	return v
+}
+
+func containerPorts(c corev1.Container) []ContainerPort {
+	ports := make([]ContainerPort, 0, len(c.Ports))
+	for _, p := range c.Ports {
+		ports = append(ports, ContainerPort{Name: p.Name, Port: p.ContainerPort, Protocol: string(p.Protocol)})
+	}
+	return ports
+}
+
+// observedStartup is how long the running container took to become ready:
+// from its start to the pod's ContainersReady transition. It returns 0 when
+// the container is not ready or became ready before this start.
+func observedStartup(pod *corev1.Pod, cs corev1.ContainerStatus) int64 {
+	if !cs.Ready || cs.State.Running == nil || cs.State.Running.StartedAt.IsZero() {
+		return 0
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.ContainersReady && cond.Status == corev1.ConditionTrue && cond.LastTransitionTime.After(cs.State.Running.StartedAt.Time) {
+			return int64(cond.LastTransitionTime.Sub(cs.State.Running.StartedAt.Time).Seconds())
+		}
+	}
+	return 0
+}
+
+// lastRunSeconds is how long the container's previous instance ran before
+// it terminated, or 0 when that is unknown.
+func lastRunSeconds(cs corev1.ContainerStatus) int64 {
+	t := cs.LastTerminationState.Terminated
+	if t == nil || t.StartedAt.IsZero() || !t.FinishedAt.After(t.StartedAt.Time) {
+		return 0
+	}
+	return int64(t.FinishedAt.Sub(t.StartedAt.Time).Seconds())
+}
+
+// startupProbeKilled reports whether the kubelet killed the container's last
+// instance because its startup probe never passed: the container has a
+// startup probe, has not started since, and the last instance ended on
+// SIGKILL or SIGTERM rather than out of memory.
+func startupProbeKilled(spec corev1.Container, cs corev1.ContainerStatus) bool {
+	t := cs.LastTerminationState.Terminated
+	if spec.StartupProbe == nil || t == nil || t.Reason == "OOMKilled" || (cs.Started != nil && *cs.Started) {
+		return false
+	}
+	return t.ExitCode == 137 || t.ExitCode == 143
+}
+
+// notReady reports whether a running, started container has been failing
+// its readiness probe for longer than notReadyAfter, counted from its start
+// or from when the pod's containers last went unready, whichever is later.
+func notReady(pod *corev1.Pod, spec corev1.Container, cs corev1.ContainerStatus, now time.Time) bool {
+	if spec.ReadinessProbe == nil || cs.Ready || cs.State.Running == nil || cs.State.Running.StartedAt.IsZero() || (cs.Started != nil && !*cs.Started) {
+		return false
+	}
+	since := cs.State.Running.StartedAt.Time
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.ContainersReady && cond.Status == corev1.ConditionFalse && cond.LastTransitionTime.After(since) {
+			since = cond.LastTransitionTime.Time
+		}
+	}
+	return now.Sub(since) > notReadyAfter
+}
@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"kuberoot/internal/redact"
+)
+
+// redactableFields are the free-text PodFailure fields that can carry
+// secrets, by the name used in drop rules and redaction counts.
+var redactableFields = []struct {
+	name   string
+	values func(f *PodFailure) []*string
+	drop   func(f *PodFailure)
+}{
+	{"message", func(f *PodFailure) []*string { return []*string{&f.Message} }, func(f *PodFailure) { f.Message = "" }},
+	{"containerCommand", func(f *PodFailure) []*string { return []*string{&f.ContainerCommand} }, func(f *PodFailure) { f.ContainerCommand = "" }},
+	{"envVariables", func(f *PodFailure) []*string { return stringRefs(f.EnvVariables) }, func(f *PodFailure) { f.EnvVariables = nil }},
+	{"events", func(f *PodFailure) []*string { return stringRefs(f.Events) }, func(f *PodFailure) { f.Events = nil }},
+	{"claimEvents", claimEventRefs, func(f *PodFailure) {
+		for i := range f.Volumes {
+			f.Volumes[i].ClaimEvents = nil
+		}
+	}},
+	{"startError", startErrorRefs, func(f *PodFailure) { f.StartError = nil }},
+	{"probes", probeRefs, func(f *PodFailure) { f.Probes = nil }},
+	{"logTail", func(f *PodFailure) []*string { return stringRefs(f.LogTail) }, func(f *PodFailure) { f.LogTail = nil }},
+	{"previousLogTail", func(f *PodFailure) []*string { return stringRefs(f.PreviousLogTail) }, func(f *PodFailure) { f.PreviousLogTail = nil }},
+	{"configMaps", func(f *PodFailure) []*string { return stringRefs(f.ConfigMaps) }, func(f *PodFailure) { f.ConfigMaps = nil }},
+	{"secrets", func(f *PodFailure) []*string { return stringRefs(f.Secrets) }, func(f *PodFailure) { f.Secrets = nil }},
+	{"services", func(f *PodFailure) []*string { return stringRefs(f.Services) }, func(f *PodFailure) { f.Services = nil }},
+}
+
+func stringRefs(values []string) []*string {
+	refs := make([]*string, len(values))
+	for i := range values {
+		refs[i] = &values[i]
+	}
+	return refs
+}
+
+func claimEventRefs(f *PodFailure) []*string {
+	var refs []*string
+	for i := range f.Volumes {
+		refs = append(refs, stringRefs(f.Volumes[i].ClaimEvents)...)
+	}
+	return refs
+}
+
+func startErrorRefs(f *PodFailure) []*string {
+	if f.StartError == nil {
+		return nil
+	}
+	return []*string{&f.StartError.Name, &f.StartError.Key, &f.StartError.Reason}
+}
+
+func probeRefs(f *PodFailure) []*string {
+	refs := make([]*string, 0, 2*len(f.Probes))
+	for i := range f.Probes {
+		refs = append(refs, &f.Probes[i].Path, &f.Probes[i].Command)
+	}
+	return refs
+}
+
+// RedactableFields lists the field names accepted by NewRedaction's drop
+// rules.
+func RedactableFields() []string {
+	names := make([]string, 0, len(redactableFields))
+	for _, field := range redactableFields {
+		names = append(names, field.name)
+	}
+	return names
+}
+
+// Redaction scrubs failures before they leave the cluster: dropped fields
+// are emptied outright and every other free-text field is run through the
+// redactor.
+type Redaction struct {
+	redactor *redact.Redactor
+	drop     map[string]bool
+}
+
+// NewRedaction builds a Redaction from extra regexps, added to the built-in
+// detectors, and the names of fields to drop (see RedactableFields, matched
+// case-insensitively).
+func NewRedaction(patterns, dropFields []string) (*Redaction, error) {
+	redactor, err := redact.New(patterns)
+	if err != nil {
+		return nil, err
+	}
+	r := &Redaction{redactor: redactor, drop: make(map[string]bool)}
+	for _, name := range dropFields {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, field := range redactableFields {
+			if strings.EqualFold(field.name, name) {
+				r.drop[field.name] = true
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown drop field %q (known: %s)", name, strings.Join(RedactableFields(), ", "))
+		}
+	}
+	return r, nil
+}
+
+// DroppedFields returns the fields this Redaction empties, sorted.
+func (r *Redaction) DroppedFields() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.drop))
+	for name := range r.drop {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply scrubs failures in place and returns what it redacted. A nil
+// Redaction leaves them unchanged.
+func (r *Redaction) Apply(failures []PodFailure) redact.Summary {
+	var summary redact.Summary
+	if r == nil {
+		return summary
+	}
+	summary.DroppedFields = r.DroppedFields()
+
+	counts := make(map[string]int)
+	for i := range failures {
+		for _, field := range redactableFields {
+			if r.drop[field.name] {
+				field.drop(&failures[i])
+				continue
+			}
+			for _, value := range field.values(&failures[i]) {
+				*value = r.redactor.Redact(*value, counts)
+			}
+			summary.Add(field.name, counts)
+			clear(counts)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StartError is what the kubelet's message says stopped a container from
+// being created or started: the object, key, image or executable it names.
+type StartError struct {
+	Kind   string // ConfigMap, Secret, Image, Executable, Mount, SecurityContext or Runtime
+	Name   string // the object, image reference, executable or mount path
+	Key    string // the missing ConfigMap or Secret key
+	Reason string // e.g. "key not found", "executable file not found in $PATH"
+}
+
+var (
+	startMissingKeyRE    = regexp.MustCompile(`couldn't find key (\S+) in (ConfigMap|Secret) (?:[\w.-]+/)?([\w.-]+)`)
+	startObjectMissingRE = regexp.MustCompile(`(?i)(configmap|secret) "([^"]+)" not found`)
+	startNonNumericRE    = regexp.MustCompile(`non-numeric user \(([^)]+)\)`)
+	startExecRE          = regexp.MustCompile(`exec: "([^"]+)"`)
+	startMountRE         = regexp.MustCompile(`error mounting "([^"]+)" to rootfs at "([^"]+)"`)
+	startImageRefRE      = regexp.MustCompile(`(?:couldn't parse image (?:reference|name)|default image tag) "([^"]+)"`)
+	startInvalidFormatRE = regexp.MustCompile(`invalid reference format(?:: ([^"]+))?`)
+	startNeverPullRE     = regexp.MustCompile(`image "([^"]+)" is not present with pull policy of Never`)
+	startReservedRE      = regexp.MustCompile(`failed to reserve container name "([^"]+)"`)
+)
+
+// parseStartError reads the waiting or termination message of a container
+// that could not be created or started. It returns nil for an empty message.
+func parseStartError(message string) *StartError {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil
+	}
+
+	if m := startMissingKeyRE.FindStringSubmatch(message); m != nil {
+		return &StartError{Kind: m[2], Name: m[3], Key: m[1], Reason: "key not found"}
+	}
+	if m := startObjectMissingRE.FindStringSubmatch(message); m != nil {
+		kind := "Secret"
+		if strings.EqualFold(m[1], "configmap") {
+			kind = "ConfigMap"
+		}
+		return &StartError{Kind: kind, Name: m[2], Reason: "not found"}
+	}
+	if strings.Contains(message, "runAsNonRoot") {
+		se := &StartError{Kind: "SecurityContext", Reason: "image will run as root"}
+		if m := startNonNumericRE.FindStringSubmatch(message); m != nil {
+			se.Name, se.Reason = m[1], "image has a non-numeric user"
+		}
+		return se
+	}
+	if m := startNeverPullRE.FindStringSubmatch(message); m != nil {
+		return &StartError{Kind: "Image", Name: m[1], Reason: "not present on the node with imagePullPolicy Never"}
+	}
+	if m := startImageRefRE.FindStringSubmatch(message); m != nil {
+		se := &StartError{Kind: "Image", Name: m[1], Reason: "invalid reference format"}
+		if f := startInvalidFormatRE.FindStringSubmatch(message); f != nil && f[1] != "" {
+			se.Reason = "invalid reference format: " + strings.TrimSpace(f[1])
+		}
+		return se
+	}
+	if m := startExecRE.FindStringSubmatch(message); m != nil {
+		return &StartError{Kind: "Executable", Name: m[1], Reason: lastClause(message)}
+	}
+	if m := startMountRE.FindStringSubmatch(message); m != nil {
+		return &StartError{Kind: "Mount", Name: m[2], Reason: lastClause(message)}
+	}
+	if m := startReservedRE.FindStringSubmatch(message); m != nil {
+		return &StartError{Kind: "Runtime", Name: m[1], Reason: "container name is still reserved by an earlier attempt"}
+	}
+	if strings.Contains(message, "context deadline exceeded") {
+		return &StartError{Kind: "Runtime", Reason: "container runtime timed out"}
+	}
+	return &StartError{Kind: "Runtime", Reason: lastClause(message)}
+}
+
+// lastClause is the innermost cause of a wrapped runtime error, e.g.
+// "no such file or directory" from "a: b: no such file or directory".
+func lastClause(message string) string {
+	message = strings.TrimSuffix(strings.TrimSpace(message), ": unknown")
+	if i := strings.LastIndex(message, ": "); i >= 0 && i+2 < len(message) {
+		return message[i+2:]
+	}
+	return message
+}
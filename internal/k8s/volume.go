@@ -0,0 +1,232 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// VolumeStatus is one PersistentVolumeClaim a pod mounts, resolved through
+// its PersistentVolume and StorageClass. Fields past the point where the
+// chain could not be followed are empty.
+type VolumeStatus struct {
+	Volume              string // the pod's volume name
+	Claim               string
+	ClaimPhase          string // Pending, Bound or Lost; "Missing" when the claim does not exist
+	AccessModes         []string
+	PersistentVolume    string
+	Driver              string   // CSI driver of the bound volume
+	Zones               []string // zones the volume's node affinity allows
+	ZoneConflict        string   // zone of the pod's node when the volume cannot be used there
+	StorageClass        string
+	StorageClassMissing bool
+	Provisioner         string
+	BindingMode         string
+	ClaimEvents         []string // recent events of a claim that is not bound, e.g. ProvisioningFailed
+}
+
+// zoneLabels are the node labels and volume affinity keys that name a zone,
+// newest first. CSI drivers add their own "<driver>/zone" keys.
+var zoneLabels = []string{corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone}
+
+// resolveVolumes follows each claim the pod mounts, including generic
+// ephemeral volumes, to its volume and class.
+func resolveVolumes(ctx context.Context, lookup workloadLookup, pod corev1.Pod) []VolumeStatus {
+	var out []VolumeStatus
+	nodeZone := ""
+	for _, vol := range pod.Spec.Volumes {
+		claimName := ""
+		switch {
+		case vol.PersistentVolumeClaim != nil:
+			claimName = vol.PersistentVolumeClaim.ClaimName
+		case vol.Ephemeral != nil:
+			claimName = pod.Name + "-" + vol.Name
+		default:
+			continue
+		}
+		status := VolumeStatus{Volume: vol.Name, Claim: claimName}
+
+		claim, err := lookup.PersistentVolumeClaim(ctx, pod.Namespace, claimName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				status.ClaimPhase = "Missing"
+			}
+			out = append(out, status)
+			continue
+		}
+		status.ClaimPhase = string(claim.Status.Phase)
+		for _, mode := range claim.Spec.AccessModes {
+			status.AccessModes = append(status.AccessModes, string(mode))
+		}
+		if claim.Spec.StorageClassName != nil {
+			status.StorageClass = *claim.Spec.StorageClassName
+		}
+		if claim.Status.Phase != corev1.ClaimBound {
+			if events, err := lookup.ClaimEvents(ctx, pod.Namespace, claimName); err == nil {
+				status.ClaimEvents = recentEventMessages(events, 3)
+			}
+		}
+
+		if claim.Spec.VolumeName != "" {
+			status.PersistentVolume = claim.Spec.VolumeName
+			if pv, err := lookup.PersistentVolume(ctx, claim.Spec.VolumeName); err == nil {
+				if pv.Spec.CSI != nil {
+					status.Driver = pv.Spec.CSI.Driver
+				}
+				if status.StorageClass == "" {
+					status.StorageClass = pv.Spec.StorageClassName
+				}
+				status.Zones = volumeZones(pv)
+			}
+		}
+		if len(status.Zones) > 0 && pod.Spec.NodeName != "" {
+			if nodeZone == "" {
+				nodeZone = nodeZoneOf(ctx, lookup, pod.Spec.NodeName)
+			}
+			if nodeZone != "" && !containsFold(status.Zones, nodeZone) {
+				status.ZoneConflict = nodeZone
+			}
+		}
+
+		if status.StorageClass != "" {
+			class, err := lookup.StorageClass(ctx, status.StorageClass)
+			switch {
+			case err == nil:
+				status.Provisioner = class.Provisioner
+				status.BindingMode = string(storagev1.VolumeBindingImmediate)
+				if class.VolumeBindingMode != nil {
+					status.BindingMode = string(*class.VolumeBindingMode)
+				}
+			case apierrors.IsNotFound(err):
+				status.StorageClassMissing = true
+			}
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// volumeZones reads the zones a volume's required node affinity allows.
+func volumeZones(pv *corev1.PersistentVolume) []string {
+	seen := make(map[string]bool)
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if expr.Operator != corev1.NodeSelectorOpIn || !isZoneKey(expr.Key) {
+					continue
+				}
+				for _, zone := range expr.Values {
+					seen[zone] = true
+				}
+			}
+		}
+	}
+	for _, key := range zoneLabels {
+		if zone := pv.Labels[key]; zone != "" {
+			seen[zone] = true
+		}
+	}
+	zones := make([]string, 0, len(seen))
+	for zone := range seen {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+func isZoneKey(key string) bool {
+	for _, label := range zoneLabels {
+		if key == label {
+			return true
+		}
+	}
+	return strings.HasSuffix(key, "/zone")
+}
+
+func nodeZoneOf(ctx context.Context, lookup workloadLookup, nodeName string) string {
+	nodes, err := lookup.Nodes(ctx)
+	if err != nil {
+		return ""
+	}
+	for i := range nodes {
+		if nodes[i].Name != nodeName {
+			continue
+		}
+		for _, key := range zoneLabels {
+			if zone := nodes[i].Labels[key]; zone != "" {
+				return zone
+			}
+		}
+	}
+	return ""
+}
+
+// schedulingFailureType classifies an Unschedulable message. Claims that
+// cannot bind and volumes pinned to other zones are storage problems rather
+// than a lack of capacity.
+func schedulingFailureType(message string) FailureType {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "volume node affinity conflict"):
+		return FailureVolumeZone
+	case strings.Contains(lower, "unbound immediate persistentvolumeclaims"),
+		strings.Contains(lower, "didn't find available persistent volumes to bind"),
+		strings.Contains(lower, "persistentvolumeclaim") && strings.Contains(lower, "not found"):
+		return FailurePVCUnbound
+	}
+	return FailureFailedScheduling
+}
+
+// volumeEventType classifies a pod event about attaching or mounting a
+// volume, given in lower case as "reason: message". Mounts of missing
+// ConfigMaps and Secrets are left to their own types.
+func volumeEventType(lowerEvent string) FailureType {
+	switch {
+	case strings.Contains(lowerEvent, "multi-attach error"):
+		return FailureVolumeMultiAttach
+	case strings.HasPrefix(lowerEvent, "failedattachvolume"):
+		return FailureVolumeAttach
+	case strings.HasPrefix(lowerEvent, "failedmount"):
+		if strings.Contains(lowerEvent, "configmap") || strings.Contains(lowerEvent, "secret") {
+			return ""
+		}
+		if strings.Contains(lowerEvent, "timed out waiting") || strings.Contains(lowerEvent, "unable to attach or mount volumes") ||
+			strings.Contains(lowerEvent, "mountdevice failed") || strings.Contains(lowerEvent, "rpc error") {
+			return FailureVolumeMount
+		}
+	case strings.Contains(lowerEvent, "volume node affinity conflict"):
+		return FailureVolumeZone
+	}
+	return ""
+}
+
+// classifyVolumeFailure refines a pending pod's failure from its resolved
+// claims: an unbound or missing claim explains the wait, and so does a bound
+// volume in a zone other than the node's.
+func classifyVolumeFailure(failure *PodFailure) {
+	if !hasType(failure.Types, string(FailurePodPending)) && !hasType(failure.Types, string(FailureFailedScheduling)) {
+		return
+	}
+	for _, vol := range failure.Volumes {
+		var found FailureType
+		switch {
+		case vol.ClaimPhase == "Missing" || vol.ClaimPhase == string(corev1.ClaimLost):
+			found = FailurePVCUnbound
+		case vol.ClaimPhase == string(corev1.ClaimPending) && vol.BindingMode != string(storagev1.VolumeBindingWaitForFirstConsumer):
+			// A WaitForFirstConsumer claim stays Pending until the pod is
+			// scheduled; the scheduler message says when it is the blocker.
+			found = FailurePVCUnbound
+		case vol.ZoneConflict != "":
+			found = FailureVolumeZone
+		default:
+			continue
+		}
+		failure.Types = removeType(removeType(failure.Types, string(FailurePodPending)), string(FailureFailedScheduling))
+		failure.Types = appendType(failure.Types, string(found))
+		return
+	}
+}
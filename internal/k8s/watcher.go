@@ -0,0 +1,401 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const involvedObjectIndex = "involvedObject"
+
+// Watcher keeps informer caches for every object failure detection reads and
+// signals whenever a pod moves into, between, or out of failure states.
+type Watcher struct {
+	factory   informers.SharedInformerFactory
+	cs        kubernetes.Interface
+	namespace string
+	pods      corelisters.PodLister
+	lookup    listerLookup
+	synced    []cache.InformerSynced
+	changes   chan struct{}
+
+	mu   sync.RWMutex
+	opts Options
+}
+
+// listerLookup serves enrichment reads from informer caches.
+type listerLookup struct {
+	deployments  appslisters.DeploymentLister
+	replicaSets  appslisters.ReplicaSetLister
+	statefulSets appslisters.StatefulSetLister
+	daemonSets   appslisters.DaemonSetLister
+	jobs         batchlisters.JobLister
+	cronJobs     batchlisters.CronJobLister
+	services     corelisters.ServiceLister
+	nodes        corelisters.NodeLister
+	claims       corelisters.PersistentVolumeClaimLister
+	volumes      corelisters.PersistentVolumeLister
+	classes      storagelisters.StorageClassLister
+	events       cache.Indexer
+}
+
+// NewWatcher wires shared informers for pods, events, services, nodes,
+// volume claims and their volumes and classes, and every built-in workload
+// kind a pod can be owned by. resync is how often cached objects are
+// re-delivered to handlers.
+// Pods rejected by opts.Filter never trigger a report and are never enriched;
+// a single literal namespace also narrows every informer.
+func NewWatcher(cs kubernetes.Interface, resync time.Duration, opts Options) (*Watcher, error) {
+	namespace := opts.Filter.ListNamespace()
+	factory := informers.NewSharedInformerFactoryWithOptions(cs, resync, informers.WithNamespace(namespace))
+
+	podInformer := factory.Core().V1().Pods()
+	eventInformer := factory.Core().V1().Events()
+	serviceInformer := factory.Core().V1().Services()
+	nodeInformer := factory.Core().V1().Nodes()
+	claimInformer := factory.Core().V1().PersistentVolumeClaims()
+	volumeInformer := factory.Core().V1().PersistentVolumes()
+	classInformer := factory.Storage().V1().StorageClasses()
+	deploymentInformer := factory.Apps().V1().Deployments()
+	replicaSetInformer := factory.Apps().V1().ReplicaSets()
+	statefulSetInformer := factory.Apps().V1().StatefulSets()
+	daemonSetInformer := factory.Apps().V1().DaemonSets()
+	jobInformer := factory.Batch().V1().Jobs()
+	cronJobInformer := factory.Batch().V1().CronJobs()
+
+	if err := eventInformer.Informer().AddIndexers(cache.Indexers{involvedObjectIndex: indexEventByObject}); err != nil {
+		return nil, fmt.Errorf("add event index: %w", err)
+	}
+
+	w := &Watcher{
+		factory:   factory,
+		cs:        cs,
+		namespace: namespace,
+		opts:      opts,
+		pods:      podInformer.Lister(),
+		lookup: listerLookup{
+			deployments:  deploymentInformer.Lister(),
+			replicaSets:  replicaSetInformer.Lister(),
+			statefulSets: statefulSetInformer.Lister(),
+			daemonSets:   daemonSetInformer.Lister(),
+			jobs:         jobInformer.Lister(),
+			cronJobs:     cronJobInformer.Lister(),
+			services:     serviceInformer.Lister(),
+			nodes:        nodeInformer.Lister(),
+			claims:       claimInformer.Lister(),
+			volumes:      volumeInformer.Lister(),
+			classes:      classInformer.Lister(),
+			events:       eventInformer.Informer().GetIndexer(),
+		},
+		synced: []cache.InformerSynced{
+			podInformer.Informer().HasSynced,
+			eventInformer.Informer().HasSynced,
+			serviceInformer.Informer().HasSynced,
+			nodeInformer.Informer().HasSynced,
+			claimInformer.Informer().HasSynced,
+			volumeInformer.Informer().HasSynced,
+			classInformer.Informer().HasSynced,
+			deploymentInformer.Informer().HasSynced,
+			replicaSetInformer.Informer().HasSynced,
+			statefulSetInformer.Informer().HasSynced,
+			daemonSetInformer.Informer().HasSynced,
+			jobInformer.Informer().HasSynced,
+			cronJobInformer.Informer().HasSynced,
+		},
+		changes: make(chan struct{}, 1),
+	}
+
+	// Jobs are watched for their terminal conditions, which the job
+	// controller sets after the pod changes that led to them.
+	_, err := jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldJob, oldOK := oldObj.(*batchv1.Job)
+			newJob, newOK := newObj.(*batchv1.Job)
+			if !oldOK || !newOK {
+				return
+			}
+			if jobSignature(oldJob) != jobSignature(newJob) && w.options().Filter.NamespaceAllowed(newJob.Namespace) {
+				w.notify()
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add job handler: %w", err)
+	}
+
+	_, err = nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, oldOK := oldObj.(*corev1.Node)
+			newNode, newOK := newObj.(*corev1.Node)
+			if !oldOK || !newOK {
+				return
+			}
+			if strings.Join(nodeProblems(oldNode), ",") != strings.Join(nodeProblems(newNode), ",") {
+				w.notify()
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add node handler: %w", err)
+	}
+
+	_, err = podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok && w.signature(pod) != "" {
+				w.notify()
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, oldOK := oldObj.(*corev1.Pod)
+			newPod, newOK := newObj.(*corev1.Pod)
+			if !oldOK || !newOK {
+				return
+			}
+			if w.signature(oldPod) != w.signature(newPod) {
+				w.notify()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*corev1.Pod); ok && w.signature(pod) != "" {
+				w.notify()
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add pod handler: %w", err)
+	}
+
+	return w, nil
+}
+
+// Start runs the informers until ctx is cancelled and blocks until the
+// initial list of every cache has completed.
+func (w *Watcher) Start(ctx context.Context) error {
+	w.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), w.synced...) {
+		return errors.New("informer caches did not sync")
+	}
+	return nil
+}
+
+// Changes delivers a coalesced signal each time a pod's failure state changes.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// FailedPods runs the same detection and enrichment as GetFailedPods, but
+// from informer caches. Log tails, which are never cached, still come from
+// the API server.
+func (w *Watcher) FailedPods(ctx context.Context) ([]PodFailure, error) {
+	cached, err := w.pods.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list cached pods: %w", err)
+	}
+	pods := make([]corev1.Pod, 0, len(cached))
+	for _, p := range cached {
+		pods = append(pods, *p)
+	}
+	return collectFailures(ctx, w.cs, w.lookup, pods, w.options())
+}
+
+// Namespace is the namespace the informers are scoped to; empty means all.
+func (w *Watcher) Namespace() string {
+	return w.namespace
+}
+
+// SetOptions swaps the options used by later detections and change signals.
+// The informers' namespace scope is fixed at construction, so callers must
+// build a new Watcher when opts.Filter.ListNamespace() differs from Namespace.
+func (w *Watcher) SetOptions(opts Options) {
+	w.mu.Lock()
+	w.opts = opts
+	w.mu.Unlock()
+}
+
+func (w *Watcher) options() Options {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.opts
+}
+
+func (w *Watcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+// signature is the failure signature of pods the filter admits; filtered-out
+// pods read as healthy, so a pod relabelled out of scope still clears.
+func (w *Watcher) signature(pod *corev1.Pod) string {
+	if !w.options().Filter.Allows(context.Background(), w.lookup, pod) {
+		return ""
+	}
+	return failureSignature(pod)
+}
+
+// failureSignature summarises the failure types DetectFailures sees for a
+// pod, so handlers only fire when something a report would show has changed.
+func failureSignature(pod *corev1.Pod) string {
+	failures := DetectFailures(*pod)
+	if len(failures) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(failures))
+	for _, f := range failures {
+		parts = append(parts, f.Container+"="+strings.Join(f.Types, ","))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
+
+// jobSignature is the Job's terminal state, empty while it is running.
+func jobSignature(job *batchv1.Job) string {
+	if failureType, _, _ := jobFailure(job); failureType != "" {
+		return failureType
+	}
+	if jobComplete(job) {
+		return "Complete"
+	}
+	return ""
+}
+
+// indexEventByObject keys events by "Kind/namespace/name" of the object
+// they are about, for the kinds enrichment reads events of.
+func indexEventByObject(obj interface{}) ([]string, error) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil, nil
+	}
+	switch event.InvolvedObject.Kind {
+	case "Pod", "PersistentVolumeClaim":
+		return []string{event.InvolvedObject.Kind + "/" + event.Namespace + "/" + event.InvolvedObject.Name}, nil
+	}
+	return nil, nil
+}
+
+func (l listerLookup) Deployment(_ context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	return l.deployments.Deployments(namespace).Get(name)
+}
+
+func (l listerLookup) ReplicaSet(_ context.Context, namespace, name string) (*appsv1.ReplicaSet, error) {
+	return l.replicaSets.ReplicaSets(namespace).Get(name)
+}
+
+func (l listerLookup) StatefulSet(_ context.Context, namespace, name string) (*appsv1.StatefulSet, error) {
+	return l.statefulSets.StatefulSets(namespace).Get(name)
+}
+
+func (l listerLookup) DaemonSet(_ context.Context, namespace, name string) (*appsv1.DaemonSet, error) {
+	return l.daemonSets.DaemonSets(namespace).Get(name)
+}
+
+func (l listerLookup) Job(_ context.Context, namespace, name string) (*batchv1.Job, error) {
+	return l.jobs.Jobs(namespace).Get(name)
+}
+
+func (l listerLookup) CronJob(_ context.Context, namespace, name string) (*batchv1.CronJob, error) {
+	return l.cronJobs.CronJobs(namespace).Get(name)
+}
+
+func (l listerLookup) Jobs(_ context.Context, namespace string) ([]batchv1.Job, error) {
+	cached, err := l.jobs.Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]batchv1.Job, 0, len(cached))
+	for _, job := range cached {
+		out = append(out, *job)
+	}
+	return out, nil
+}
+
+func (l listerLookup) CronJobs(_ context.Context, namespace string) ([]batchv1.CronJob, error) {
+	cached, err := l.cronJobs.CronJobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]batchv1.CronJob, 0, len(cached))
+	for _, cronJob := range cached {
+		out = append(out, *cronJob)
+	}
+	return out, nil
+}
+
+func (l listerLookup) Services(_ context.Context, namespace string) ([]corev1.Service, error) {
+	cached, err := l.services.Services(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]corev1.Service, 0, len(cached))
+	for _, svc := range cached {
+		out = append(out, *svc)
+	}
+	return out, nil
+}
+
+func (l listerLookup) Nodes(_ context.Context) ([]corev1.Node, error) {
+	cached, err := l.nodes.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]corev1.Node, 0, len(cached))
+	for _, node := range cached {
+		out = append(out, *node)
+	}
+	return out, nil
+}
+
+func (l listerLookup) PersistentVolumeClaim(_ context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	return l.claims.PersistentVolumeClaims(namespace).Get(name)
+}
+
+func (l listerLookup) PersistentVolume(_ context.Context, name string) (*corev1.PersistentVolume, error) {
+	return l.volumes.Get(name)
+}
+
+func (l listerLookup) StorageClass(_ context.Context, name string) (*storagev1.StorageClass, error) {
+	return l.classes.Get(name)
+}
+
+func (l listerLookup) PodEvents(_ context.Context, namespace, podName string) ([]corev1.Event, error) {
+	return l.involvedEvents("Pod", namespace, podName)
+}
+
+func (l listerLookup) ClaimEvents(_ context.Context, namespace, claimName string) ([]corev1.Event, error) {
+	return l.involvedEvents("PersistentVolumeClaim", namespace, claimName)
+}
+
+func (l listerLookup) involvedEvents(kind, namespace, name string) ([]corev1.Event, error) {
+	cached, err := l.events.ByIndex(involvedObjectIndex, kind+"/"+namespace+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]corev1.Event, 0, len(cached))
+	for _, obj := range cached {
+		if event, ok := obj.(*corev1.Event); ok {
+			out = append(out, *event)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// workloadStatus describes the top-level controller that owns a pod.
+type workloadStatus struct {
+	Kind      string
+	Name      string
+	Revision  string // deployment revision, controller-revision-hash or Job generation
+	Readiness string // kind-specific, e.g. "2/3 replicas ready"
+}
+
+// resolveWorkload walks the pod's owner references up to its top-level
+// controller: ReplicaSet to Deployment and Job to CronJob, with StatefulSets
+// and DaemonSets owning their pods directly. Owners of other kinds, such as
+// custom controllers, are reported by kind and name only. When an owner
+// cannot be read, the walk stops at the last owner it knows.
+func resolveWorkload(ctx context.Context, lookup workloadLookup, pod corev1.Pod) workloadStatus {
+	owner := controllerOf(pod.OwnerReferences)
+	if owner == nil {
+		return workloadStatus{}
+	}
+	ns := pod.Namespace
+	status := workloadStatus{Kind: owner.Kind, Name: owner.Name}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		rs, err := lookup.ReplicaSet(ctx, ns, owner.Name)
+		if err != nil {
+			return workloadStatus{}
+		}
+		if parent := controllerOf(rs.OwnerReferences); parent != nil && parent.Kind == "Deployment" {
+			return deploymentStatus(ctx, lookup, ns, parent.Name)
+		}
+		status.Revision = rs.Annotations["deployment.kubernetes.io/revision"]
+		status.Readiness = replicaReadiness(rs.Status.ReadyReplicas, rs.Spec.Replicas)
+	case "Deployment":
+		return deploymentStatus(ctx, lookup, ns, owner.Name)
+	case "StatefulSet":
+		status.Revision = pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+		sts, err := lookup.StatefulSet(ctx, ns, owner.Name)
+		if err != nil {
+			return status
+		}
+		status.Readiness = replicaReadiness(sts.Status.ReadyReplicas, sts.Spec.Replicas)
+	case "DaemonSet":
+		status.Revision = pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+		ds, err := lookup.DaemonSet(ctx, ns, owner.Name)
+		if err != nil {
+			return status
+		}
+		status.Readiness = fmt.Sprintf("%d/%d nodes ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+	case "Job":
+		job, err := lookup.Job(ctx, ns, owner.Name)
+		if err != nil {
+			return status
+		}
+		return jobWorkload(job)
+	}
+	return status
+}
+
+// jobWorkload describes a Job, or its CronJob when it has one: a CronJob run
+// is diagnosed as the CronJob, though the Job's own progress is still the
+// readiness that matters.
+func jobWorkload(job *batchv1.Job) workloadStatus {
+	status := workloadStatus{Kind: "Job", Name: job.Name, Readiness: jobReadiness(job)}
+	if job.Generation > 0 {
+		status.Revision = strconv.FormatInt(job.Generation, 10)
+	}
+	if parent := controllerOf(job.OwnerReferences); parent != nil && parent.Kind == "CronJob" {
+		status.Kind, status.Name = parent.Kind, parent.Name
+		status.Readiness = "job " + job.Name + ": " + status.Readiness
+	}
+	return status
+}
+
+func deploymentStatus(ctx context.Context, lookup workloadLookup, namespace, name string) workloadStatus {
+	status := workloadStatus{Kind: "Deployment", Name: name}
+	dep, err := lookup.Deployment(ctx, namespace, name)
+	if err != nil {
+		return status
+	}
+	status.Revision = dep.Annotations["deployment.kubernetes.io/revision"]
+	status.Readiness = replicaReadiness(dep.Status.ReadyReplicas, dep.Spec.Replicas)
+	return status
+}
+
+// controllerOf returns the managing owner, falling back to the first owner
+// for objects created by tools that do not set the controller flag.
+func controllerOf(owners []metav1.OwnerReference) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOfNoCopy(&metav1.ObjectMeta{OwnerReferences: owners}); ref != nil {
+		return ref
+	}
+	if len(owners) > 0 {
+		return &owners[0]
+	}
+	return nil
+}
+
+func replicaReadiness(ready int32, replicas *int32) string {
+	desired := int32(1)
+	if replicas != nil {
+		desired = *replicas
+	}
+	return fmt.Sprintf("%d/%d replicas ready", ready, desired)
+}
+
+func jobReadiness(job *batchv1.Job) string {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+	return fmt.Sprintf("%d/%d completions, %d active, %d failed", job.Status.Succeeded, completions, job.Status.Active, job.Status.Failed)
+}
@@ -0,0 +1,143 @@
+// Package redact scrubs secrets out of free-form text (container logs, event
+// messages, commands) before it leaves the cluster.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const placeholder = "[REDACTED]"
+
+// CustomDetector names matches of user-supplied patterns in counts.
+const CustomDetector = "custom"
+
+// builtinDetectors catch the credentials most often printed by crashing apps
+// or passed on command lines. Where a pattern has a capture group, only the
+// group is replaced so the surrounding key stays readable. Order matters:
+// specific detectors run before the generic key=value one, so a token is
+// counted under what it is rather than where it appeared.
+var builtinDetectors = []struct {
+	name    string
+	pattern string
+}{
+	{"url-credentials", `(?i)\b[a-z][a-z0-9+.-]*://[^:/\s]+:([^@\s]+)@`},
+	{"bearer-token", `(?i)\bbearer\s+([a-z0-9\-._~+/]+=*)`},
+	{"jwt", `\b(eyJ[a-zA-Z0-9_-]{8,}\.[a-zA-Z0-9_-]{8,}\.[a-zA-Z0-9_-]{8,})\b`},
+	{"aws-access-key", `\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`},
+	{"aws-secret-key", `(?i)\baws_?secret_?access_?key\b["']?\s*[:=]\s*["']?([a-z0-9/+=]{40})\b`},
+	{"secret-assignment", `(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)\b["']?\s*[:=]\s*["']?([^\s"',;]+)`},
+}
+
+type detector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Redactor replaces matches of its detectors with a fixed placeholder.
+type Redactor struct {
+	detectors []detector
+}
+
+// New builds a Redactor from the built-in detectors plus extra, which use Go
+// regexp syntax and are counted as CustomDetector.
+func New(extra []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, d := range builtinDetectors {
+		r.detectors = append(r.detectors, detector{name: d.name, re: regexp.MustCompile(d.pattern)})
+	}
+	for _, p := range extra {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("compile redaction pattern %q: %w", p, err)
+		}
+		r.detectors = append(r.detectors, detector{name: CustomDetector, re: re})
+	}
+	return r, nil
+}
+
+// String returns s with every match redacted. A nil Redactor returns s unchanged.
+func (r *Redactor) String(s string) string {
+	return r.Redact(s, nil)
+}
+
+// Redact returns s with every match redacted and adds one to counts, keyed
+// by detector name, per value replaced. counts may be nil.
+func (r *Redactor) Redact(s string, counts map[string]int) string {
+	if r == nil {
+		return s
+	}
+	for _, d := range r.detectors {
+		s = replace(d, s, counts)
+	}
+	return s
+}
+
+// Lines redacts each line in place and returns the slice.
+func (r *Redactor) Lines(lines []string) []string {
+	for i, line := range lines {
+		lines[i] = r.String(line)
+	}
+	return lines
+}
+
+func replace(d detector, s string, counts map[string]int) string {
+	return d.re.ReplaceAllStringFunc(s, func(match string) string {
+		start, end := 0, len(match)
+		if d.re.NumSubexp() > 0 {
+			loc := d.re.FindStringSubmatchIndex(match)
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+		}
+		// Already scrubbed by an earlier detector, e.g. token=<jwt>.
+		if match[start:end] == placeholder {
+			return match
+		}
+		if counts != nil {
+			counts[d.name]++
+		}
+		return match[:start] + placeholder + match[end:]
+	})
+}
+
+// Summary records what was scrubbed from one report, so what left the
+// cluster can be audited without seeing the values themselves.
+type Summary struct {
+	Total         int            `json:"total"`
+	ByDetector    map[string]int `json:"byDetector,omitempty"`
+	ByField       map[string]int `json:"byField,omitempty"`
+	DroppedFields []string       `json:"droppedFields,omitempty"`
+}
+
+// Add records counts, as filled in by Redact, against field.
+func (s *Summary) Add(field string, counts map[string]int) {
+	for name, n := range counts {
+		if n == 0 {
+			continue
+		}
+		if s.ByDetector == nil {
+			s.ByDetector = make(map[string]int)
+			s.ByField = make(map[string]int)
+		}
+		s.ByDetector[name] += n
+		s.ByField[field] += n
+		s.Total += n
+	}
+}
+
+// Empty reports whether nothing was redacted or dropped.
+func (s Summary) Empty() bool {
+	return s.Total == 0 && len(s.DroppedFields) == 0
+}
+
+// Detectors returns the names counted in a Summary's ByDetector, sorted.
+func (s Summary) Detectors() []string {
+	names := make([]string, 0, len(s.ByDetector))
+	for name := range s.ByDetector {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -40,6 +40,11 @@ func NewPostgresStore(ctx context.Context, databaseURL string) (*PostgresStore,
 	return store, nil
 }
 
+// Close releases the connection pool once in-flight queries have finished.
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
 func (s *PostgresStore) ensureSchema(ctx context.Context) error {
 	const schema = `
 CREATE TABLE IF NOT EXISTS clusters (
@@ -72,6 +77,7 @@ ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT 'legacy
 ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true;
 ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
 ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS signing_secret TEXT;
 
 CREATE INDEX IF NOT EXISTS idx_api_keys_key_hash
 	ON api_keys(key_hash) WHERE active = true;
@@ -109,6 +115,92 @@ ALTER TABLE diagnoses ADD COLUMN IF NOT EXISTS restart_count INTEGER NOT NULL DE
 
 CREATE INDEX IF NOT EXISTS idx_diagnoses_cluster_created_at
 	ON diagnoses(cluster_id, created_at DESC);
+
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS watch_filters JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- The delta report sequence last applied, per organization and cluster;
+-- cluster IDs are chosen by agents and only unique within an organization.
+CREATE TABLE IF NOT EXISTS cluster_report_sequences (
+	organization_id TEXT NOT NULL,
+	cluster_id TEXT NOT NULL,
+	report_sequence BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (organization_id, cluster_id)
+);
+
+CREATE TABLE IF NOT EXISTS cluster_failure_state (
+	organization_id TEXT NOT NULL,
+	cluster_id TEXT NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+	failure_key TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	failure JSONB NOT NULL DEFAULT '{}'::jsonb,
+	first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	observations INTEGER NOT NULL DEFAULT 1,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (organization_id, cluster_id, failure_key)
+);
+
+CREATE TABLE IF NOT EXISTS agent_report_chunks (
+	organization_id TEXT NOT NULL,
+	cluster_id TEXT NOT NULL,
+	report_id TEXT NOT NULL,
+	chunk_index INTEGER NOT NULL,
+	chunk_count INTEGER NOT NULL,
+	body JSONB NOT NULL,
+	received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (organization_id, cluster_id, report_id, chunk_index)
+);
+
+CREATE TABLE IF NOT EXISTS agent_reports (
+	organization_id TEXT NOT NULL,
+	cluster_id TEXT NOT NULL,
+	report_id TEXT NOT NULL,
+	processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (organization_id, cluster_id, report_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_reports_processed_at
+	ON agent_reports(processed_at);
+
+CREATE TABLE IF NOT EXISTS report_nonces (
+	key_hash TEXT NOT NULL,
+	nonce TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (key_hash, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_nonces_expires_at
+	ON report_nonces(expires_at);
+
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS agent_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS agent_features JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS registered_at TIMESTAMPTZ;
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS config_version BIGINT NOT NULL DEFAULT 0;
+
+CREATE SEQUENCE IF NOT EXISTS agent_config_version_seq;
+
+-- cluster_id '' holds the organization default.
+CREATE TABLE IF NOT EXISTS agent_configs (
+	organization_id TEXT NOT NULL,
+	cluster_id TEXT NOT NULL DEFAULT '',
+	config JSONB NOT NULL DEFAULT '{}'::jsonb,
+	version BIGINT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (organization_id, cluster_id)
+);
+
+CREATE TABLE IF NOT EXISTS report_redactions (
+	id BIGSERIAL PRIMARY KEY,
+	organization_id TEXT NOT NULL,
+	cluster_id TEXT NOT NULL,
+	report_id TEXT NOT NULL DEFAULT '',
+	summary JSONB NOT NULL,
+	received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_redactions_cluster_received_at
+	ON report_redactions(organization_id, cluster_id, received_at DESC);
 `
 
 	if _, err := s.db.ExecContext(ctx, schema); err != nil {
@@ -152,10 +244,21 @@ func generateAPIKey() (string, error) {
 	return "kr_live_" + hex.EncodeToString(randomBytes), nil
 }
 
-func (s *PostgresStore) CreateAPIKey(ctx context.Context, organizationID, name string) (string, error) {
+func generateSigningSecret() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("generate random signing secret bytes: %w", err)
+	}
+
+	return "krs_" + hex.EncodeToString(randomBytes), nil
+}
+
+// CreateAPIKey returns a new raw API key and the secret the agent signs
+// reports with. Neither can be retrieved again.
+func (s *PostgresStore) CreateAPIKey(ctx context.Context, organizationID, name string) (string, string, error) {
 	orgID := strings.TrimSpace(organizationID)
 	if orgID == "" {
-		return "", fmt.Errorf("organization_id is required")
+		return "", "", fmt.Errorf("organization_id is required")
 	}
 
 	keyName := strings.TrimSpace(name)
@@ -163,33 +266,89 @@ func (s *PostgresStore) CreateAPIKey(ctx context.Context, organizationID, name s
 		keyName = fmt.Sprintf("generated-%d", time.Now().Unix())
 	}
 
+	signingSecret, err := generateSigningSecret()
+	if err != nil {
+		return "", "", err
+	}
+
 	for attempts := 0; attempts < 3; attempts++ {
 		rawKey, err := generateAPIKey()
 		if err != nil {
-			return "", err
+			return "", "", err
 		}
 
 		keyHash := hashAPIKey(rawKey)
 		_, err = s.db.ExecContext(
 			ctx,
-			`INSERT INTO api_keys (organization_id, key_hash, name, active)
-			 VALUES ($1, $2, $3, true)`,
+			`INSERT INTO api_keys (organization_id, key_hash, name, active, signing_secret)
+			 VALUES ($1, $2, $3, true, $4)`,
 			orgID,
 			keyHash,
 			keyName,
+			signingSecret,
 		)
 		if err == nil {
-			return rawKey, nil
+			return rawKey, signingSecret, nil
 		}
 
 		if strings.Contains(strings.ToLower(err.Error()), "duplicate") {
 			continue
 		}
 
-		return "", fmt.Errorf("create api key: %w", err)
+		return "", "", fmt.Errorf("create api key: %w", err)
+	}
+
+	return "", "", fmt.Errorf("create api key: failed after retries")
+}
+
+// SigningSecret returns the report signing secret of an active key, or ""
+// for keys created before reports were signed.
+func (s *PostgresStore) SigningSecret(ctx context.Context, keyHash string) (string, error) {
+	var secret sql.NullString
+	err := s.db.QueryRowContext(
+		ctx,
+		`SELECT signing_secret FROM api_keys WHERE key_hash = $1 AND active = true`,
+		keyHash,
+	).Scan(&secret)
+
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("invalid or inactive API key")
+	}
+	if err != nil {
+		return "", fmt.Errorf("load signing secret: %w", err)
+	}
+
+	return secret.String, nil
+}
+
+// UseNonce records a signed request's nonce and reports whether it was new.
+// Nonces are kept until expiresAt, after which the request's timestamp is
+// stale anyway.
+func (s *PostgresStore) UseNonce(ctx context.Context, keyHash, nonce string, expiresAt time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO report_nonces (key_hash, nonce, expires_at)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT DO NOTHING`,
+		keyHash,
+		nonce,
+		expiresAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("record nonce: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("record nonce: %w", err)
 	}
 
-	return "", fmt.Errorf("create api key: failed after retries")
+	if _, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM report_nonces WHERE expires_at < NOW()`,
+	); err != nil {
+		return false, fmt.Errorf("expire nonces: %w", err)
+	}
+	return inserted == 1, nil
 }
 
 func (s *PostgresStore) ListDiagnoses(ctx context.Context, organizationID, clusterID string, filter DiagnosisHistoryFilter) ([]analyzer.Diagnosis, error) {
@@ -327,55 +486,75 @@ func (s *PostgresStore) ListCurrentFailures(ctx context.Context, organizationID,
 		limit = 100
 	}
 
-	whereClauses := []string{"organization_id = $1", "cluster_id = $2"}
+	whereClauses := []string{"d.organization_id = $1", "d.cluster_id = $2"}
 	args := []any{organizationID, clusterID}
 
 	if filter.FailureType != "" {
 		args = append(args, filter.FailureType)
-		whereClauses = append(whereClauses, fmt.Sprintf("failure_type = $%d", len(args)))
+		whereClauses = append(whereClauses, fmt.Sprintf("d.failure_type = $%d", len(args)))
 	}
 
 	if filter.Namespace != "" {
 		args = append(args, filter.Namespace)
-		whereClauses = append(whereClauses, fmt.Sprintf("namespace = $%d", len(args)))
+		whereClauses = append(whereClauses, fmt.Sprintf("d.namespace = $%d", len(args)))
 	}
 
 	if filter.Since != nil {
 		args = append(args, *filter.Since)
-		whereClauses = append(whereClauses, fmt.Sprintf("created_at >= $%d", len(args)))
+		whereClauses = append(whereClauses, fmt.Sprintf("d.created_at >= $%d", len(args)))
 	}
 
 	if filter.Until != nil {
 		args = append(args, *filter.Until)
-		whereClauses = append(whereClauses, fmt.Sprintf("created_at <= $%d", len(args)))
+		whereClauses = append(whereClauses, fmt.Sprintf("d.created_at <= $%d", len(args)))
 	}
 
 	args = append(args, limit)
 	limitArgPosition := len(args)
 
-	query := fmt.Sprintf(`WITH filtered AS (
+	// Clusters on the delta protocol only re-send failures that changed, so
+	// their diagnoses stop at the last change. For them the failure state is
+	// what is current: only diagnoses of failures still in it are listed, from
+	// the time the failure appeared, and last seen and occurrences come from
+	// the state, which every applied report refreshes. Clusters sending full
+	// reports have no state and are read from the diagnoses alone.
+	query := fmt.Sprintf(`WITH tracked AS (
+		SELECT EXISTS (
+			SELECT 1 FROM cluster_report_sequences
+			WHERE organization_id = $1 AND cluster_id = $2
+		) AS delta
+	), filtered AS (
 		SELECT
-			organization_id,
-			cluster_id,
-			pod_name,
-			namespace,
-			container,
-			image,
-			restart_count,
-			failure_type,
-			likely_cause,
-			suggested_fix,
-			confidence,
-			confidence_note,
-			evidence,
-			fix_suggestions,
-			quick_commands,
-			diag_context,
-			events,
-			created_at,
-			namespace || '/' || pod_name || '/' || failure_type AS issue_key
-		FROM diagnoses
+			d.organization_id,
+			d.cluster_id,
+			d.pod_name,
+			d.namespace,
+			d.container,
+			d.image,
+			d.restart_count,
+			d.failure_type,
+			d.likely_cause,
+			d.suggested_fix,
+			d.confidence,
+			d.confidence_note,
+			d.evidence,
+			d.fix_suggestions,
+			d.quick_commands,
+			d.diag_context,
+			d.events,
+			d.created_at,
+			d.namespace || '/' || d.pod_name || '/' || d.failure_type AS issue_key,
+			s.first_seen_at AS state_first_seen,
+			s.last_seen_at AS state_last_seen,
+			s.observations AS state_observations
+		FROM diagnoses d
+		LEFT JOIN cluster_failure_state s
+			ON s.organization_id = d.organization_id
+			AND s.cluster_id = d.cluster_id
+			AND s.failure_key = d.namespace || '/' || d.pod_name || '/' || d.container
+			AND d.created_at >= s.first_seen_at
 		WHERE %s
+		  AND (s.failure_key IS NOT NULL OR NOT (SELECT delta FROM tracked))
 	), latest AS (
 		SELECT DISTINCT ON (issue_key)
 			issue_key,
@@ -402,9 +581,9 @@ func (s *PostgresStore) ListCurrentFailures(ctx context.Context, organizationID,
 	), agg AS (
 		SELECT
 			issue_key,
-			MIN(created_at) AS first_seen,
-			MAX(created_at) AS last_seen,
-			COUNT(*) AS occurrences,
+			MIN(COALESCE(state_first_seen, created_at)) AS first_seen,
+			MAX(GREATEST(created_at, state_last_seen)) AS last_seen,
+			GREATEST(COUNT(*), MAX(state_observations)) AS occurrences,
 			MIN(restart_count) AS min_restart,
 			MAX(restart_count) AS max_restart
 		FROM filtered
@@ -664,7 +843,22 @@ func (s *PostgresStore) SaveDiagnoses(ctx context.Context, organizationID, clust
 		_ = tx.Rollback()
 	}()
 
-	// Upsert cluster: set last_seen_at, maintain first_seen_at
+	if err := upsertCluster(ctx, tx, organizationID, clusterID); err != nil {
+		return err
+	}
+	if err := insertDiagnoses(ctx, tx, organizationID, clusterID, diagnoses); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// upsertCluster sets last_seen_at and maintains first_seen_at.
+func upsertCluster(ctx context.Context, tx *sql.Tx, organizationID, clusterID string) error {
 	if _, err := tx.ExecContext(
 		ctx,
 		`INSERT INTO clusters (id, organization_id, first_seen_at, last_seen_at, active)
@@ -676,11 +870,11 @@ func (s *PostgresStore) SaveDiagnoses(ctx context.Context, organizationID, clust
 	); err != nil {
 		return fmt.Errorf("upsert cluster: %w", err)
 	}
+	return nil
+}
 
+func insertDiagnoses(ctx context.Context, tx *sql.Tx, organizationID, clusterID string, diagnoses []analyzer.Diagnosis) error {
 	if len(diagnoses) == 0 {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("commit tx: %w", err)
-		}
 		return nil
 	}
 
@@ -749,10 +943,6 @@ func (s *PostgresStore) SaveDiagnoses(ctx context.Context, organizationID, clust
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit tx: %w", err)
-	}
-
 	return nil
 }
 
@@ -772,6 +962,169 @@ func (s *PostgresStore) RegisterCluster(ctx context.Context, organizationID, clu
 	return nil
 }
 
+func (s *PostgresStore) SaveClusterFilters(ctx context.Context, organizationID, clusterID string, filters json.RawMessage) error {
+	if len(filters) == 0 || string(filters) == "null" {
+		filters = json.RawMessage(`{}`)
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO clusters (id, organization_id, first_seen_at, last_seen_at, active, watch_filters)
+		 VALUES ($1, $2, NOW(), NOW(), true, $3)
+		 ON CONFLICT (id)
+		 DO UPDATE SET last_seen_at = NOW(), active = true, watch_filters = EXCLUDED.watch_filters`,
+		clusterID,
+		organizationID,
+		[]byte(filters),
+	)
+	if err != nil {
+		return fmt.Errorf("save cluster filters: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ListClusters(ctx context.Context, organizationID string) ([]ClusterSummary, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT c.id, c.first_seen_at, c.last_seen_at, c.active, c.watch_filters,
+		   c.agent_version, c.agent_features, c.registered_at, c.config_version,
+		   GREATEST(COALESCE(org.version, 0), COALESCE(own.version, 0))
+		 FROM clusters c
+		 LEFT JOIN agent_configs org ON org.organization_id = c.organization_id AND org.cluster_id = ''
+		 LEFT JOIN agent_configs own ON own.organization_id = c.organization_id AND own.cluster_id = c.id
+		 WHERE c.organization_id = $1
+		 ORDER BY c.last_seen_at DESC`,
+		organizationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list clusters query: %w", err)
+	}
+	defer rows.Close()
+
+	clusters := make([]ClusterSummary, 0)
+	for rows.Next() {
+		var c ClusterSummary
+		var filters, features []byte
+		var registeredAt sql.NullTime
+		if scanErr := rows.Scan(&c.ID, &c.FirstSeen, &c.LastSeen, &c.Active, &filters,
+			&c.AgentVersion, &features, &registeredAt, &c.ConfigVersion, &c.DesiredConfigVersion); scanErr != nil {
+			return nil, fmt.Errorf("scan cluster row: %w", scanErr)
+		}
+		c.Filters = json.RawMessage(filters)
+		if err := json.Unmarshal(features, &c.AgentFeatures); err != nil {
+			return nil, fmt.Errorf("decode agent features for %s: %w", c.ID, err)
+		}
+		if registeredAt.Valid {
+			c.RegisteredAt = &registeredAt.Time
+		}
+		clusters = append(clusters, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cluster rows: %w", err)
+	}
+	return clusters, nil
+}
+
+func (s *PostgresStore) RegisterAgent(ctx context.Context, organizationID, clusterID string, registration AgentRegistration) error {
+	features := registration.Features
+	if features == nil {
+		features = []string{}
+	}
+	featuresJSON, err := json.Marshal(features)
+	if err != nil {
+		return fmt.Errorf("marshal agent features: %w", err)
+	}
+	_, err = s.db.ExecContext(
+		ctx,
+		`INSERT INTO clusters (id, organization_id, first_seen_at, last_seen_at, active, agent_version, agent_features, registered_at, config_version)
+		 VALUES ($1, $2, NOW(), NOW(), true, $3, $4, NOW(), $5)
+		 ON CONFLICT (id)
+		 DO UPDATE SET last_seen_at = NOW(), active = true, agent_version = EXCLUDED.agent_version,
+		   agent_features = EXCLUDED.agent_features, registered_at = NOW(), config_version = EXCLUDED.config_version`,
+		clusterID,
+		organizationID,
+		registration.AgentVersion,
+		featuresJSON,
+		registration.ConfigVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("register agent: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) SaveClusterConfigVersion(ctx context.Context, organizationID, clusterID string, version int64) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`UPDATE clusters SET config_version = $3
+		 WHERE id = $1 AND organization_id = $2 AND config_version <> $3`,
+		clusterID,
+		organizationID,
+		version,
+	)
+	if err != nil {
+		return fmt.Errorf("save cluster config version: %w", err)
+	}
+	return nil
+}
+
+// LoadAgentConfig returns the organization default and the cluster's own
+// config layer, if set.
+func (s *PostgresStore) LoadAgentConfig(ctx context.Context, organizationID, clusterID string) (AgentConfigSet, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT cluster_id, config, version, updated_at
+		 FROM agent_configs
+		 WHERE organization_id = $1 AND cluster_id IN ('', $2)`,
+		organizationID,
+		clusterID,
+	)
+	if err != nil {
+		return AgentConfigSet{}, fmt.Errorf("load agent config query: %w", err)
+	}
+	defer rows.Close()
+
+	var set AgentConfigSet
+	for rows.Next() {
+		var layerCluster string
+		var config []byte
+		layer := &AgentConfigLayer{}
+		if scanErr := rows.Scan(&layerCluster, &config, &layer.Version, &layer.UpdatedAt); scanErr != nil {
+			return AgentConfigSet{}, fmt.Errorf("scan agent config row: %w", scanErr)
+		}
+		layer.Config = json.RawMessage(config)
+		if layerCluster == "" {
+			set.Organization = layer
+		} else {
+			set.Cluster = layer
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return AgentConfigSet{}, fmt.Errorf("iterate agent config rows: %w", err)
+	}
+	return set, nil
+}
+
+// SaveAgentConfig replaces one config layer and returns its new version. An
+// empty clusterID sets the organization default.
+func (s *PostgresStore) SaveAgentConfig(ctx context.Context, organizationID, clusterID string, config json.RawMessage) (int64, error) {
+	var version int64
+	err := s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO agent_configs (organization_id, cluster_id, config, version, updated_at)
+		 VALUES ($1, $2, $3, nextval('agent_config_version_seq'), NOW())
+		 ON CONFLICT (organization_id, cluster_id)
+		 DO UPDATE SET config = EXCLUDED.config, version = EXCLUDED.version, updated_at = NOW()
+		 RETURNING version`,
+		organizationID,
+		clusterID,
+		[]byte(config),
+	).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("save agent config: %w", err)
+	}
+	return version, nil
+}
+
 func (s *PostgresStore) FailureSeenRecently(ctx context.Context, organizationID, clusterID, namespace, podName, failureType string, window time.Duration) (bool, error) {
 	if window <= 0 {
 		window = 10 * time.Minute
@@ -803,3 +1156,320 @@ func (s *PostgresStore) FailureSeenRecently(ctx context.Context, organizationID,
 
 	return exists, nil
 }
+
+func (s *PostgresStore) LoadFailureState(ctx context.Context, organizationID, clusterID string) (FailureState, error) {
+	state := FailureState{Fingerprints: make(map[string]string)}
+
+	err := s.db.QueryRowContext(
+		ctx,
+		`SELECT report_sequence FROM cluster_report_sequences WHERE organization_id = $1 AND cluster_id = $2`,
+		organizationID,
+		clusterID,
+	).Scan(&state.Sequence)
+	if err == sql.ErrNoRows {
+		return state, nil
+	}
+	if err != nil {
+		return FailureState{}, fmt.Errorf("load report sequence: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT failure_key, fingerprint
+		 FROM cluster_failure_state
+		 WHERE organization_id = $1 AND cluster_id = $2`,
+		organizationID,
+		clusterID,
+	)
+	if err != nil {
+		return FailureState{}, fmt.Errorf("query failure state: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, fingerprint string
+		if scanErr := rows.Scan(&key, &fingerprint); scanErr != nil {
+			return FailureState{}, fmt.Errorf("scan failure state row: %w", scanErr)
+		}
+		state.Fingerprints[key] = fingerprint
+	}
+	if err := rows.Err(); err != nil {
+		return FailureState{}, fmt.Errorf("iterate failure state rows: %w", err)
+	}
+
+	return state, nil
+}
+
+// SaveFailureState stores the diagnoses for a delta-protocol report and
+// applies its state update in one transaction. The cluster's sequence row is
+// locked first, and ErrFailureStateChanged is returned without saving
+// anything when it no longer holds update.BaseSequence, so overlapping
+// reports for one cluster cannot interleave.
+func (s *PostgresStore) SaveFailureState(ctx context.Context, organizationID, clusterID string, update FailureStateUpdate, diagnoses []analyzer.Diagnosis) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := upsertCluster(ctx, tx, organizationID, clusterID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO cluster_report_sequences (organization_id, cluster_id)
+		 VALUES ($1, $2)
+		 ON CONFLICT (organization_id, cluster_id) DO NOTHING`,
+		organizationID,
+		clusterID,
+	); err != nil {
+		return fmt.Errorf("insert report sequence: %w", err)
+	}
+	var stored int64
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT report_sequence FROM cluster_report_sequences
+		 WHERE organization_id = $1 AND cluster_id = $2
+		 FOR UPDATE`,
+		organizationID,
+		clusterID,
+	).Scan(&stored); err != nil {
+		return fmt.Errorf("lock report sequence: %w", err)
+	}
+	if stored != update.BaseSequence {
+		return ErrFailureStateChanged
+	}
+
+	if err := insertDiagnoses(ctx, tx, organizationID, clusterID, diagnoses); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE cluster_report_sequences SET report_sequence = $3, updated_at = NOW()
+		 WHERE organization_id = $1 AND cluster_id = $2`,
+		organizationID,
+		clusterID,
+		update.Sequence,
+	); err != nil {
+		return fmt.Errorf("update report sequence: %w", err)
+	}
+
+	for _, key := range update.Cleared {
+		if _, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM cluster_failure_state
+			 WHERE organization_id = $1 AND cluster_id = $2 AND failure_key = $3`,
+			organizationID,
+			clusterID,
+			key,
+		); err != nil {
+			return fmt.Errorf("clear failure state: %w", err)
+		}
+	}
+
+	// Every failure still active was seen again, changed or not.
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE cluster_failure_state
+		 SET last_seen_at = $3, observations = observations + 1
+		 WHERE organization_id = $1 AND cluster_id = $2`,
+		organizationID,
+		clusterID,
+		update.ReceivedAt,
+	); err != nil {
+		return fmt.Errorf("touch failure state: %w", err)
+	}
+
+	for _, entry := range update.Upserts {
+		failureJSON := entry.Failure
+		if len(failureJSON) == 0 {
+			failureJSON = json.RawMessage(`{}`)
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO cluster_failure_state (organization_id, cluster_id, failure_key, fingerprint, failure, first_seen_at, last_seen_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $6)
+			 ON CONFLICT (organization_id, cluster_id, failure_key)
+			 DO UPDATE SET fingerprint = EXCLUDED.fingerprint, failure = EXCLUDED.failure, updated_at = NOW()`,
+			organizationID,
+			clusterID,
+			entry.Key,
+			entry.Fingerprint,
+			[]byte(failureJSON),
+			update.ReceivedAt,
+		); err != nil {
+			return fmt.Errorf("upsert failure state: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// SaveReportChunk stores one chunk of a split report. Once every chunk has
+// arrived they are removed and returned in index order; until then it returns
+// nil. Re-sent chunks overwrite the stored copy, so retries are harmless.
+func (s *PostgresStore) SaveReportChunk(ctx context.Context, organizationID, clusterID string, chunk ReportChunk) ([]json.RawMessage, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Serialize chunks of the same report so exactly one request sees it complete.
+	if _, err := tx.ExecContext(
+		ctx,
+		`SELECT pg_advisory_xact_lock(hashtext($1))`,
+		organizationID+"/"+clusterID+"/"+chunk.ReportID,
+	); err != nil {
+		return nil, fmt.Errorf("lock report chunks: %w", err)
+	}
+
+	// Abandoned partial reports are never completed; drop them after an hour.
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM agent_report_chunks WHERE received_at < NOW() - INTERVAL '1 hour'`,
+	); err != nil {
+		return nil, fmt.Errorf("expire report chunks: %w", err)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO agent_report_chunks (organization_id, cluster_id, report_id, chunk_index, chunk_count, body)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (organization_id, cluster_id, report_id, chunk_index)
+		 DO UPDATE SET chunk_count = EXCLUDED.chunk_count, body = EXCLUDED.body, received_at = NOW()`,
+		organizationID,
+		clusterID,
+		chunk.ReportID,
+		chunk.Index,
+		chunk.Count,
+		[]byte(chunk.Body),
+	); err != nil {
+		return nil, fmt.Errorf("insert report chunk: %w", err)
+	}
+
+	var received int
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*)
+		 FROM agent_report_chunks
+		 WHERE organization_id = $1 AND cluster_id = $2 AND report_id = $3 AND chunk_count = $4`,
+		organizationID,
+		clusterID,
+		chunk.ReportID,
+		chunk.Count,
+	).Scan(&received); err != nil {
+		return nil, fmt.Errorf("count report chunks: %w", err)
+	}
+	if received < chunk.Count {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`DELETE FROM agent_report_chunks
+		 WHERE organization_id = $1 AND cluster_id = $2 AND report_id = $3
+		 RETURNING chunk_index, body`,
+		organizationID,
+		clusterID,
+		chunk.ReportID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("collect report chunks: %w", err)
+	}
+	bodies := make([]json.RawMessage, chunk.Count)
+	for rows.Next() {
+		var index int
+		var body []byte
+		if scanErr := rows.Scan(&index, &body); scanErr != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan report chunk: %w", scanErr)
+		}
+		if index >= 0 && index < len(bodies) {
+			bodies[index] = body
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("iterate report chunks: %w", err)
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return bodies, nil
+}
+
+// SaveRedactionSummary keeps the audit record of what an agent scrubbed from
+// one report. It holds counts only, never the scrubbed values.
+func (s *PostgresStore) SaveRedactionSummary(ctx context.Context, organizationID, clusterID, reportID string, summary json.RawMessage) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO report_redactions (organization_id, cluster_id, report_id, summary)
+		 VALUES ($1, $2, $3, $4)`,
+		organizationID,
+		clusterID,
+		reportID,
+		[]byte(summary),
+	)
+	if err != nil {
+		return fmt.Errorf("save redaction summary: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ReportProcessed(ctx context.Context, organizationID, clusterID, reportID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (
+			SELECT 1
+			FROM agent_reports
+			WHERE organization_id = $1 AND cluster_id = $2 AND report_id = $3
+		)`,
+		organizationID,
+		clusterID,
+		reportID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("report processed query: %w", err)
+	}
+	return exists, nil
+}
+
+// MarkReportProcessed records reportID so a redelivery is acknowledged without
+// being applied twice. Receipts older than a day are pruned.
+func (s *PostgresStore) MarkReportProcessed(ctx context.Context, organizationID, clusterID, reportID string) error {
+	if _, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO agent_reports (organization_id, cluster_id, report_id)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT DO NOTHING`,
+		organizationID,
+		clusterID,
+		reportID,
+	); err != nil {
+		return fmt.Errorf("mark report processed: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM agent_reports WHERE processed_at < NOW() - INTERVAL '24 hours'`,
+	); err != nil {
+		return fmt.Errorf("expire report receipts: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"kuberoot/internal/analyzer"
@@ -30,12 +33,146 @@ type CurrentFailure struct {
 	Timeline        []string           `json:"timeline"`
 }
 
+// ClusterSummary describes a cluster that has reported to the backend.
+// Filters is the pod filter set its agent last reported ({} when unfiltered).
+// ConfigVersion is the backend config version the agent last reported
+// running; it lags DesiredConfigVersion until the agent picks up a change,
+// and stays behind if the agent rejects it.
+type ClusterSummary struct {
+	ID                   string          `json:"id"`
+	FirstSeen            time.Time       `json:"firstSeen"`
+	LastSeen             time.Time       `json:"lastSeen"`
+	Active               bool            `json:"active"`
+	Filters              json.RawMessage `json:"filters"`
+	AgentVersion         string          `json:"agentVersion"`
+	AgentFeatures        []string        `json:"agentFeatures"`
+	RegisteredAt         *time.Time      `json:"registeredAt,omitempty"`
+	ConfigVersion        int64           `json:"configVersion"`
+	DesiredConfigVersion int64           `json:"desiredConfigVersion"`
+}
+
+// AgentRegistration is what an agent announces about itself on startup.
+type AgentRegistration struct {
+	AgentVersion  string
+	Features      []string
+	ConfigVersion int64
+}
+
+// AgentConfigLayer is one stored agent config document: the organization
+// default or a single cluster's overrides.
+type AgentConfigLayer struct {
+	Config    json.RawMessage `json:"config"`
+	Version   int64           `json:"version"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// AgentConfigSet holds the config layers that apply to one cluster. Either
+// may be nil when it was never set.
+type AgentConfigSet struct {
+	Organization *AgentConfigLayer
+	Cluster      *AgentConfigLayer
+}
+
+// Version is the version of the effective config. Versions come from one
+// sequence, so any change to either layer raises it.
+func (s AgentConfigSet) Version() int64 {
+	var version int64
+	for _, layer := range []*AgentConfigLayer{s.Organization, s.Cluster} {
+		if layer != nil && layer.Version > version {
+			version = layer.Version
+		}
+	}
+	return version
+}
+
+// Effective merges the cluster layer over the organization default. Objects
+// merge key by key; any other value in the cluster layer replaces the
+// default, so a list is overridden as a whole.
+func (s AgentConfigSet) Effective() (json.RawMessage, error) {
+	merged := map[string]any{}
+	for _, layer := range []*AgentConfigLayer{s.Organization, s.Cluster} {
+		if layer == nil || len(layer.Config) == 0 {
+			continue
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(layer.Config, &doc); err != nil {
+			return nil, fmt.Errorf("decode config version %d: %w", layer.Version, err)
+		}
+		mergeConfig(merged, doc)
+	}
+	return json.Marshal(merged)
+}
+
+func mergeConfig(dst, src map[string]any) {
+	for key, value := range src {
+		srcObj, srcIsObj := value.(map[string]any)
+		dstObj, dstIsObj := dst[key].(map[string]any)
+		if srcIsObj && dstIsObj {
+			mergeConfig(dstObj, srcObj)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// FailureState is the backend's view of a cluster's active failures, keyed by
+// k8s.FailureKey, as of the last applied report sequence.
+type FailureState struct {
+	Sequence     int64
+	Fingerprints map[string]string
+}
+
+type FailureStateEntry struct {
+	Key         string
+	Fingerprint string
+	Failure     json.RawMessage
+}
+
+// FailureStateUpdate moves a cluster's failure state from BaseSequence to
+// Sequence by upserting new or changed failures and removing cleared ones.
+// Failures still in the state afterwards count as seen at ReceivedAt.
+type FailureStateUpdate struct {
+	BaseSequence int64
+	Sequence     int64
+	ReceivedAt   time.Time
+	Upserts      []FailureStateEntry
+	Cleared      []string
+}
+
+// ErrFailureStateChanged means another report for the cluster was applied
+// after the failure state was loaded, so an update computed from it was not
+// saved.
+var ErrFailureStateChanged = errors.New("failure state changed since it was loaded")
+
+// ReportChunk is one part of an agent report that was split to fit the
+// request size limit. Body holds the chunk exactly as the agent sent it.
+type ReportChunk struct {
+	ReportID string
+	Index    int
+	Count    int
+	Body     json.RawMessage
+}
+
 type DiagnosisStore interface {
 	SaveDiagnoses(ctx context.Context, organizationID, clusterID string, diagnoses []analyzer.Diagnosis) error
 	ListDiagnoses(ctx context.Context, organizationID, clusterID string, filter DiagnosisHistoryFilter) ([]analyzer.Diagnosis, error)
 	ListCurrentFailures(ctx context.Context, organizationID, clusterID string, filter DiagnosisHistoryFilter) ([]CurrentFailure, error)
 	FailureSeenRecently(ctx context.Context, organizationID, clusterID, namespace, podName, failureType string, window time.Duration) (bool, error)
 	ValidateAPIKey(ctx context.Context, keyHash string) (string, error)
-	CreateAPIKey(ctx context.Context, organizationID, name string) (string, error)
+	CreateAPIKey(ctx context.Context, organizationID, name string) (string, string, error)
+	SigningSecret(ctx context.Context, keyHash string) (string, error)
+	UseNonce(ctx context.Context, keyHash, nonce string, expiresAt time.Time) (bool, error)
 	RegisterCluster(ctx context.Context, organizationID, clusterID string) error
+	SaveClusterFilters(ctx context.Context, organizationID, clusterID string, filters json.RawMessage) error
+	ListClusters(ctx context.Context, organizationID string) ([]ClusterSummary, error)
+	RegisterAgent(ctx context.Context, organizationID, clusterID string, registration AgentRegistration) error
+	SaveClusterConfigVersion(ctx context.Context, organizationID, clusterID string, version int64) error
+	LoadAgentConfig(ctx context.Context, organizationID, clusterID string) (AgentConfigSet, error)
+	SaveAgentConfig(ctx context.Context, organizationID, clusterID string, config json.RawMessage) (int64, error)
+	LoadFailureState(ctx context.Context, organizationID, clusterID string) (FailureState, error)
+	SaveFailureState(ctx context.Context, organizationID, clusterID string, update FailureStateUpdate, diagnoses []analyzer.Diagnosis) error
+	SaveReportChunk(ctx context.Context, organizationID, clusterID string, chunk ReportChunk) ([]json.RawMessage, error)
+	SaveRedactionSummary(ctx context.Context, organizationID, clusterID, reportID string, summary json.RawMessage) error
+	ReportProcessed(ctx context.Context, organizationID, clusterID, reportID string) (bool, error)
+	MarkReportProcessed(ctx context.Context, organizationID, clusterID, reportID string) error
 }
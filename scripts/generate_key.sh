@@ -47,6 +47,14 @@ except Exception:
     raise SystemExit(0)
 print(data.get("apiKey",""))')
 
+signing_secret=$(printf '%s' "$response" | python3 -c 'import json,sys
+try:
+    data=json.load(sys.stdin)
+except Exception:
+    print("")
+    raise SystemExit(0)
+print(data.get("signingSecret",""))')
+
 if [[ -z "$api_key" ]]; then
   echo "Failed to parse apiKey from response"
   echo "$response"
@@ -60,10 +68,12 @@ Organization: ${organization_id}
 Cluster ID:   ${cluster_id}
 Key name:     ${key_name}
 API Key:      ${api_key}
+Signing:      ${signing_secret}
 
 Install command to send:
 
 export KUBEROOT_API_KEY=${api_key}
+export KUBEROOT_SIGNING_SECRET=${signing_secret}
 export KUBEROOT_CLUSTER_ID=${cluster_id}
 
 curl -sSL https://raw.githubusercontent.com/LikhithSM/KubeRoot/refs/heads/main/install.yaml \\
@@ -50,10 +50,12 @@ export function Dashboard() {
     const map = new Map<string, Agg>();
     for (const issue of filteredCurrentFailures) {
       const ctx = issue.diagnosis.context || [];
+      // "Workload: <Kind> <name>"; older diagnoses carry "Deployment: <name>".
+      const workloadLine = ctx.find((c) => c.startsWith('Workload: '));
       const depLine = ctx.find((c) => c.startsWith('Deployment: '));
-      if (!depLine) continue;
-
-      const deployment = depLine.replace('Deployment: ', '').trim();
+      const deployment = workloadLine
+        ? workloadLine.replace('Workload: ', '').trim()
+        : (depLine || '').replace('Deployment: ', '').trim();
       if (!deployment) continue;
 
       const key = `${issue.diagnosis.namespace}/${deployment}`;
@@ -67,9 +69,9 @@ export function Dashboard() {
 
       current.pods.add(issue.diagnosis.podName);
 
-      const replicasLine = ctx.find((c) => c.startsWith('Replicas: '));
+      const replicasLine = ctx.find((c) => c.startsWith('Readiness: ') || c.startsWith('Replicas: '));
       if (replicasLine) {
-        const m = replicasLine.match(/Replicas:\s*(\d+)\/(\d+)/);
+        const m = replicasLine.match(/(\d+)\/(\d+)/);
         if (m) {
           const ready = Number(m[1]);
           const desired = Number(m[2]);
@@ -189,7 +191,7 @@ export function Dashboard() {
             <>
               {activeTab === 'active' && deploymentImpacts.length > 0 && (
                 <div className="mb-6">
-                  <h3 className="text-sm font-semibold text-gray-700 uppercase tracking-wider mb-3">Deployment Impact</h3>
+                  <h3 className="text-sm font-semibold text-gray-700 uppercase tracking-wider mb-3">Workload Impact</h3>
                   <div className="grid grid-cols-1 md:grid-cols-2 xl:grid-cols-3 gap-3">
                     {deploymentImpacts.map((impact) => (
                       <div key={`${impact.namespace}/${impact.deployment}`} className="surface-card rounded-xl p-4 border border-rose-200 bg-rose-50/60">
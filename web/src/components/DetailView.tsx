@@ -21,7 +21,7 @@ export function DetailView({ diagnosis, timeline, firstSeen, lastSeen, occurrenc
   });
   const quickCommands = diagnosis.quickCommands || [];
   const contextSignals = diagnosis.context || [];
-  const replicasLine = contextSignals.find((c) => c.startsWith('Replicas: '));
+  const replicasLine = contextSignals.find((c) => c.startsWith('Readiness: ') || c.startsWith('Replicas: '));
 
   const isPatchSnippet = (cmd: string): boolean => {
     const value = cmd.trim();
@@ -51,13 +51,13 @@ export function DetailView({ diagnosis, timeline, firstSeen, lastSeen, occurrenc
       lines.push(`Active for ${Math.max(1, Math.floor(durationSeconds / 60))} minutes.`);
     }
     if (replicasLine) {
-      const m = replicasLine.match(/Replicas:\s*(\d+)\/(\d+)/);
+      const m = replicasLine.match(/(\d+)\/(\d+)/);
       if (m) {
         const ready = Number(m[1]);
         const desired = Number(m[2]);
         if (!Number.isNaN(ready) && !Number.isNaN(desired) && desired > 0) {
           if (ready < desired) {
-            lines.push(`Service impact: deployment degraded (${ready}/${desired} ready).`);
+            lines.push(`Service impact: workload degraded (${ready}/${desired} ready).`);
           } else {
             lines.push('Service impact: no replica degradation detected.');
           }